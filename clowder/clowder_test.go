@@ -15,17 +15,45 @@
 package clowder_test
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"path/filepath"
 	"testing"
 
 	"github.com/RedHatInsights/insights-operator-utils/clowder"
 	"github.com/RedHatInsights/insights-operator-utils/kafka"
 	"github.com/RedHatInsights/insights-operator-utils/postgres"
+	"github.com/RedHatInsights/insights-operator-utils/redis"
+	s3util "github.com/RedHatInsights/insights-operator-utils/s3"
+	"github.com/RedHatInsights/insights-operator-utils/tests/helpers"
+	"github.com/RedHatInsights/insights-operator-utils/types"
 	api "github.com/redhatinsights/app-common-go/pkg/api/v1"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
 	"github.com/stretchr/testify/assert"
-	"github.com/tisnik/go-capture"
 )
 
+const fixturesDir = "../testdata/clowder"
+
+// captureLog runs the given function and returns everything it logged
+func captureLog(f func()) string {
+	buf := new(bytes.Buffer)
+	originalLogger := log.Logger
+	log.Logger = zerolog.New(buf)
+	defer func() { log.Logger = originalLogger }()
+
+	f()
+	return buf.String()
+}
+
+// loadFixture reads Clowder configuration from the testdata directory
+func loadFixture(t *testing.T, name string) *api.AppConfig {
+	cfg, err := api.LoadConfig(filepath.Join(fixturesDir, name))
+	helpers.FailOnError(t, err)
+	return cfg
+}
+
 func TestUseDBConfig(t *testing.T) {
 	cfg := postgres.StorageConfiguration{}
 	expected := postgres.StorageConfiguration{
@@ -67,7 +95,8 @@ func TestUseClowderTopicsTopicFound(t *testing.T) {
 		},
 	}
 
-	clowder.UseClowderTopics(&brokerCfg, kafkaTopics)
+	err := clowder.UseClowderTopics(&brokerCfg, kafkaTopics)
+	helpers.FailOnError(t, err)
 	assert.Equal(t, clowderTopicName, brokerCfg.Topic, "Clowder topic name was not used")
 }
 
@@ -86,7 +115,8 @@ func TestUseClowderTopicsTopicFoundMultiBrokers(t *testing.T) {
 		},
 	}
 
-	clowder.UseClowderTopics(&brokerCfg, kafkaTopics)
+	err := clowder.UseClowderTopics(&brokerCfg, kafkaTopics)
+	helpers.FailOnError(t, err)
 	assert.Equal(t, clowderTopicName, brokerCfg.Topic, "Clowder topic name was not used")
 }
 
@@ -102,57 +132,71 @@ func TestUseClowderTopicsTopicNotFound(t *testing.T) {
 		},
 	}
 
-	output, _ := capture.StandardOutput(func() {
-		clowder.UseClowderTopics(&brokerCfg, kafkaTopics)
+	var err error
+	output := captureLog(func() {
+		err = clowder.UseClowderTopics(&brokerCfg, kafkaTopics)
 	})
 	assert.Equal(t, originalTopicName, brokerCfg.Topic, "topic name should not change")
-	assert.Contains(t, output, "warning: no kafka mapping found for topic topic1")
+	assert.Contains(t, output, "no kafka mapping found for topic topic1")
+
+	var mappingErr *clowder.TopicMappingError
+	assert.True(t, errors.As(err, &mappingErr))
+	assert.Equal(t, originalTopicName, mappingErr.Topic)
+}
+
+func TestUseClowderTopicsFromFixture(t *testing.T) {
+	loadedConfig := loadFixture(t, "brokers_without_auth.json")
+	kafkaTopics := map[string]api.TopicConfig{}
+	for _, topic := range loadedConfig.Kafka.Topics {
+		kafkaTopics[topic.RequestedName] = topic
+	}
+
+	brokerCfg := kafka.BrokerConfiguration{Topic: "platform.results.ccx"}
+	err := clowder.UseClowderTopics(&brokerCfg, kafkaTopics)
+	helpers.FailOnError(t, err)
+	assert.Equal(t, "platform.results.ccx.clowder", brokerCfg.Topic)
 }
 
 func TestUseBrokerConfigNoKafkaConfig(t *testing.T) {
-	brokerCfg := kafka.BrokerConfiguration{}
-	loadedConfig := api.AppConfig{}
+	brokerCfg := kafka.BrokerConfiguration{Addresses: "localhost:9092"}
+	loadedConfig := loadFixture(t, "no_kafka.json")
 
-	output, _ := capture.StandardOutput(func() {
-		clowder.UseBrokerConfig(&brokerCfg, &loadedConfig)
+	var (
+		report clowder.ConfigReport
+		err    error
+	)
+	output := captureLog(func() {
+		report, err = clowder.UseBrokerConfig(&brokerCfg, loadedConfig)
 	})
-	assert.Contains(t, output, clowder.NoBrokerCfg)
+	assert.ErrorIs(t, err, clowder.ErrNoBrokerConfig)
+	assert.Contains(t, output, clowder.ErrNoBrokerConfig.Error())
+	assert.Empty(t, report.Overridden)
+	assert.Equal(t, "localhost:9092", brokerCfg.Addresses, "addresses should not change")
+}
+
+func TestUseBrokerConfigNilConfig(t *testing.T) {
+	brokerCfg := kafka.BrokerConfiguration{}
+
+	_, err := clowder.UseBrokerConfig(&brokerCfg, nil)
+	assert.ErrorIs(t, err, clowder.ErrNoBrokerConfig)
 }
 
 func TestUseBrokerConfigNoKafkaBrokers(t *testing.T) {
 	brokerCfg := kafka.BrokerConfiguration{}
-	loadedConfig := api.AppConfig{
-		Kafka: &api.KafkaConfig{},
-	}
+	loadedConfig := loadFixture(t, "no_brokers.json")
 
-	output, _ := capture.StandardOutput(func() {
-		clowder.UseBrokerConfig(&brokerCfg, &loadedConfig)
-	})
-	assert.Contains(t, output, clowder.NoBrokerCfg)
+	_, err := clowder.UseBrokerConfig(&brokerCfg, loadedConfig)
+	assert.ErrorIs(t, err, clowder.ErrNoBrokerConfig)
 }
 
 func TestUseBrokerConfigMultipleKafkaBrokers(t *testing.T) {
-	addr1 := "test_broker"
-	addr2 := "test_broker_backup"
-	port := 12345
 	brokerCfg := kafka.BrokerConfiguration{}
-	loadedConfig := api.AppConfig{
-		Kafka: &api.KafkaConfig{
-			Brokers: []api.BrokerConfig{
-				{
-					Hostname: addr1,
-					Port:     &port,
-				},
-				{
-					Hostname: addr2,
-					Port:     nil,
-				},
-			},
-		},
-	}
+	loadedConfig := loadFixture(t, "brokers_without_auth.json")
 
-	clowder.UseBrokerConfig(&brokerCfg, &loadedConfig)
-	assert.Equal(t, fmt.Sprintf("%s:%d,%s", addr1, port, addr2), brokerCfg.Addresses)
+	report, err := clowder.UseBrokerConfig(&brokerCfg, loadedConfig)
+	helpers.FailOnError(t, err)
+	assert.Equal(t, "broker-1:9092,broker-2", brokerCfg.Addresses)
+	assert.Equal(t, []string{"addresses"}, report.Overridden)
 }
 
 func TestUseBrokerConfigNoAuthNoPort(t *testing.T) {
@@ -169,7 +213,8 @@ func TestUseBrokerConfigNoAuthNoPort(t *testing.T) {
 		},
 	}
 
-	clowder.UseBrokerConfig(&brokerCfg, &loadedConfig)
+	_, err := clowder.UseBrokerConfig(&brokerCfg, &loadedConfig)
+	helpers.FailOnError(t, err)
 	assert.Equal(t, addr, brokerCfg.Addresses)
 }
 
@@ -188,84 +233,251 @@ func TestUseBrokerConfigNoAuth(t *testing.T) {
 		},
 	}
 
-	clowder.UseBrokerConfig(&brokerCfg, &loadedConfig)
+	_, err := clowder.UseBrokerConfig(&brokerCfg, &loadedConfig)
+	helpers.FailOnError(t, err)
 	assert.Equal(t, fmt.Sprintf("%s:%d", addr, port), brokerCfg.Addresses)
 }
 
 func TestUseBrokerConfigAuthEnabledNoSasl(t *testing.T) {
 	brokerCfg := kafka.BrokerConfiguration{}
-	port := 12345
-	addr := "test_broker"
-	authType := api.BrokerConfigAuthtypeSasl
-	loadedConfig := api.AppConfig{
-		Kafka: &api.KafkaConfig{
-			Brokers: []api.BrokerConfig{
-				{
-					Hostname: addr,
-					Port:     &port,
-					Authtype: &authType,
-				},
-			},
-		},
-	}
+	loadedConfig := loadFixture(t, "sasl_missing.json")
 
-	output, _ := capture.StandardOutput(func() {
-		clowder.UseBrokerConfig(&brokerCfg, &loadedConfig)
+	var (
+		report clowder.ConfigReport
+		err    error
+	)
+	output := captureLog(func() {
+		report, err = clowder.UseBrokerConfig(&brokerCfg, loadedConfig)
 	})
 
-	assert.Equal(t, fmt.Sprintf("%s:%d", addr, port), brokerCfg.Addresses)
-	assert.Contains(t, output, clowder.NoSaslCfg)
+	assert.ErrorIs(t, err, clowder.ErrNoSaslConfig)
+	assert.Equal(t, "broker-1:9096", brokerCfg.Addresses)
+	assert.Contains(t, output, clowder.ErrNoSaslConfig.Error())
+	assert.Equal(t, []string{"addresses"}, report.Overridden)
+}
+
+func TestUseBrokerConfigIncompleteSasl(t *testing.T) {
+	brokerCfg := kafka.BrokerConfiguration{
+		SaslMechanism:    "PLAIN",
+		SecurityProtocol: "SASL_SSL",
+	}
+	loadedConfig := loadFixture(t, "sasl_incomplete.json")
+
+	report, err := clowder.UseBrokerConfig(&brokerCfg, loadedConfig)
+	assert.ErrorIs(t, err, clowder.ErrIncompleteSaslConfig)
+	assert.Contains(t, err.Error(), "missing password")
+	assert.Equal(t, "user", brokerCfg.SaslUsername)
+	assert.Empty(t, brokerCfg.SaslPassword)
+	// values not provided by Clowder are kept
+	assert.Equal(t, "PLAIN", brokerCfg.SaslMechanism)
+	assert.Equal(t, "SASL_SSL", brokerCfg.SecurityProtocol)
+	assert.True(t, report.IsOverridden("sasl_username"))
+	assert.False(t, report.IsOverridden("sasl_password"))
+	assert.False(t, report.IsOverridden("security_protocol"))
+}
+
+func TestUseBrokerConfigSecurityProtocolInSasl(t *testing.T) {
+	brokerCfg := kafka.BrokerConfiguration{}
+	loadedConfig := loadFixture(t, "sasl_protocol_in_sasl.json")
+
+	report, err := clowder.UseBrokerConfig(&brokerCfg, loadedConfig)
+	helpers.FailOnError(t, err)
+	assert.Equal(t, "SASL_PLAINTEXT", brokerCfg.SecurityProtocol)
+	assert.True(t, report.IsOverridden("security_protocol"))
+	assert.Empty(t, brokerCfg.CertPath, "no CA certificate is provided")
 }
 
 func TestUseBrokerConfigAuthEnabledWithSaslConfig(t *testing.T) {
 	brokerCfg := kafka.BrokerConfiguration{}
-	port := 12345
-	addr := "test_broker"
-	addr2 := "test_broker_backup"
-	saslUsr := "user"
-	saslUsr2 := "user2"
-	saslPwd := "pwd"
-	saslMechanism := "sasl"
-	protocol := "tls"
-
-	authType := api.BrokerConfigAuthtypeSasl
-	loadedConfig := api.AppConfig{
-		Kafka: &api.KafkaConfig{
-			Brokers: []api.BrokerConfig{
-				{
-					Hostname: addr,
-					Port:     &port,
-					Authtype: &authType,
-					Sasl: &api.KafkaSASLConfig{
-						Password:      &saslPwd,
-						Username:      &saslUsr,
-						SaslMechanism: &saslMechanism,
-					},
-					SecurityProtocol: &protocol,
-				},
-				{
-					Hostname: addr2,
-					Port:     &port,
-					Authtype: &authType,
-					Sasl: &api.KafkaSASLConfig{
-						Password:      &saslPwd,
-						Username:      &saslUsr2,
-						SaslMechanism: &saslMechanism,
-					},
-					SecurityProtocol: &protocol,
-				},
-			},
-		},
-	}
+	loadedConfig := loadFixture(t, "sasl_complete.json")
 
-	output, _ := capture.StandardOutput(func() {
-		clowder.UseBrokerConfig(&brokerCfg, &loadedConfig)
+	var (
+		report clowder.ConfigReport
+		err    error
+	)
+	output := captureLog(func() {
+		report, err = clowder.UseBrokerConfig(&brokerCfg, loadedConfig)
 	})
+	helpers.FailOnError(t, err)
 
-	assert.Equal(t, fmt.Sprintf("%s:%d,%s:%d", addr, port, addr2, port), brokerCfg.Addresses)
+	assert.Equal(t, "broker-1:9096", brokerCfg.Addresses)
 	assert.Contains(t, output, "kafka is configured to use authentication")
-	assert.Equal(t, saslUsr, brokerCfg.SaslUsername)
-	assert.Equal(t, saslPwd, brokerCfg.SaslPassword)
-	assert.Equal(t, saslMechanism, brokerCfg.SaslMechanism)
-	assert.Equal(t, protocol, brokerCfg.SecurityProtocol)
+	assert.Equal(t, "user", brokerCfg.SaslUsername)
+	assert.Equal(t, "password", brokerCfg.SaslPassword)
+	assert.Equal(t, "SCRAM-SHA-512", brokerCfg.SaslMechanism)
+	assert.Equal(t, "SASL_SSL", brokerCfg.SecurityProtocol)
+	assert.FileExists(t, brokerCfg.CertPath)
+	assert.ElementsMatch(t, []string{
+		"addresses", "sasl_username", "sasl_password", "sasl_mechanism", "security_protocol", "cert_path",
+	}, report.Overridden)
+}
+
+func TestUseBrokerConfigMixedAuthentication(t *testing.T) {
+	brokerCfg := kafka.BrokerConfiguration{}
+	loadedConfig := loadFixture(t, "mixed_auth.json")
+
+	var err error
+	output := captureLog(func() {
+		_, err = clowder.UseBrokerConfig(&brokerCfg, loadedConfig)
+	})
+	helpers.FailOnError(t, err)
+
+	assert.Equal(t, "broker-1:9092,broker-2:9096,broker-3:9096", brokerCfg.Addresses)
+	// the first broker with authentication enabled is used
+	assert.Equal(t, "user2", brokerCfg.SaslUsername)
+	assert.Equal(t, "password2", brokerCfg.SaslPassword)
+	assert.Contains(t, output, "kafka brokers have different authentication settings")
+}
+
+func TestUseInMemoryDBConfig(t *testing.T) {
+	redisCfg := redis.Configuration{
+		Database:       2,
+		TimeoutSeconds: 30,
+	}
+	loadedConfig := loadFixture(t, "services.json")
+
+	report, err := clowder.UseInMemoryDBConfig(&redisCfg, loadedConfig)
+	helpers.FailOnError(t, err)
+
+	expected := redis.Configuration{
+		Endpoint:       "redis.example.com:6379",
+		Database:       2,
+		Username:       "redis-user",
+		Password:       "redis-password",
+		TimeoutSeconds: 30,
+	}
+	assert.Equal(t, expected, redisCfg)
+	assert.Equal(t, []string{"endpoint", "username", "password"}, report.Overridden)
+}
+
+func TestUseInMemoryDBConfigMissing(t *testing.T) {
+	redisCfg := redis.Configuration{Endpoint: "localhost:6379"}
+
+	_, err := clowder.UseInMemoryDBConfig(&redisCfg, loadFixture(t, "no_kafka.json"))
+	assert.ErrorIs(t, err, clowder.ErrNoInMemoryDBConfig)
+	assert.Equal(t, "localhost:6379", redisCfg.Endpoint, "endpoint should not change")
+}
+
+func TestUseObjectStoreConfig(t *testing.T) {
+	s3Cfg := s3util.Configuration{Bucket: "archives", Region: "eu-west-1"}
+	loadedConfig := loadFixture(t, "services.json")
+
+	report, err := clowder.UseObjectStoreConfig(&s3Cfg, loadedConfig)
+	helpers.FailOnError(t, err)
+
+	expected := s3util.Configuration{
+		Endpoint:  "minio.example.com:9000",
+		Region:    "eu-west-1",
+		Bucket:    "archives-clowder",
+		AccessKey: "store-access-key",
+		SecretKey: "store-secret-key",
+		UseSSL:    false,
+	}
+	assert.Equal(t, expected, s3Cfg)
+	assert.False(t, report.IsOverridden("region"))
+	assert.True(t, report.IsOverridden("bucket"))
+}
+
+func TestUseObjectStoreConfigBucketSettings(t *testing.T) {
+	s3Cfg := s3util.Configuration{Bucket: "reports"}
+	loadedConfig := loadFixture(t, "services.json")
+
+	report, err := clowder.UseObjectStoreConfig(&s3Cfg, loadedConfig)
+	helpers.FailOnError(t, err)
+
+	expected := s3util.Configuration{
+		Endpoint:  "s3.us-east-1.amazonaws.com",
+		Region:    "us-east-1",
+		Bucket:    "reports-clowder",
+		AccessKey: "bucket-access-key",
+		SecretKey: "bucket-secret-key",
+		UseSSL:    true,
+	}
+	assert.Equal(t, expected, s3Cfg)
+	assert.ElementsMatch(t, []string{
+		"endpoint", "use_ssl", "access_key", "secret_key", "bucket", "region",
+	}, report.Overridden)
+}
+
+func TestUseObjectStoreConfigUnknownBucket(t *testing.T) {
+	s3Cfg := s3util.Configuration{Bucket: "unknown"}
+
+	_, err := clowder.UseObjectStoreConfig(&s3Cfg, loadFixture(t, "services.json"))
+
+	var mappingErr *clowder.BucketMappingError
+	assert.True(t, errors.As(err, &mappingErr))
+	assert.Equal(t, "unknown", mappingErr.Bucket)
+	assert.Equal(t, "unknown", s3Cfg.Bucket, "bucket name should not change")
+}
+
+func TestUseObjectStoreConfigMissing(t *testing.T) {
+	s3Cfg := s3util.Configuration{}
+
+	_, err := clowder.UseObjectStoreConfig(&s3Cfg, loadFixture(t, "no_kafka.json"))
+	assert.ErrorIs(t, err, clowder.ErrNoObjectStoreConfig)
+}
+
+func TestUseServerConfig(t *testing.T) {
+	serverCfg := types.ServerConfiguration{Address: ":8080"}
+
+	report, err := clowder.UseServerConfig(&serverCfg, loadFixture(t, "services.json"))
+	helpers.FailOnError(t, err)
+
+	expected := types.ServerConfiguration{
+		Address:        ":8000",
+		PrivateAddress: ":10000",
+		MetricsAddress: ":9000",
+		MetricsPath:    "/metrics",
+	}
+	assert.Equal(t, expected, serverCfg)
+	assert.Len(t, report.Overridden, 4)
+}
+
+func TestUseServerConfigDeprecatedWebPort(t *testing.T) {
+	serverCfg := types.ServerConfiguration{MetricsPath: "/api/v1/metrics"}
+
+	report, err := clowder.UseServerConfig(&serverCfg, loadFixture(t, "deprecated_web_port.json"))
+	helpers.FailOnError(t, err)
+
+	assert.Equal(t, ":8080", serverCfg.Address)
+	assert.Empty(t, serverCfg.MetricsAddress)
+	assert.Equal(t, "/api/v1/metrics", serverCfg.MetricsPath)
+	assert.Equal(t, []string{"address"}, report.Overridden)
+}
+
+func TestUseServerConfigMissing(t *testing.T) {
+	serverCfg := types.ServerConfiguration{Address: ":8080"}
+
+	_, err := clowder.UseServerConfig(&serverCfg, &api.AppConfig{})
+	assert.ErrorIs(t, err, clowder.ErrNoServerConfig)
+	assert.Equal(t, ":8080", serverCfg.Address)
+
+	_, err = clowder.UseServerConfig(&serverCfg, nil)
+	assert.ErrorIs(t, err, clowder.ErrNoServerConfig)
+}
+
+func TestUseMetricsConfig(t *testing.T) {
+	metricsCfg := types.MetricsConfiguration{Job: "job"}
+
+	report, err := clowder.UseMetricsConfig(&metricsCfg, loadFixture(t, "services.json"))
+	helpers.FailOnError(t, err)
+	assert.Equal(t, "http://pushgateway.example.com:9091", metricsCfg.GatewayURL)
+	assert.Equal(t, "job", metricsCfg.Job)
+	assert.Equal(t, []string{"gateway_url"}, report.Overridden)
+
+	_, err = clowder.UseMetricsConfig(&metricsCfg, loadFixture(t, "no_kafka.json"))
+	assert.ErrorIs(t, err, clowder.ErrNoPrometheusGatewayConfig)
+}
+
+func TestUseFeatureFlagsConfig(t *testing.T) {
+	featureFlagsCfg := types.FeatureFlagsConfiguration{}
+
+	report, err := clowder.UseFeatureFlagsConfig(&featureFlagsCfg, loadFixture(t, "services.json"))
+	helpers.FailOnError(t, err)
+	assert.Equal(t, "https://unleash.example.com:4242/api", featureFlagsCfg.URL)
+	assert.Equal(t, "unleash-token", featureFlagsCfg.Token)
+	assert.Equal(t, []string{"url", "token"}, report.Overridden)
+
+	_, err = clowder.UseFeatureFlagsConfig(&featureFlagsCfg, nil)
+	assert.ErrorIs(t, err, clowder.ErrNoFeatureFlagsConfig)
 }
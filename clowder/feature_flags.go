@@ -0,0 +1,50 @@
+// Copyright 2026 Red Hat, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clowder
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/RedHatInsights/insights-operator-utils/types"
+	api "github.com/redhatinsights/app-common-go/pkg/api/v1"
+	"github.com/rs/zerolog/log"
+)
+
+// ErrNoFeatureFlagsConfig is returned when no feature flags service is
+// provided by Clowder
+var ErrNoFeatureFlagsConfig = errors.New("no feature flags configuration found in clowder config")
+
+// featureFlagsAPIPath is the path to the client API of Unleash server
+const featureFlagsAPIPath = "/api"
+
+// UseFeatureFlagsConfig tries to replace the URL of the feature flags service
+// and its access token with the values loaded by Clowder
+func UseFeatureFlagsConfig(featureFlagsCfg *types.FeatureFlagsConfiguration, loadedConfig *api.AppConfig) (ConfigReport, error) {
+	report := ConfigReport{}
+
+	if loadedConfig == nil || loadedConfig.FeatureFlags == nil {
+		log.Warn().Msg(ErrNoFeatureFlagsConfig.Error())
+		return report, ErrNoFeatureFlagsConfig
+	}
+
+	featureFlags := loadedConfig.FeatureFlags
+	featureFlagsCfg.URL = fmt.Sprintf("%s://%s:%d%s",
+		featureFlags.Scheme, featureFlags.Hostname, featureFlags.Port, featureFlagsAPIPath)
+	report.override("url")
+	report.useString(&featureFlagsCfg.Token, featureFlags.ClientAccessToken, "token")
+
+	return report, nil
+}
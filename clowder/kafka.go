@@ -15,63 +15,163 @@
 package clowder
 
 import (
+	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/RedHatInsights/insights-operator-utils/kafka"
 	api "github.com/redhatinsights/app-common-go/pkg/api/v1"
+	"github.com/rs/zerolog/log"
 )
 
-// Common constants used for logging and error reporting
-const (
-	noBrokerConfig = "warning: no broker configurations found in clowder config"
-	noSaslConfig   = "warning: SASL configuration is missing"
-	noTopicMapping = "warning: no kafka mapping found for topic %s"
+// Errors returned when the Clowder configuration can't be used
+var (
+	// ErrNoBrokerConfig is returned when no Kafka brokers are configured
+	ErrNoBrokerConfig = errors.New("no broker configurations found in clowder config")
+	// ErrNoSaslConfig is returned when authentication is enabled, but the
+	// SASL configuration is missing
+	ErrNoSaslConfig = errors.New("SASL configuration is missing")
+	// ErrIncompleteSaslConfig is returned when some of the mandatory SASL
+	// attributes are missing
+	ErrIncompleteSaslConfig = errors.New("SASL configuration is incomplete")
 )
 
+// TopicMappingError is returned when there's no Clowder topic for the
+// configured one
+type TopicMappingError struct {
+	Topic string
+}
+
+func (e *TopicMappingError) Error() string {
+	return fmt.Sprintf("no kafka mapping found for topic %s", e.Topic)
+}
+
 // UseBrokerConfig tries to replace parts of the BrokerConfiguration with the values
-// loaded by Clowder
-func UseBrokerConfig(brokerCfg *kafka.BrokerConfiguration, loadedConfig *api.AppConfig) {
-	if loadedConfig.Kafka != nil && len(loadedConfig.Kafka.Brokers) > 0 {
-		brokerCfg.Addresses = ""
-		for _, broker := range loadedConfig.Kafka.Brokers {
-			if broker.Port != nil {
-				brokerCfg.Addresses += fmt.Sprintf("%s:%d", broker.Hostname, *broker.Port) + ","
-			} else {
-				brokerCfg.Addresses += broker.Hostname + ","
-			}
+// loaded by Clowder. Authentication settings are taken from the first broker
+// that has authentication enabled. The returned report lists all overridden
+// fields, even when an error is returned.
+func UseBrokerConfig(brokerCfg *kafka.BrokerConfiguration, loadedConfig *api.AppConfig) (ConfigReport, error) {
+	report := ConfigReport{}
+
+	if loadedConfig == nil || loadedConfig.Kafka == nil || len(loadedConfig.Kafka.Brokers) == 0 {
+		log.Warn().Msg(ErrNoBrokerConfig.Error())
+		return report, ErrNoBrokerConfig
+	}
+
+	brokers := loadedConfig.Kafka.Brokers
+	addresses := make([]string, 0, len(brokers))
+	for _, broker := range brokers {
+		if broker.Port != nil {
+			addresses = append(addresses, fmt.Sprintf("%s:%d", broker.Hostname, *broker.Port))
+		} else {
+			addresses = append(addresses, broker.Hostname)
 		}
-		// remove the extra comma
-		brokerCfg.Addresses = brokerCfg.Addresses[:len(brokerCfg.Addresses)-1]
-		// SSL config
-		clowderCfg := loadedConfig.Kafka.Brokers[0]
-		if clowderCfg.Authtype != nil {
-			fmt.Println("kafka is configured to use authentication")
-			if clowderCfg.Sasl != nil {
-				// we are trusting that these values are set and
-				// dereferencing the pointers without any check...
-				brokerCfg.SaslUsername = *clowderCfg.Sasl.Username
-				brokerCfg.SaslPassword = *clowderCfg.Sasl.Password
-				brokerCfg.SaslMechanism = *clowderCfg.Sasl.SaslMechanism
-				brokerCfg.SecurityProtocol = *clowderCfg.SecurityProtocol
-
-				if caPath, err := loadedConfig.KafkaCa(clowderCfg); err == nil {
-					brokerCfg.CertPath = caPath
-				}
-			} else {
-				fmt.Println(noSaslConfig)
-			}
+	}
+	brokerCfg.Addresses = strings.Join(addresses, ",")
+	report.override("addresses")
+
+	clowderCfg := authenticatedBroker(brokers)
+	if clowderCfg == nil {
+		return report, nil
+	}
+	log.Info().Str("broker", clowderCfg.Hostname).Msg("kafka is configured to use authentication")
+
+	if clowderCfg.Sasl == nil {
+		log.Warn().Str("broker", clowderCfg.Hostname).Msg(ErrNoSaslConfig.Error())
+		return report, ErrNoSaslConfig
+	}
+
+	var missing []string
+	if !report.useString(&brokerCfg.SaslUsername, clowderCfg.Sasl.Username, "sasl_username") {
+		missing = append(missing, "username")
+	}
+	if !report.useString(&brokerCfg.SaslPassword, clowderCfg.Sasl.Password, "sasl_password") {
+		missing = append(missing, "password")
+	}
+	if !report.useString(&brokerCfg.SaslMechanism, clowderCfg.Sasl.SaslMechanism, "sasl_mechanism") {
+		log.Warn().Msg("SASL mechanism is not provided by clowder, using the configured one")
+	}
+
+	securityProtocol := clowderCfg.SecurityProtocol
+	if securityProtocol == nil {
+		securityProtocol = clowderCfg.Sasl.SecurityProtocol
+	}
+	if !report.useString(&brokerCfg.SecurityProtocol, securityProtocol, "security_protocol") {
+		log.Warn().Msg("security protocol is not provided by clowder, using the configured one")
+	}
+
+	if clowderCfg.Cacert != nil {
+		caPath, err := loadedConfig.KafkaCa(*clowderCfg)
+		if err != nil {
+			log.Error().Err(err).Msg("unable to store kafka CA certificate")
+			return report, err
+		}
+		brokerCfg.CertPath = caPath
+		report.override("cert_path")
+	}
+
+	if len(missing) > 0 {
+		err := fmt.Errorf("%w: missing %s", ErrIncompleteSaslConfig, strings.Join(missing, ", "))
+		log.Warn().Err(err).Msg("unable to use clowder SASL configuration")
+		return report, err
+	}
+
+	return report, nil
+}
+
+// authenticatedBroker returns the first broker with authentication enabled or
+// nil if there's no such broker. Brokers with different authentication
+// settings are reported, because only one setting can be used.
+func authenticatedBroker(brokers []api.BrokerConfig) *api.BrokerConfig {
+	var selected *api.BrokerConfig
+	for i := range brokers {
+		broker := &brokers[i]
+		if broker.Authtype == nil {
+			continue
+		}
+		if selected == nil {
+			selected = broker
+			continue
 		}
-	} else {
-		fmt.Println(noBrokerConfig)
+		if !sameAuthentication(selected, broker) {
+			log.Warn().
+				Str("used", selected.Hostname).
+				Str("ignored", broker.Hostname).
+				Msg("kafka brokers have different authentication settings")
+		}
+	}
+	return selected
+}
+
+func sameAuthentication(broker1, broker2 *api.BrokerConfig) bool {
+	if *broker1.Authtype != *broker2.Authtype {
+		return false
+	}
+	if broker1.Sasl == nil || broker2.Sasl == nil {
+		return broker1.Sasl == broker2.Sasl
 	}
+	return equalStrings(broker1.Sasl.Username, broker2.Sasl.Username) &&
+		equalStrings(broker1.Sasl.SaslMechanism, broker2.Sasl.SaslMechanism) &&
+		equalStrings(broker1.SecurityProtocol, broker2.SecurityProtocol)
+}
+
+func equalStrings(s1, s2 *string) bool {
+	if s1 == nil || s2 == nil {
+		return s1 == s2
+	}
+	return *s1 == *s2
 }
 
 // UseClowderTopics tries to replace the configured topic with the corresponding
 // topic loaded by Clowder
-func UseClowderTopics(brokerCfg *kafka.BrokerConfiguration, kafkaTopics map[string]api.TopicConfig) {
-	if clowderTopic, ok := kafkaTopics[brokerCfg.Topic]; ok {
-		brokerCfg.Topic = clowderTopic.Name
-	} else {
-		fmt.Printf(noTopicMapping, brokerCfg.Topic)
+func UseClowderTopics(brokerCfg *kafka.BrokerConfiguration, kafkaTopics map[string]api.TopicConfig) error {
+	clowderTopic, ok := kafkaTopics[brokerCfg.Topic]
+	if !ok {
+		err := &TopicMappingError{Topic: brokerCfg.Topic}
+		log.Warn().Err(err).Msg("unable to use clowder topic")
+		return err
 	}
+
+	brokerCfg.Topic = clowderTopic.Name
+	return nil
 }
@@ -0,0 +1,50 @@
+// Copyright 2026 Red Hat, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clowder
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/RedHatInsights/insights-operator-utils/redis"
+	api "github.com/redhatinsights/app-common-go/pkg/api/v1"
+	"github.com/rs/zerolog/log"
+)
+
+// ErrNoInMemoryDBConfig is returned when no in-memory DB is provided by Clowder
+var ErrNoInMemoryDBConfig = errors.New("no in-memory DB configuration found in clowder config")
+
+// UseInMemoryDBConfig tries to replace the Redis configuration with the values
+// loaded by Clowder
+func UseInMemoryDBConfig(redisCfg *redis.Configuration, loadedConfig *api.AppConfig) (ConfigReport, error) {
+	report := ConfigReport{}
+
+	if loadedConfig == nil || loadedConfig.InMemoryDb == nil {
+		log.Warn().Msg(ErrNoInMemoryDBConfig.Error())
+		return report, ErrNoInMemoryDBConfig
+	}
+
+	inMemoryDB := loadedConfig.InMemoryDb
+	redisCfg.Endpoint = fmt.Sprintf("%s:%d", inMemoryDB.Hostname, inMemoryDB.Port)
+	report.override("endpoint")
+	report.useString(&redisCfg.Username, inMemoryDB.Username, "username")
+	report.useString(&redisCfg.Password, inMemoryDB.Password, "password")
+
+	if inMemoryDB.SslMode != nil && *inMemoryDB.SslMode {
+		log.Warn().Msg("TLS connection to in-memory DB is requested by clowder, but it's not supported by the Redis client")
+	}
+
+	return report, nil
+}
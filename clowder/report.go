@@ -0,0 +1,50 @@
+// Copyright 2026 Red Hat, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clowder
+
+// ConfigReport lists the configuration fields that were overridden by the
+// values loaded by Clowder. Field names are the same as the names used in
+// TOML configuration files.
+type ConfigReport struct {
+	Overridden []string
+}
+
+// IsOverridden checks whether the given field was overridden by Clowder
+func (report *ConfigReport) IsOverridden(field string) bool {
+	for _, overridden := range report.Overridden {
+		if overridden == field {
+			return true
+		}
+	}
+	return false
+}
+
+// override records that the field was overridden
+func (report *ConfigReport) override(field string) {
+	if !report.IsOverridden(field) {
+		report.Overridden = append(report.Overridden, field)
+	}
+}
+
+// useString replaces the destination value when the value provided by
+// Clowder is set. It returns false when the value is not available.
+func (report *ConfigReport) useString(dest, value *string, field string) bool {
+	if value == nil {
+		return false
+	}
+	*dest = *value
+	report.override(field)
+	return true
+}
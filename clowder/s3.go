@@ -0,0 +1,85 @@
+// Copyright 2026 Red Hat, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clowder
+
+import (
+	"errors"
+	"fmt"
+
+	s3util "github.com/RedHatInsights/insights-operator-utils/s3"
+	api "github.com/redhatinsights/app-common-go/pkg/api/v1"
+	"github.com/rs/zerolog/log"
+)
+
+// ErrNoObjectStoreConfig is returned when no object store is provided by Clowder
+var ErrNoObjectStoreConfig = errors.New("no object store configuration found in clowder config")
+
+// BucketMappingError is returned when there's no Clowder bucket for the
+// configured one
+type BucketMappingError struct {
+	Bucket string
+}
+
+func (e *BucketMappingError) Error() string {
+	return fmt.Sprintf("no object store mapping found for bucket %s", e.Bucket)
+}
+
+// UseObjectStoreConfig tries to replace the S3 configuration with the values
+// loaded by Clowder. The configured bucket is used as the requested bucket name
+// and bucket specific settings take precedence over the object store ones.
+func UseObjectStoreConfig(s3Cfg *s3util.Configuration, loadedConfig *api.AppConfig) (ConfigReport, error) {
+	report := ConfigReport{}
+
+	if loadedConfig == nil || loadedConfig.ObjectStore == nil {
+		log.Warn().Msg(ErrNoObjectStoreConfig.Error())
+		return report, ErrNoObjectStoreConfig
+	}
+
+	objectStore := loadedConfig.ObjectStore
+	s3Cfg.Endpoint = fmt.Sprintf("%s:%d", objectStore.Hostname, objectStore.Port)
+	report.override("endpoint")
+	s3Cfg.UseSSL = objectStore.Tls
+	report.override("use_ssl")
+	report.useString(&s3Cfg.AccessKey, objectStore.AccessKey, "access_key")
+	report.useString(&s3Cfg.SecretKey, objectStore.SecretKey, "secret_key")
+
+	bucket := findBucket(objectStore.Buckets, s3Cfg.Bucket)
+	if bucket == nil {
+		err := &BucketMappingError{Bucket: s3Cfg.Bucket}
+		log.Warn().Err(err).Msg("unable to use clowder bucket")
+		return report, err
+	}
+
+	s3Cfg.Bucket = bucket.Name
+	report.override("bucket")
+	report.useString(&s3Cfg.Endpoint, bucket.Endpoint, "endpoint")
+	report.useString(&s3Cfg.Region, bucket.Region, "region")
+	report.useString(&s3Cfg.AccessKey, bucket.AccessKey, "access_key")
+	report.useString(&s3Cfg.SecretKey, bucket.SecretKey, "secret_key")
+	if bucket.Tls != nil {
+		s3Cfg.UseSSL = *bucket.Tls
+	}
+
+	return report, nil
+}
+
+func findBucket(buckets []api.ObjectStoreBucket, requestedName string) *api.ObjectStoreBucket {
+	for i := range buckets {
+		if buckets[i].RequestedName == requestedName {
+			return &buckets[i]
+		}
+	}
+	return nil
+}
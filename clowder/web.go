@@ -0,0 +1,88 @@
+// Copyright 2026 Red Hat, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clowder
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/RedHatInsights/insights-operator-utils/types"
+	api "github.com/redhatinsights/app-common-go/pkg/api/v1"
+	"github.com/rs/zerolog/log"
+)
+
+// ErrNoServerConfig is returned when no ports of HTTP servers are provided by
+// Clowder
+var ErrNoServerConfig = errors.New("no server configuration found in clowder config")
+
+// ErrNoPrometheusGatewayConfig is returned when no Prometheus Pushgateway is
+// provided by Clowder
+var ErrNoPrometheusGatewayConfig = errors.New("no prometheus gateway configuration found in clowder config")
+
+// UseServerConfig tries to replace the addresses of HTTP servers and the path
+// to metrics with the values loaded by Clowder
+func UseServerConfig(serverCfg *types.ServerConfiguration, loadedConfig *api.AppConfig) (ConfigReport, error) {
+	report := ConfigReport{}
+
+	if loadedConfig == nil || (loadedConfig.PublicPort == nil && loadedConfig.WebPort == nil &&
+		loadedConfig.PrivatePort == nil && loadedConfig.MetricsPort <= 0) {
+		log.Warn().Msg(ErrNoServerConfig.Error())
+		return report, ErrNoServerConfig
+	}
+
+	// webPort is deprecated in favour of publicPort
+	publicPort := loadedConfig.PublicPort
+	if publicPort == nil {
+		publicPort = loadedConfig.WebPort
+	}
+	if publicPort != nil {
+		serverCfg.Address = fmt.Sprintf(":%d", *publicPort)
+		report.override("address")
+	}
+
+	if loadedConfig.PrivatePort != nil {
+		serverCfg.PrivateAddress = fmt.Sprintf(":%d", *loadedConfig.PrivatePort)
+		report.override("private_address")
+	}
+
+	if loadedConfig.MetricsPort > 0 {
+		serverCfg.MetricsAddress = fmt.Sprintf(":%d", loadedConfig.MetricsPort)
+		report.override("metrics_address")
+	}
+
+	if loadedConfig.MetricsPath != "" {
+		serverCfg.MetricsPath = loadedConfig.MetricsPath
+		report.override("metrics_path")
+	}
+
+	return report, nil
+}
+
+// UseMetricsConfig tries to replace the Prometheus Pushgateway URL with the
+// value loaded by Clowder
+func UseMetricsConfig(metricsCfg *types.MetricsConfiguration, loadedConfig *api.AppConfig) (ConfigReport, error) {
+	report := ConfigReport{}
+
+	if loadedConfig == nil || loadedConfig.PrometheusGateway == nil {
+		log.Warn().Msg(ErrNoPrometheusGatewayConfig.Error())
+		return report, ErrNoPrometheusGatewayConfig
+	}
+
+	gateway := loadedConfig.PrometheusGateway
+	metricsCfg.GatewayURL = fmt.Sprintf("http://%s:%d", gateway.Hostname, gateway.Port)
+	report.override("gateway_url")
+
+	return report, nil
+}
@@ -0,0 +1,90 @@
+/*
+Copyright © 2026 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package featureflags
+
+// Documentation in literate-programming-style is available at:
+// https://redhatinsights.github.io/insights-operator-utils/packages/featureflags/env_provider.html
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	ctypes "github.com/RedHatInsights/insights-results-types"
+
+	"github.com/RedHatInsights/insights-operator-utils/env"
+)
+
+// EnvFeatureProvider is a provider with the features read from environment
+// variables. Name of the variable is the prefix followed by the feature name
+// in upper case with other characters than letters and digits replaced by
+// underscores, e.g. FEATURE_NEW_REPORT for feature "new-report". The value
+// is either "true" or "false" (in any case) enabling or disabling the
+// feature for all organizations, or a comma separated list of organization
+// IDs, so "1" enables the feature for organization 1 only. Unset variable
+// disables the feature.
+type EnvFeatureProvider struct {
+	prefix string
+}
+
+// NewEnvProvider creates provider reading variables with the prefix
+func NewEnvProvider(prefix string) *EnvFeatureProvider {
+	return &EnvFeatureProvider{prefix: prefix}
+}
+
+// VariableName returns name of environment variable of the feature
+func (provider *EnvFeatureProvider) VariableName(feature string) string {
+	return provider.prefix + strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		default:
+			return '_'
+		}
+	}, feature)
+}
+
+// IsEnabled implements Provider interface. Error is returned when the value
+// of the variable is neither a boolean nor a list of organization IDs.
+func (provider *EnvFeatureProvider) IsEnabled(_ context.Context, feature string, orgID ctypes.OrgID) (bool, error) {
+	name := provider.VariableName(feature)
+	value := strings.TrimSpace(env.GetEnv(name, ""))
+	if value == "" {
+		return false, nil
+	}
+	switch strings.ToLower(value) {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	}
+
+	enabled := false
+	for _, item := range strings.Split(value, ",") {
+		parsed, err := strconv.ParseUint(strings.TrimSpace(item), 10, 32)
+		if err != nil {
+			return false, fmt.Errorf("invalid value of environment variable %s: %w", name, err)
+		}
+		if orgID != 0 && ctypes.OrgID(parsed) == orgID {
+			enabled = true
+		}
+	}
+	return enabled, nil
+}
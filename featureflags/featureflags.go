@@ -0,0 +1,149 @@
+/*
+Copyright © 2026 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package featureflags contains providers deciding whether a feature is
+// enabled for an organization. Features can be enabled in the configuration
+// file, by environment variables, in Redis or in Unleash feature flags
+// service.
+package featureflags
+
+// Documentation in literate-programming-style is available at:
+// https://redhatinsights.github.io/insights-operator-utils/packages/featureflags/featureflags.html
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	ctypes "github.com/RedHatInsights/insights-results-types"
+
+	"github.com/RedHatInsights/insights-operator-utils/redis"
+	"github.com/RedHatInsights/insights-operator-utils/types"
+)
+
+// Supported values of Configuration.Provider
+const (
+	// StaticProvider reads the features from the configuration file
+	StaticProvider = "static"
+	// EnvProvider reads the features from environment variables
+	EnvProvider = "env"
+	// RedisProvider reads the features from Redis sets
+	RedisProvider = "redis"
+	// UnleashProvider reads the features from Unleash feature flags service
+	UnleashProvider = "unleash"
+)
+
+// Default values used for unset fields of Configuration
+const (
+	DefaultEnvPrefix       = "FEATURE_"
+	DefaultRedisKeyPrefix  = "feature:"
+	DefaultRefreshInterval = time.Minute
+)
+
+// Provider decides whether the feature is enabled for the organization.
+// Organization ID 0 is used when the organization is not known, such
+// requests get only the features enabled for all organizations.
+type Provider interface {
+	IsEnabled(ctx context.Context, feature string, orgID ctypes.OrgID) (bool, error)
+}
+
+// FeatureConfiguration represents configuration of one feature in the
+// static provider
+type FeatureConfiguration struct {
+	// Enabled enables the feature for all organizations
+	Enabled bool `mapstructure:"enabled" toml:"enabled"`
+	// Orgs contains organizations the feature is enabled for
+	Orgs []ctypes.OrgID `mapstructure:"orgs" toml:"orgs"`
+}
+
+// Configuration represents configuration of feature flags
+type Configuration struct {
+	// Provider is one of "static" (default), "env", "redis" or "unleash"
+	Provider string `mapstructure:"provider" toml:"provider"`
+	// Features are used by the static provider
+	Features map[string]FeatureConfiguration `mapstructure:"features" toml:"features"`
+	// EnvPrefix is prepended to names of environment variables
+	EnvPrefix string `mapstructure:"env_prefix" toml:"env_prefix"`
+	// RedisKeyPrefix is prepended to keys stored in Redis
+	RedisKeyPrefix string `mapstructure:"redis_key_prefix" toml:"redis_key_prefix"`
+	// Unleash contains URL and token of Unleash feature flags service, it
+	// can be filled in by clowder.UseFeatureFlagsConfig
+	Unleash types.FeatureFlagsConfiguration `mapstructure:"unleash" toml:"unleash"`
+	// RefreshInterval is the time Unleash features are cached for
+	RefreshInterval time.Duration `mapstructure:"refresh_interval" toml:"refresh_interval"`
+}
+
+func (configuration *Configuration) setDefaults() {
+	if configuration.Provider == "" {
+		configuration.Provider = StaticProvider
+	}
+	if configuration.EnvPrefix == "" {
+		configuration.EnvPrefix = DefaultEnvPrefix
+	}
+	if configuration.RedisKeyPrefix == "" {
+		configuration.RedisKeyPrefix = DefaultRedisKeyPrefix
+	}
+	if configuration.RefreshInterval <= 0 {
+		configuration.RefreshInterval = DefaultRefreshInterval
+	}
+}
+
+// NewProvider creates feature flags provider selected by the configuration.
+// Redis client is needed by the redis provider only.
+func NewProvider(configuration Configuration, redisClient *redis.Client) (Provider, error) {
+	configuration.setDefaults()
+
+	switch configuration.Provider {
+	case StaticProvider:
+		return NewStaticProvider(configuration.Features), nil
+	case EnvProvider:
+		return NewEnvProvider(configuration.EnvPrefix), nil
+	case RedisProvider:
+		if redisClient == nil || redisClient.Connection == nil {
+			return nil, errors.New("Redis client is needed by redis feature flags provider")
+		}
+		return NewRedisProvider(redisClient, configuration.RedisKeyPrefix), nil
+	case UnleashProvider:
+		if configuration.Unleash.URL == "" {
+			return nil, errors.New("URL of Unleash service is needed by unleash feature flags provider")
+		}
+		return NewUnleashProvider(configuration.Unleash, configuration.RefreshInterval, nil), nil
+	default:
+		return nil, fmt.Errorf("unsupported feature flags provider '%s'", configuration.Provider)
+	}
+}
+
+// StaticFeatureProvider is a provider with the features read from the
+// configuration, unknown features are disabled
+type StaticFeatureProvider struct {
+	features map[string]FeatureConfiguration
+}
+
+// NewStaticProvider creates provider of the configured features
+func NewStaticProvider(features map[string]FeatureConfiguration) *StaticFeatureProvider {
+	return &StaticFeatureProvider{features: features}
+}
+
+// IsEnabled implements Provider interface
+func (provider *StaticFeatureProvider) IsEnabled(_ context.Context, feature string, orgID ctypes.OrgID) (bool, error) {
+	configuration, found := provider.features[feature]
+	if !found {
+		return false, nil
+	}
+	return configuration.Enabled || (orgID != 0 && slices.Contains(configuration.Orgs, orgID)), nil
+}
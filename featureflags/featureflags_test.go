@@ -0,0 +1,139 @@
+/*
+Copyright © 2026 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package featureflags_test
+
+// Documentation in literate-programming-style is available at:
+// https://redhatinsights.github.io/insights-operator-utils/packages/featureflags/featureflags_test.html
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	ctypes "github.com/RedHatInsights/insights-results-types"
+	"github.com/go-redis/redismock/v9"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/RedHatInsights/insights-operator-utils/featureflags"
+	"github.com/RedHatInsights/insights-operator-utils/redis"
+	"github.com/RedHatInsights/insights-operator-utils/tests/helpers"
+	"github.com/RedHatInsights/insights-operator-utils/types"
+)
+
+func assertEnabled(t *testing.T, provider featureflags.Provider, feature string, orgID ctypes.OrgID, expected bool) {
+	enabled, err := provider.IsEnabled(context.Background(), feature, orgID)
+	helpers.FailOnError(t, err)
+	assert.Equal(t, expected, enabled, "feature %s for org %d", feature, orgID)
+}
+
+func TestNewProvider(t *testing.T) {
+	provider, err := featureflags.NewProvider(featureflags.Configuration{}, nil)
+	helpers.FailOnError(t, err)
+	assert.IsType(t, &featureflags.StaticFeatureProvider{}, provider)
+
+	provider, err = featureflags.NewProvider(featureflags.Configuration{Provider: featureflags.EnvProvider}, nil)
+	helpers.FailOnError(t, err)
+	assert.IsType(t, &featureflags.EnvFeatureProvider{}, provider)
+
+	client, _ := redismock.NewClientMock()
+	provider, err = featureflags.NewProvider(
+		featureflags.Configuration{Provider: featureflags.RedisProvider}, &redis.Client{Connection: client},
+	)
+	helpers.FailOnError(t, err)
+	assert.IsType(t, &featureflags.RedisFeatureProvider{}, provider)
+
+	provider, err = featureflags.NewProvider(featureflags.Configuration{
+		Provider: featureflags.UnleashProvider,
+		Unleash:  types.FeatureFlagsConfiguration{URL: "http://localhost:4242/api"},
+	}, nil)
+	helpers.FailOnError(t, err)
+	assert.IsType(t, &featureflags.UnleashFeatureProvider{}, provider)
+}
+
+func TestNewProviderInvalidConfiguration(t *testing.T) {
+	for _, configuration := range []featureflags.Configuration{
+		{Provider: featureflags.RedisProvider},
+		{Provider: featureflags.UnleashProvider},
+		{Provider: "launchdarkly"},
+	} {
+		_, err := featureflags.NewProvider(configuration, nil)
+		assert.Error(t, err, configuration.Provider)
+	}
+}
+
+func TestStaticProvider(t *testing.T) {
+	provider := featureflags.NewStaticProvider(map[string]featureflags.FeatureConfiguration{
+		"new-report": {Enabled: true},
+		"upgrade":    {Orgs: []ctypes.OrgID{1, 2}},
+	})
+
+	assertEnabled(t, provider, "new-report", 0, true)
+	assertEnabled(t, provider, "new-report", 3, true)
+	assertEnabled(t, provider, "upgrade", 2, true)
+	assertEnabled(t, provider, "upgrade", 3, false)
+	assertEnabled(t, provider, "upgrade", 0, false)
+	assertEnabled(t, provider, "unknown", 1, false)
+}
+
+func TestEnvProvider(t *testing.T) {
+	provider := featureflags.NewEnvProvider(featureflags.DefaultEnvPrefix)
+	assert.Equal(t, "FEATURE_NEW_REPORT_V2", provider.VariableName("new-report.v2"))
+
+	t.Setenv("FEATURE_NEW_REPORT", "true")
+	t.Setenv("FEATURE_UPGRADE", "1, 2")
+	t.Setenv("FEATURE_DISABLED", "false")
+
+	assertEnabled(t, provider, "new-report", 0, true)
+	assertEnabled(t, provider, "upgrade", 2, true)
+	assertEnabled(t, provider, "upgrade", 3, false)
+	assertEnabled(t, provider, "disabled", 1, false)
+	assertEnabled(t, provider, "unknown", 1, false)
+
+	// only "true" and "false" are booleans, other values are organizations
+	t.Setenv("FEATURE_ORG_ONE", "1")
+	t.Setenv("FEATURE_UPPER", "TRUE")
+	assertEnabled(t, provider, "org-one", 1, true)
+	assertEnabled(t, provider, "org-one", 2, false)
+	assertEnabled(t, provider, "upper", 2, true)
+
+	t.Setenv("FEATURE_INVALID", "yes")
+	_, err := provider.IsEnabled(context.Background(), "invalid", 1)
+	assert.Error(t, err)
+
+	t.Setenv("FEATURE_INVALID", "1,org")
+	_, err = provider.IsEnabled(context.Background(), "invalid", 1)
+	assert.Error(t, err)
+}
+
+func TestRedisProvider(t *testing.T) {
+	client, mock := redismock.NewClientMock()
+	provider := featureflags.NewRedisProvider(&redis.Client{Connection: client}, "feature:")
+
+	mock.ExpectSMIsMember("feature:upgrade", featureflags.AllOrgsMember, "2").SetVal([]bool{false, true})
+	mock.ExpectSMIsMember("feature:upgrade", featureflags.AllOrgsMember, "3").SetVal([]bool{false, false})
+	mock.ExpectSMIsMember("feature:new-report", featureflags.AllOrgsMember, "0").SetVal([]bool{true, false})
+
+	assertEnabled(t, provider, "upgrade", 2, true)
+	assertEnabled(t, provider, "upgrade", 3, false)
+	assertEnabled(t, provider, "new-report", 0, true)
+
+	mock.ExpectSMIsMember("feature:upgrade", featureflags.AllOrgsMember, "1").SetErr(errors.New("connection refused"))
+	_, err := provider.IsEnabled(context.Background(), "upgrade", 1)
+	assert.Error(t, err)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,58 @@
+/*
+Copyright © 2026 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package featureflags
+
+// Documentation in literate-programming-style is available at:
+// https://redhatinsights.github.io/insights-operator-utils/packages/featureflags/redis_provider.html
+
+import (
+	"context"
+	"fmt"
+
+	ctypes "github.com/RedHatInsights/insights-results-types"
+
+	"github.com/RedHatInsights/insights-operator-utils/redis"
+)
+
+// AllOrgsMember is the member of Redis set enabling the feature for all
+// organizations
+const AllOrgsMember = "*"
+
+// RedisFeatureProvider is a provider with the features stored in Redis, so
+// they can be changed at runtime for all instances of the service. Every
+// feature is a set under the prefixed key with IDs of the organizations the
+// feature is enabled for, or with "*" member enabling it for all of them.
+type RedisFeatureProvider struct {
+	client *redis.Client
+	prefix string
+}
+
+// NewRedisProvider creates provider reading sets with the key prefix
+func NewRedisProvider(client *redis.Client, prefix string) *RedisFeatureProvider {
+	return &RedisFeatureProvider{client: client, prefix: prefix}
+}
+
+// IsEnabled implements Provider interface
+func (provider *RedisFeatureProvider) IsEnabled(ctx context.Context, feature string, orgID ctypes.OrgID) (bool, error) {
+	members, err := provider.client.Connection.SMIsMember(
+		ctx, provider.prefix+feature, AllOrgsMember, fmt.Sprint(orgID),
+	).Result()
+	if err != nil {
+		return false, err
+	}
+	return members[0] || (orgID != 0 && members[1]), nil
+}
@@ -0,0 +1,276 @@
+/*
+Copyright © 2026 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package featureflags
+
+// Documentation in literate-programming-style is available at:
+// https://redhatinsights.github.io/insights-operator-utils/packages/featureflags/unleash_provider.html
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	ctypes "github.com/RedHatInsights/insights-results-types"
+	"github.com/rs/zerolog/log"
+
+	"github.com/RedHatInsights/insights-operator-utils/types"
+)
+
+const (
+	// UnleashFeaturesEndpoint is the endpoint of Unleash client API
+	// returning all features, relative to URL of the service
+	UnleashFeaturesEndpoint = "/client/features"
+	// UnleashOrgIDContextName is the name of context field with
+	// organization ID used in strategy constraints
+	UnleashOrgIDContextName = "orgId"
+
+	// UnleashTimeout is the timeout of requests to Unleash service sent by
+	// the default client
+	UnleashTimeout = 10 * time.Second
+
+	unleashDefaultStrategy         = "default"
+	unleashFlexibleRolloutStrategy = "flexibleRollout"
+	unleashInOperator              = "IN"
+	unleashNotInOperator           = "NOT_IN"
+)
+
+type unleashFeatures struct {
+	Features []unleashFeature `json:"features"`
+}
+
+type unleashFeature struct {
+	Name       string            `json:"name"`
+	Enabled    bool              `json:"enabled"`
+	Strategies []unleashStrategy `json:"strategies"`
+}
+
+type unleashStrategy struct {
+	Name        string                 `json:"name"`
+	Parameters  map[string]interface{} `json:"parameters"`
+	Constraints []unleashConstraint    `json:"constraints"`
+}
+
+type unleashConstraint struct {
+	ContextName string   `json:"contextName"`
+	Operator    string   `json:"operator"`
+	Values      []string `json:"values"`
+	Inverted    bool     `json:"inverted"`
+}
+
+// UnleashFeatureProvider is a provider with the features read from Unleash
+// feature flags service, or from any service implementing its client API,
+// e.g. a local stub. The features are cached for the refresh interval and
+// the cached features are used when the service is not available. Expired
+// features are refreshed in background while the cached ones are still
+// served, only one request to the service is in flight at a time.
+//
+// Enabled feature is enabled for the organization when it has no strategies
+// or when any of its strategies matches. Supported strategies are "default"
+// and "flexibleRollout" with 100% rollout, both can be restricted by IN and
+// NOT_IN constraints of "orgId" context field. Other strategies never match.
+type UnleashFeatureProvider struct {
+	configuration   types.FeatureFlagsConfiguration
+	refreshInterval time.Duration
+	client          *http.Client
+
+	mutex     sync.Mutex
+	features  map[string]unleashFeature
+	fetchedAt time.Time
+	// inFlight is the fetch in progress, nil when there's none
+	inFlight *unleashFetch
+}
+
+// unleashFetch is a fetch of features shared by all callers waiting for it,
+// its result is available once done is closed
+type unleashFetch struct {
+	done     chan struct{}
+	features map[string]unleashFeature
+	err      error
+}
+
+// NewUnleashProvider creates provider reading features from Unleash service
+// with the URL and token from the configuration. Client with UnleashTimeout
+// is used when client is nil.
+func NewUnleashProvider(
+	configuration types.FeatureFlagsConfiguration, refreshInterval time.Duration, client *http.Client,
+) *UnleashFeatureProvider {
+	if refreshInterval <= 0 {
+		refreshInterval = DefaultRefreshInterval
+	}
+	if client == nil {
+		client = &http.Client{Timeout: UnleashTimeout}
+	}
+	return &UnleashFeatureProvider{
+		configuration:   configuration,
+		refreshInterval: refreshInterval,
+		client:          client,
+	}
+}
+
+// IsEnabled implements Provider interface
+func (provider *UnleashFeatureProvider) IsEnabled(ctx context.Context, feature string, orgID ctypes.OrgID) (bool, error) {
+	features, err := provider.getFeatures(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	definition, found := features[feature]
+	if !found || !definition.Enabled {
+		return false, nil
+	}
+	if len(definition.Strategies) == 0 {
+		return true, nil
+	}
+	for _, strategy := range definition.Strategies {
+		if strategy.matches(orgID) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// getFeatures returns the cached features. Features that are not cached yet
+// are fetched, expired ones are refreshed in background.
+func (provider *UnleashFeatureProvider) getFeatures(ctx context.Context) (map[string]unleashFeature, error) {
+	provider.mutex.Lock()
+	features := provider.features
+	if features != nil && time.Since(provider.fetchedAt) < provider.refreshInterval {
+		provider.mutex.Unlock()
+		return features, nil
+	}
+	fetch := provider.startFetch()
+	provider.mutex.Unlock()
+
+	if features != nil {
+		return features, nil
+	}
+
+	select {
+	case <-fetch.done:
+		return fetch.features, fetch.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// startFetch starts fetching the features unless it's in progress already,
+// the mutex needs to be locked by the caller
+func (provider *UnleashFeatureProvider) startFetch() *unleashFetch {
+	if provider.inFlight != nil {
+		return provider.inFlight
+	}
+
+	fetch := &unleashFetch{done: make(chan struct{})}
+	provider.inFlight = fetch
+	go func() {
+		// the fetch is shared, so it's not canceled with the context of
+		// any of the callers
+		fetch.features, fetch.err = provider.fetch(context.Background())
+
+		provider.mutex.Lock()
+		provider.inFlight = nil
+		if fetch.err == nil {
+			provider.features = fetch.features
+			provider.fetchedAt = time.Now()
+		} else if provider.features != nil {
+			// the service is not asked again until the next refresh
+			log.Warn().Err(fetch.err).Msg("Unable to refresh feature flags, cached features are used")
+			provider.fetchedAt = time.Now()
+		}
+		provider.mutex.Unlock()
+		close(fetch.done)
+	}()
+	return fetch
+}
+
+func (provider *UnleashFeatureProvider) fetch(ctx context.Context) (map[string]unleashFeature, error) {
+	url := strings.TrimSuffix(provider.configuration.URL, "/") + UnleashFeaturesEndpoint
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, url, http.NoBody)
+	if err != nil {
+		return nil, err
+	}
+	if provider.configuration.Token != "" {
+		request.Header.Set("Authorization", provider.configuration.Token)
+	}
+
+	response, err := provider.client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if closeErr := response.Body.Close(); closeErr != nil {
+			log.Error().Err(closeErr).Msg("Unable to close response body")
+		}
+	}()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d of feature flags service", response.StatusCode)
+	}
+
+	var decoded unleashFeatures
+	if err := json.NewDecoder(response.Body).Decode(&decoded); err != nil {
+		return nil, fmt.Errorf("unable to decode features: %w", err)
+	}
+
+	features := make(map[string]unleashFeature, len(decoded.Features))
+	for _, feature := range decoded.Features {
+		features[feature.Name] = feature
+	}
+	return features, nil
+}
+
+func (strategy unleashStrategy) matches(orgID ctypes.OrgID) bool {
+	switch strategy.Name {
+	case unleashDefaultStrategy:
+	case unleashFlexibleRolloutStrategy:
+		if fmt.Sprint(strategy.Parameters["rollout"]) != "100" {
+			return false
+		}
+	default:
+		return false
+	}
+
+	for _, constraint := range strategy.Constraints {
+		if !constraint.matches(orgID) {
+			return false
+		}
+	}
+	return true
+}
+
+func (constraint unleashConstraint) matches(orgID ctypes.OrgID) bool {
+	if constraint.ContextName != UnleashOrgIDContextName || orgID == 0 {
+		return false
+	}
+
+	var result bool
+	contains := slices.Contains(constraint.Values, fmt.Sprint(orgID))
+	switch constraint.Operator {
+	case unleashInOperator:
+		result = contains
+	case unleashNotInOperator:
+		result = !contains
+	default:
+		return false
+	}
+	return result != constraint.Inverted
+}
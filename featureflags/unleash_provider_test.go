@@ -0,0 +1,184 @@
+/*
+Copyright © 2026 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package featureflags_test
+
+// Documentation in literate-programming-style is available at:
+// https://redhatinsights.github.io/insights-operator-utils/packages/featureflags/unleash_provider_test.html
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/RedHatInsights/insights-operator-utils/featureflags"
+	"github.com/RedHatInsights/insights-operator-utils/types"
+)
+
+const unleashFeatures = `{
+	"version": 1,
+	"features": [
+		{"name": "new-report", "enabled": true, "strategies": [{"name": "default"}]},
+		{"name": "no-strategies", "enabled": true},
+		{"name": "disabled", "enabled": false, "strategies": [{"name": "default"}]},
+		{"name": "upgrade", "enabled": true, "strategies": [
+			{"name": "default", "constraints": [
+				{"contextName": "orgId", "operator": "IN", "values": ["1", "2"]}
+			]},
+			{"name": "flexibleRollout", "parameters": {"rollout": "100"}, "constraints": [
+				{"contextName": "orgId", "operator": "NOT_IN", "values": ["1", "2", "3"], "inverted": true}
+			]}
+		]},
+		{"name": "gradual", "enabled": true, "strategies": [
+			{"name": "flexibleRollout", "parameters": {"rollout": "50"}},
+			{"name": "remoteAddress", "parameters": {"IPs": "127.0.0.1"}}
+		]}
+	]
+}`
+
+// unleashStub is a local stub of Unleash client API
+func unleashStub(t *testing.T, status *atomic.Int32, requests *atomic.Int32) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		requests.Add(1)
+		assert.Equal(t, "/api"+featureflags.UnleashFeaturesEndpoint, request.URL.Path)
+		assert.Equal(t, "token", request.Header.Get("Authorization"))
+
+		writer.WriteHeader(int(status.Load()))
+		_, err := writer.Write([]byte(unleashFeatures))
+		assert.NoError(t, err)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestUnleashProvider(t *testing.T) {
+	var status, requests atomic.Int32
+	status.Store(http.StatusOK)
+	server := unleashStub(t, &status, &requests)
+
+	provider := featureflags.NewUnleashProvider(
+		types.FeatureFlagsConfiguration{URL: server.URL + "/api/", Token: "token"}, time.Hour, nil,
+	)
+
+	assertEnabled(t, provider, "new-report", 0, true)
+	assertEnabled(t, provider, "no-strategies", 5, true)
+	assertEnabled(t, provider, "disabled", 1, false)
+	assertEnabled(t, provider, "upgrade", 1, true)
+	assertEnabled(t, provider, "upgrade", 3, true)
+	assertEnabled(t, provider, "upgrade", 4, false)
+	assertEnabled(t, provider, "upgrade", 0, false)
+	assertEnabled(t, provider, "gradual", 1, false)
+	assertEnabled(t, provider, "unknown", 1, false)
+
+	// features are cached for the refresh interval
+	assert.Equal(t, int32(1), requests.Load())
+}
+
+func TestUnleashProviderUnavailable(t *testing.T) {
+	var status, requests atomic.Int32
+	status.Store(http.StatusServiceUnavailable)
+	server := unleashStub(t, &status, &requests)
+
+	provider := featureflags.NewUnleashProvider(
+		types.FeatureFlagsConfiguration{URL: server.URL + "/api", Token: "token"}, time.Nanosecond, nil,
+	)
+
+	_, err := provider.IsEnabled(context.Background(), "new-report", 1)
+	assert.EqualError(t, err, "unexpected status code 503 of feature flags service")
+
+	status.Store(http.StatusOK)
+	assertEnabled(t, provider, "new-report", 1, true)
+
+	// cached features are used when the service becomes unavailable
+	status.Store(http.StatusServiceUnavailable)
+	time.Sleep(time.Millisecond)
+	assertEnabled(t, provider, "new-report", 1, true)
+	assert.Eventually(t, func() bool { return requests.Load() == 3 }, time.Second, time.Millisecond)
+}
+
+// blockingUnleashStub is a stub of Unleash client API that doesn't respond
+// until release is closed
+func blockingUnleashStub(t *testing.T, requests *atomic.Int32, release chan struct{}) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		if requests.Add(1) > 1 {
+			<-release
+		}
+		_, err := writer.Write([]byte(unleashFeatures))
+		assert.NoError(t, err)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestUnleashProviderServesCacheDuringRefresh(t *testing.T) {
+	var requests atomic.Int32
+	release := make(chan struct{})
+	server := blockingUnleashStub(t, &requests, release)
+
+	provider := featureflags.NewUnleashProvider(
+		types.FeatureFlagsConfiguration{URL: server.URL}, time.Nanosecond, nil,
+	)
+	assertEnabled(t, provider, "new-report", 1, true)
+
+	// refresh is blocked, but the callers get the cached features and no
+	// other request is sent
+	time.Sleep(time.Millisecond)
+	for range 10 {
+		assertEnabled(t, provider, "new-report", 1, true)
+	}
+	assert.Eventually(t, func() bool { return requests.Load() == 2 }, time.Second, time.Millisecond)
+
+	close(release)
+}
+
+func TestUnleashProviderSharedFetch(t *testing.T) {
+	var requests atomic.Int32
+	requests.Store(1)
+	release := make(chan struct{})
+	server := blockingUnleashStub(t, &requests, release)
+
+	provider := featureflags.NewUnleashProvider(
+		types.FeatureFlagsConfiguration{URL: server.URL}, time.Hour, nil,
+	)
+
+	// callers waiting for the first fetch share it
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			enabled, err := provider.IsEnabled(context.Background(), "new-report", 1)
+			assert.NoError(t, err)
+			assert.True(t, enabled)
+		}()
+	}
+
+	// caller can stop waiting
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	_, err := provider.IsEnabled(ctx, "new-report", 1)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	close(release)
+	wg.Wait()
+	assert.Equal(t, int32(2), requests.Load())
+}
@@ -0,0 +1,191 @@
+// Copyright 2026 Red Hat, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httputils
+
+// Documentation in literate-programming-style is available at:
+// https://redhatinsights.github.io/insights-operator-utils/packages/http/auth.html
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	ctypes "github.com/RedHatInsights/insights-results-types"
+	"github.com/gorilla/mux"
+	"github.com/rs/zerolog/log"
+
+	"github.com/RedHatInsights/insights-operator-utils/types"
+)
+
+// Supported values of AuthenticationConfiguration.Type
+const (
+	// XRHAuthType reads identity from base64 encoded x-rh-identity header
+	XRHAuthType = "xrh"
+	// JWTAuthType reads identity from JWT bearer token in Authorization
+	// header
+	JWTAuthType = "jwt"
+)
+
+// Names of headers containing the authentication token
+const (
+	XRHIdentityHeader   = "x-rh-identity"
+	AuthorizationHeader = "Authorization"
+)
+
+const bearerPrefix = "bearer "
+
+// AuthenticationConfiguration represents configuration of Authenticate
+// middleware
+type AuthenticationConfiguration struct {
+	// Type is either "xrh" (default) or "jwt"
+	Type string `mapstructure:"type" toml:"type"`
+	// AllowedIdentityTypes contains accepted values of identity type
+	// (e.g. "User" or "ServiceAccount"), all types are accepted when the
+	// list is empty. Only x-rh-identity tokens contain the type.
+	AllowedIdentityTypes []string `mapstructure:"allowed_identity_types" toml:"allowed_identity_types"`
+	// ExemptPaths contains paths that don't require authentication. Path
+	// ending with "*" matches all paths with the given prefix.
+	ExemptPaths []string `mapstructure:"exempt_paths" toml:"exempt_paths"`
+}
+
+// Authenticate creates a middleware that decodes the identity of user from
+// the request and stores it in request context under ctypes.ContextKeyUser
+// key, where it is expected by CheckPermissions. Requests with missing or
+// invalid token are refused with 401 Unauthorized.
+//
+// Signature of JWT tokens is not verified, the service is expected to run
+// behind a gateway that has already authenticated the request.
+func Authenticate(configuration AuthenticationConfiguration) (mux.MiddlewareFunc, error) {
+	var decode func(request *http.Request) (ctypes.Identity, error)
+
+	switch configuration.Type {
+	case "", XRHAuthType:
+		decode = func(request *http.Request) (ctypes.Identity, error) {
+			return decodeXRHIdentity(request, configuration.AllowedIdentityTypes)
+		}
+	case JWTAuthType:
+		decode = decodeJWTIdentity
+	default:
+		return nil, fmt.Errorf("unsupported authentication type '%s'", configuration.Type)
+	}
+
+	return func(nextHandler http.Handler) http.Handler {
+		return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			if isExemptPath(request.URL.Path, configuration.ExemptPaths) {
+				nextHandler.ServeHTTP(writer, request)
+				return
+			}
+
+			identity, err := decode(request)
+			if err != nil {
+				types.HandleServerError(writer, err)
+				return
+			}
+
+			setRequestOrgID(request.Context(), identity.OrgID)
+			ctx := context.WithValue(request.Context(), ctypes.ContextKeyUser, identity)
+			nextHandler.ServeHTTP(writer, request.WithContext(ctx))
+		})
+	}, nil
+}
+
+// GetIdentity returns identity stored in request context by Authenticate
+// middleware
+func GetIdentity(request *http.Request) (ctypes.Identity, bool) {
+	identity, ok := request.Context().Value(ctypes.ContextKeyUser).(ctypes.Identity)
+	return identity, ok
+}
+
+func isExemptPath(path string, exemptPaths []string) bool {
+	for _, exemptPath := range exemptPaths {
+		if prefix, isPrefix := strings.CutSuffix(exemptPath, "*"); isPrefix {
+			if strings.HasPrefix(path, prefix) {
+				return true
+			}
+		} else if path == exemptPath {
+			return true
+		}
+	}
+	return false
+}
+
+func decodeXRHIdentity(request *http.Request, allowedTypes []string) (ctypes.Identity, error) {
+	header := request.Header.Get(XRHIdentityHeader)
+	if header == "" {
+		return ctypes.Identity{}, unauthorized("missing %s header", XRHIdentityHeader)
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(header)
+	if err != nil {
+		return ctypes.Identity{}, unauthorized("%s header is not base64 encoded", XRHIdentityHeader)
+	}
+
+	var token ctypes.Token
+	if err := json.Unmarshal(decoded, &token); err != nil {
+		log.Warn().Err(err).Msg("Unable to unmarshal identity")
+		return ctypes.Identity{}, unauthorized("malformed %s header", XRHIdentityHeader)
+	}
+
+	if len(allowedTypes) > 0 && !slices.Contains(allowedTypes, token.Identity.Type) {
+		return ctypes.Identity{}, unauthorized("identity type '%s' is not allowed", token.Identity.Type)
+	}
+
+	return token.Identity, checkOrgID(token.Identity)
+}
+
+func decodeJWTIdentity(request *http.Request) (ctypes.Identity, error) {
+	header := request.Header.Get(AuthorizationHeader)
+	if len(header) <= len(bearerPrefix) || !strings.EqualFold(header[:len(bearerPrefix)], bearerPrefix) {
+		return ctypes.Identity{}, unauthorized("missing bearer token in %s header", AuthorizationHeader)
+	}
+
+	parts := strings.Split(header[len(bearerPrefix):], ".")
+	if len(parts) != 3 {
+		return ctypes.Identity{}, unauthorized("malformed JWT token")
+	}
+
+	decoded, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return ctypes.Identity{}, unauthorized("JWT token payload is not base64 encoded")
+	}
+
+	var payload ctypes.JWTPayload
+	if err := json.Unmarshal(decoded, &payload); err != nil {
+		log.Warn().Err(err).Msg("Unable to unmarshal JWT payload")
+		return ctypes.Identity{}, unauthorized("malformed JWT token payload")
+	}
+
+	identity := ctypes.Identity{
+		AccountNumber: payload.AccountNumber,
+		OrgID:         payload.OrgID,
+		User:          ctypes.User{UserID: payload.UserID},
+	}
+	return identity, checkOrgID(identity)
+}
+
+func checkOrgID(identity ctypes.Identity) error {
+	if identity.OrgID == 0 {
+		return unauthorized("organization ID is missing in the identity")
+	}
+	return nil
+}
+
+func unauthorized(format string, args ...interface{}) error {
+	return &types.UnauthorizedError{ErrString: fmt.Sprintf(format, args...)}
+}
@@ -0,0 +1,170 @@
+// Copyright 2026 Red Hat, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httputils_test
+
+// Documentation in literate-programming-style is available at:
+// https://redhatinsights.github.io/insights-operator-utils/packages/http/auth_test.html
+
+import (
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	ctypes "github.com/RedHatInsights/insights-results-types"
+	"github.com/stretchr/testify/assert"
+
+	httputils "github.com/RedHatInsights/insights-operator-utils/http"
+	"github.com/RedHatInsights/insights-operator-utils/tests/helpers"
+)
+
+const testOrgID = ctypes.OrgID(42)
+
+func makeIdentityToken(t *testing.T, orgID ctypes.OrgID, identityType string) string {
+	return helpers.MakeXRHTokenString(t, &ctypes.Token{
+		Identity: ctypes.Identity{
+			AccountNumber: "1",
+			OrgID:         orgID,
+			User:          ctypes.User{UserID: "1"},
+			Type:          identityType,
+		},
+	})
+}
+
+func makeJWTToken(payload string) string {
+	return "Bearer header." + base64.RawURLEncoding.EncodeToString([]byte(payload)) + ".signature"
+}
+
+// serveAuthenticated sends request through the Authenticate middleware to a
+// handler checking permissions to testOrgID
+func serveAuthenticated(
+	t *testing.T, configuration httputils.AuthenticationConfiguration, request *http.Request,
+) *httptest.ResponseRecorder {
+	middleware, err := httputils.Authenticate(configuration)
+	helpers.FailOnError(t, err)
+
+	handler := middleware(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if httputils.CheckPermissions(writer, request, testOrgID, true) {
+			writer.WriteHeader(http.StatusNoContent)
+		}
+	}))
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func TestAuthenticateXRHIdentity(t *testing.T) {
+	configuration := httputils.AuthenticationConfiguration{
+		AllowedIdentityTypes: []string{"User"},
+	}
+
+	testCases := []struct {
+		name           string
+		header         string
+		expectedStatus int
+	}{
+		{"valid identity", makeIdentityToken(t, testOrgID, "User"), http.StatusNoContent},
+		{"different organization", makeIdentityToken(t, 1, "User"), http.StatusForbidden},
+		{"missing header", "", http.StatusUnauthorized},
+		{"not base64", "not base64!", http.StatusUnauthorized},
+		{"not JSON", base64.StdEncoding.EncodeToString([]byte("{")), http.StatusUnauthorized},
+		{"missing org ID", makeIdentityToken(t, 0, "User"), http.StatusUnauthorized},
+		{"not allowed type", makeIdentityToken(t, testOrgID, "System"), http.StatusUnauthorized},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, "/api/v1/report", http.NoBody)
+			if tc.header != "" {
+				request.Header.Set(httputils.XRHIdentityHeader, tc.header)
+			}
+			recorder := serveAuthenticated(t, configuration, request)
+			assert.Equal(t, tc.expectedStatus, recorder.Code)
+		})
+	}
+}
+
+func TestAuthenticateJWT(t *testing.T) {
+	configuration := httputils.AuthenticationConfiguration{Type: httputils.JWTAuthType}
+
+	testCases := []struct {
+		name           string
+		header         string
+		expectedStatus int
+	}{
+		{"valid token", makeJWTToken(`{"account_number": "1", "org_id": "42", "user_id": "1"}`), http.StatusNoContent},
+		{"lowercase scheme", "bearer" + makeJWTToken(`{"org_id": "42"}`)[len("Bearer"):], http.StatusNoContent},
+		{"different organization", makeJWTToken(`{"org_id": "1"}`), http.StatusForbidden},
+		{"missing header", "", http.StatusUnauthorized},
+		{"not bearer", "Basic dXNlcjpwYXNz", http.StatusUnauthorized},
+		{"missing parts", "Bearer token", http.StatusUnauthorized},
+		{"not base64", "Bearer header.!.signature", http.StatusUnauthorized},
+		{"not JSON", makeJWTToken("{"), http.StatusUnauthorized},
+		{"missing org ID", makeJWTToken(`{"user_id": "1"}`), http.StatusUnauthorized},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, "/api/v1/report", http.NoBody)
+			if tc.header != "" {
+				request.Header.Set(httputils.AuthorizationHeader, tc.header)
+			}
+			recorder := serveAuthenticated(t, configuration, request)
+			assert.Equal(t, tc.expectedStatus, recorder.Code)
+		})
+	}
+}
+
+func TestAuthenticateExemptPaths(t *testing.T) {
+	configuration := httputils.AuthenticationConfiguration{
+		ExemptPaths: []string{"/api/v1/openapi.json", "/metrics*"},
+	}
+
+	for _, path := range []string{"/api/v1/openapi.json", "/metrics", "/metrics/internal"} {
+		request := httptest.NewRequest(http.MethodGet, path, http.NoBody)
+		recorder := serveAuthenticated(t, configuration, request)
+		assert.Equal(t, http.StatusNoContent, recorder.Code, path)
+	}
+
+	request := httptest.NewRequest(http.MethodGet, "/api/v1/openapi.json/other", http.NoBody)
+	recorder := serveAuthenticated(t, configuration, request)
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+}
+
+func TestAuthenticateStoresIdentity(t *testing.T) {
+	middleware, err := httputils.Authenticate(httputils.AuthenticationConfiguration{})
+	helpers.FailOnError(t, err)
+
+	var identity ctypes.Identity
+	var found bool
+	handler := middleware(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		identity, found = httputils.GetIdentity(request)
+	}))
+
+	request := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+	request.Header.Set(httputils.XRHIdentityHeader, makeIdentityToken(t, testOrgID, "User"))
+	handler.ServeHTTP(httptest.NewRecorder(), request)
+
+	assert.True(t, found)
+	assert.Equal(t, testOrgID, identity.OrgID)
+	assert.Equal(t, "User", identity.Type)
+	assert.Equal(t, ctypes.UserID("1"), identity.User.UserID)
+}
+
+func TestAuthenticateUnsupportedType(t *testing.T) {
+	_, err := httputils.Authenticate(httputils.AuthenticationConfiguration{Type: "basic"})
+	assert.Error(t, err)
+}
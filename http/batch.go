@@ -0,0 +1,237 @@
+// Copyright 2026 Red Hat, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httputils
+
+// Documentation in literate-programming-style is available at:
+// https://redhatinsights.github.io/insights-operator-utils/packages/http/batch.html
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	ctypes "github.com/RedHatInsights/insights-results-types"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+
+	"github.com/RedHatInsights/insights-operator-utils/responses"
+	"github.com/RedHatInsights/insights-operator-utils/types"
+)
+
+// DefaultMaxBatchSize is the number of items allowed in batch requests when
+// BatchOptions.MaxSize is not set
+const DefaultMaxBatchSize = 1000
+
+// BatchOptions describes limits of batch requests
+type BatchOptions struct {
+	MaxSize int
+}
+
+// BatchItemError describes an item of batch request that is not valid
+type BatchItemError struct {
+	Item  string
+	Error string
+}
+
+// Batch contains valid items of batch request, without duplicates and in
+// the order of the request, and errors of invalid items
+type Batch[T comparable] struct {
+	Valid  []T
+	Errors []BatchItemError
+
+	// order lists the items in the order of the request
+	order []batchEntry
+}
+
+// batchEntry refers to an item of Valid or Errors
+type batchEntry struct {
+	valid bool
+	index int
+}
+
+// ClusterBatch is a batch of cluster names
+type ClusterBatch = Batch[ctypes.ClusterName]
+
+// OrgBatch is a batch of organization IDs
+type OrgBatch = Batch[ctypes.OrgID]
+
+// Results returns per-item results for responses.BuildBatchResponse in the
+// order of the request. Valid items are reported as processed unless the
+// failed map contains an error for them, invalid items are reported with
+// their validation errors.
+func (batch Batch[T]) Results(failed map[T]error) []responses.BatchItemResult {
+	order := batch.order
+	if len(order) != len(batch.Valid)+len(batch.Errors) {
+		// batch not created by validation, valid items are reported first
+		order = make([]batchEntry, 0, len(batch.Valid)+len(batch.Errors))
+		for i := range batch.Valid {
+			order = append(order, batchEntry{valid: true, index: i})
+		}
+		for i := range batch.Errors {
+			order = append(order, batchEntry{index: i})
+		}
+	}
+
+	results := make([]responses.BatchItemResult, 0, len(order))
+	for _, entry := range order {
+		if !entry.valid {
+			itemError := batch.Errors[entry.index]
+			results = append(results, responses.BatchItemResult{
+				Item:   itemError.Item,
+				Status: responses.BatchItemError,
+				Error:  itemError.Error,
+			})
+			continue
+		}
+		item := batch.Valid[entry.index]
+		result := responses.BatchItemResult{Item: fmt.Sprint(item), Status: responses.BatchItemOK}
+		if err := failed[item]; err != nil {
+			result.Status = responses.BatchItemError
+			result.Error = err.Error()
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// ValidateClusterBatch validates all cluster names like ValidateClusterName,
+// normalizes them to the canonical form of UUID and removes duplicates.
+// ValidationError is returned when the batch is larger than allowed.
+func ValidateClusterBatch(clusterNames []string, options BatchOptions) (ClusterBatch, error) {
+	return validateBatch(clusterNames, "clusters", options, parseClusterName)
+}
+
+// parseClusterName validates the cluster name and returns it in the
+// canonical form, errors are not logged as they are reported per item
+func parseClusterName(clusterName string) (ctypes.ClusterName, error) {
+	parsed, err := uuid.Parse(clusterName)
+	if err != nil {
+		return "", &types.RouterParsingError{
+			ParamName:  "cluster",
+			ParamValue: clusterName,
+			ErrString:  err.Error(),
+		}
+	}
+	return ctypes.ClusterName(parsed.String()), nil
+}
+
+// ValidateOrgBatch validates all organization IDs by ValidateOrgID and
+// removes duplicates. ValidationError is returned when the batch is larger
+// than allowed.
+func ValidateOrgBatch(orgIDs []string, options BatchOptions) (OrgBatch, error) {
+	return validateBatch(orgIDs, "organizations", options, ValidateOrgID)
+}
+
+func validateBatch[T comparable](
+	items []string, paramName string, options BatchOptions, validate func(string) (T, error),
+) (Batch[T], error) {
+	if options.MaxSize <= 0 {
+		options.MaxSize = DefaultMaxBatchSize
+	}
+
+	batch := Batch[T]{Valid: []T{}}
+	if len(items) > options.MaxSize {
+		return batch, &types.ValidationError{
+			ParamName:  paramName,
+			ParamValue: len(items),
+			ErrString:  fmt.Sprintf("at most %d items are allowed in one request", options.MaxSize),
+		}
+	}
+
+	seenValid := make(map[T]struct{}, len(items))
+	seenInvalid := make(map[string]struct{})
+	for _, item := range items {
+		value, err := validate(item)
+		if err != nil {
+			if _, seen := seenInvalid[item]; !seen {
+				seenInvalid[item] = struct{}{}
+				batch.order = append(batch.order, batchEntry{index: len(batch.Errors)})
+				batch.Errors = append(batch.Errors, BatchItemError{Item: item, Error: batchErrorString(err)})
+			}
+			continue
+		}
+		if _, seen := seenValid[value]; !seen {
+			seenValid[value] = struct{}{}
+			batch.order = append(batch.order, batchEntry{valid: true, index: len(batch.Valid)})
+			batch.Valid = append(batch.Valid, value)
+		}
+	}
+
+	if len(batch.Errors) > 0 {
+		log.Warn().Str("param", paramName).Int("invalid", len(batch.Errors)).Int("total", len(items)).
+			Msg("Invalid items in batch request")
+	}
+	return batch, nil
+}
+
+// batchErrorString returns the reason of validation error without the
+// parameter name and value, which are reported as the item
+func batchErrorString(err error) string {
+	var parsingError *types.RouterParsingError
+	if errors.As(err, &parsingError) {
+		return parsingError.ErrString
+	}
+	return err.Error()
+}
+
+// ReadClusterBatchFromPath retrieves list of clusters from request's path
+// and validates it by ValidateClusterBatch. If the list can't be read or
+// it's too large, it writes http error to the writer and returns false.
+func ReadClusterBatchFromPath(writer http.ResponseWriter, request *http.Request, options BatchOptions) (ClusterBatch, bool) {
+	clusterList, ok := ReadClusterListFromPath(writer, request)
+	if !ok {
+		return ClusterBatch{}, false
+	}
+	batch, err := ValidateClusterBatch(clusterList, options)
+	if err != nil {
+		types.HandleServerError(writer, err)
+		return batch, false
+	}
+	return batch, true
+}
+
+// ReadClusterBatchFromBody retrieves list of clusters from request's body by
+// ReadJSONBody, so the body size and content type are checked, and validates
+// it by ValidateClusterBatch. If the list can't be read or it's too large,
+// it writes http error to the writer and returns false.
+func ReadClusterBatchFromBody(writer http.ResponseWriter, request *http.Request, options BatchOptions) (ClusterBatch, bool) {
+	clusterList, ok := ReadJSONBody[ctypes.ClusterListInRequest](writer, request, BodyOptions{})
+	if !ok {
+		return ClusterBatch{}, false
+	}
+	batch, err := ValidateClusterBatch(clusterList.Clusters, options)
+	if err != nil {
+		types.HandleServerError(writer, err)
+		return batch, false
+	}
+	return batch, true
+}
+
+// ReadOrgBatch retrieves list of organizations from request's path and
+// validates it by ValidateOrgBatch. If the list can't be read or it's too
+// large, it writes http error to the writer and returns false.
+func ReadOrgBatch(writer http.ResponseWriter, request *http.Request, options BatchOptions) (OrgBatch, bool) {
+	organizationsParam, err := GetRouterParam(request, "organizations")
+	if err != nil {
+		HandleOrgIDError(writer, err)
+		return OrgBatch{}, false
+	}
+	batch, err := ValidateOrgBatch(SplitRequestParamArray(organizationsParam), options)
+	if err != nil {
+		types.HandleServerError(writer, err)
+		return batch, false
+	}
+	return batch, true
+}
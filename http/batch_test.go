@@ -0,0 +1,172 @@
+// Copyright 2026 Red Hat, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httputils_test
+
+// Documentation in literate-programming-style is available at:
+// https://redhatinsights.github.io/insights-operator-utils/packages/http/batch_test.html
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	ctypes "github.com/RedHatInsights/insights-results-types"
+	"github.com/stretchr/testify/assert"
+
+	httputils "github.com/RedHatInsights/insights-operator-utils/http"
+	"github.com/RedHatInsights/insights-operator-utils/responses"
+	"github.com/RedHatInsights/insights-operator-utils/tests/helpers"
+)
+
+func TestValidateClusterBatch(t *testing.T) {
+	batch, err := httputils.ValidateClusterBatch(
+		[]string{cluster1ID, "not-a-uuid", cluster2ID, cluster1ID, "not-a-uuid", ""},
+		httputils.BatchOptions{},
+	)
+	helpers.FailOnError(t, err)
+
+	assert.Equal(t, []ctypes.ClusterName{cluster1ID, cluster2ID}, batch.Valid)
+	assert.Equal(t, []httputils.BatchItemError{
+		{Item: "not-a-uuid", Error: "invalid UUID length: 10"},
+		{Item: "", Error: "invalid UUID length: 0"},
+	}, batch.Errors)
+}
+
+func TestValidateClusterBatchNormalizes(t *testing.T) {
+	batch, err := httputils.ValidateClusterBatch(
+		[]string{strings.ToUpper(cluster1ID), "urn:uuid:" + cluster1ID, "{" + cluster1ID + "}", cluster1ID},
+		httputils.BatchOptions{},
+	)
+	helpers.FailOnError(t, err)
+
+	assert.Equal(t, []ctypes.ClusterName{cluster1ID}, batch.Valid)
+	assert.Empty(t, batch.Errors)
+}
+
+func TestValidateOrgBatch(t *testing.T) {
+	batch, err := httputils.ValidateOrgBatch([]string{"1", "2", "1", "-1", "4294967296"}, httputils.BatchOptions{})
+	helpers.FailOnError(t, err)
+
+	assert.Equal(t, []ctypes.OrgID{1, 2}, batch.Valid)
+	assert.Equal(t, []httputils.BatchItemError{
+		{Item: "-1", Error: "integer array expected"},
+		{Item: "4294967296", Error: "integer array expected"},
+	}, batch.Errors)
+}
+
+func TestValidateBatchMaxSize(t *testing.T) {
+	_, err := httputils.ValidateClusterBatch([]string{cluster1ID, cluster2ID}, httputils.BatchOptions{MaxSize: 1})
+	assert.EqualError(t, err, "Error during validating param 'clusters' with value '2'. "+
+		"Error: 'at most 1 items are allowed in one request'")
+
+	batch, err := httputils.ValidateOrgBatch([]string{}, httputils.BatchOptions{})
+	helpers.FailOnError(t, err)
+	assert.Empty(t, batch.Valid)
+	assert.Empty(t, batch.Errors)
+}
+
+func TestBatchResults(t *testing.T) {
+	batch, err := httputils.ValidateClusterBatch([]string{"x", cluster1ID, "y", cluster2ID}, httputils.BatchOptions{})
+	helpers.FailOnError(t, err)
+
+	// results are in the order of the request
+	results := batch.Results(map[ctypes.ClusterName]error{cluster2ID: errors.New("report not found")})
+	assert.Equal(t, []responses.BatchItemResult{
+		{Item: "x", Status: responses.BatchItemError, Error: "invalid UUID length: 1"},
+		{Item: cluster1ID, Status: responses.BatchItemOK},
+		{Item: "y", Status: responses.BatchItemError, Error: "invalid UUID length: 1"},
+		{Item: cluster2ID, Status: responses.BatchItemError, Error: "report not found"},
+	}, results)
+
+	// valid items go first in batches created directly
+	batch = httputils.ClusterBatch{
+		Valid:  []ctypes.ClusterName{cluster1ID},
+		Errors: []httputils.BatchItemError{{Item: "x", Error: "invalid"}},
+	}
+	assert.Equal(t, []responses.BatchItemResult{
+		{Item: cluster1ID, Status: responses.BatchItemOK},
+		{Item: "x", Status: responses.BatchItemError, Error: "invalid"},
+	}, batch.Results(nil))
+}
+
+func TestReadClusterBatchFromBody(t *testing.T) {
+	body := fmt.Sprintf(`{"clusters": ["%v", "%v", "bad"]}`, cluster1ID, cluster1ID)
+	request := httptest.NewRequest(http.MethodPost, "/clusters", strings.NewReader(body))
+
+	batch, ok := httputils.ReadClusterBatchFromBody(httptest.NewRecorder(), request, httputils.BatchOptions{})
+	assert.True(t, ok)
+	assert.Equal(t, []ctypes.ClusterName{cluster1ID}, batch.Valid)
+	assert.Len(t, batch.Errors, 1)
+
+	request = httptest.NewRequest(http.MethodPost, "/clusters", strings.NewReader(body))
+	recorder := httptest.NewRecorder()
+	_, ok = httputils.ReadClusterBatchFromBody(recorder, request, httputils.BatchOptions{MaxSize: 2})
+	assert.False(t, ok)
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+
+	request = httptest.NewRequest(http.MethodPost, "/clusters", strings.NewReader("{"))
+	_, ok = httputils.ReadClusterBatchFromBody(httptest.NewRecorder(), request, httputils.BatchOptions{})
+	assert.False(t, ok)
+
+	// body is read strictly
+	request = httptest.NewRequest(http.MethodPost, "/clusters", strings.NewReader(body))
+	request.Header.Set("Content-Type", "text/plain")
+	recorder = httptest.NewRecorder()
+	_, ok = httputils.ReadClusterBatchFromBody(recorder, request, httputils.BatchOptions{})
+	assert.False(t, ok)
+	assert.Equal(t, http.StatusUnsupportedMediaType, recorder.Code)
+
+	request = httptest.NewRequest(http.MethodPost, "/clusters", strings.NewReader(body+" {}"))
+	_, ok = httputils.ReadClusterBatchFromBody(httptest.NewRecorder(), request, httputils.BatchOptions{})
+	assert.False(t, ok)
+}
+
+func TestReadClusterBatchFromPath(t *testing.T) {
+	request := mustGetRequestWithMuxVars(t, http.MethodGet, "", nil, map[string]string{
+		"cluster_list": cluster1ID + ",bad," + cluster2ID,
+	})
+
+	batch, ok := httputils.ReadClusterBatchFromPath(httptest.NewRecorder(), request, httputils.BatchOptions{})
+	assert.True(t, ok)
+	assert.Equal(t, []ctypes.ClusterName{cluster1ID, cluster2ID}, batch.Valid)
+	assert.Equal(t, []httputils.BatchItemError{{Item: "bad", Error: "invalid UUID length: 3"}}, batch.Errors)
+
+	request = mustGetRequestWithMuxVars(t, http.MethodGet, "", nil, map[string]string{})
+	recorder := httptest.NewRecorder()
+	_, ok = httputils.ReadClusterBatchFromPath(recorder, request, httputils.BatchOptions{})
+	assert.False(t, ok)
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+}
+
+func TestReadOrgBatch(t *testing.T) {
+	request := mustGetRequestWithMuxVars(t, http.MethodGet, "", nil, map[string]string{"organizations": "1,x,2"})
+
+	batch, ok := httputils.ReadOrgBatch(httptest.NewRecorder(), request, httputils.BatchOptions{})
+	assert.True(t, ok)
+	assert.Equal(t, []ctypes.OrgID{1, 2}, batch.Valid)
+	assert.Equal(t, []httputils.BatchItemError{{Item: "x", Error: "integer array expected"}}, batch.Errors)
+
+	recorder := httptest.NewRecorder()
+	_, ok = httputils.ReadOrgBatch(recorder, request, httputils.BatchOptions{MaxSize: 2})
+	assert.False(t, ok)
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+
+	request = mustGetRequestWithMuxVars(t, http.MethodGet, "", nil, map[string]string{})
+	_, ok = httputils.ReadOrgBatch(httptest.NewRecorder(), request, httputils.BatchOptions{})
+	assert.False(t, ok)
+}
@@ -0,0 +1,394 @@
+// Copyright 2026 Red Hat, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httputils
+
+// Documentation in literate-programming-style is available at:
+// https://redhatinsights.github.io/insights-operator-utils/packages/http/binding.html
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+
+	"github.com/RedHatInsights/insights-operator-utils/types"
+)
+
+// Names of struct tags used by Bind
+const (
+	pathTag     = "path"
+	queryTag    = "query"
+	headerTag   = "header"
+	bodyTag     = "body"
+	validateTag = "validate"
+	defaultTag  = "default"
+)
+
+// BindingValidator checks one value of a bound field, the argument is the
+// part of the rule after "=" (e.g. "10" for "max=10")
+type BindingValidator func(value reflect.Value, argument string) error
+
+// bindingValidators contains validators usable in validate tag, "required"
+// is handled by Bind itself
+var bindingValidators = map[string]BindingValidator{
+	"positive":      validatePositive,
+	"min":           validateMin,
+	"max":           validateMax,
+	"oneof":         validateOneOf,
+	"cluster_name":  validateClusterName,
+	"rule_id":       validateRuleID,
+	"rule_selector": validateRuleSelector,
+}
+
+// RegisterBindingValidator registers validator usable in validate tag under
+// the given name. It is expected to be called during initialization.
+func RegisterBindingValidator(name string, validator BindingValidator) {
+	bindingValidators[name] = validator
+}
+
+// Bind fills in fields of the target structure from the request. Fields are
+// bound by tags:
+//
+//	path:"organization"   - value of gorilla/mux path variable
+//	query:"limit"         - value of query parameter
+//	header:"X-Request-Id" - value of header
+//	body:"json"           - the whole request body decoded as JSON
+//	default:"50"          - value used when the parameter is not provided
+//	validate:"required,positive" - comma separated validation rules
+//
+// Supported field types are strings, integers, booleans, floats, types
+// derived from them (e.g. ctypes.OrgID) and slices of them. Slices are read
+// from comma separated values or from repeated query parameters. Supported
+// rules are required, positive, min=N, max=N, oneof=a|b, cluster_name,
+// rule_id and rule_selector, more can be added by RegisterBindingValidator.
+//
+// All violations are returned in one types.ValidationErrors error. Other
+// errors mean that the target structure is not usable for binding.
+func Bind(request *http.Request, target interface{}) error {
+	targetValue := reflect.ValueOf(target)
+	if targetValue.Kind() != reflect.Pointer || targetValue.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("binding target must be a pointer to structure, got %T", target)
+	}
+
+	structValue := targetValue.Elem()
+	structType := structValue.Type()
+	violations := &types.ValidationErrors{}
+
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		var violation *types.ValidationError
+		var err error
+		if _, isBody := field.Tag.Lookup(bodyTag); isBody {
+			violation, err = bindBody(request, field, structValue.Field(i))
+		} else {
+			violation, err = bindParam(request, field, structValue.Field(i))
+		}
+		if err != nil {
+			return err
+		}
+
+		if violation != nil {
+			violations.Errors = append(violations.Errors, violation)
+		}
+	}
+
+	if len(violations.Errors) > 0 {
+		return violations
+	}
+	return nil
+}
+
+// ReadRequestParams binds the request to the target by Bind
+// if it's not possible, it writes http error to the writer and returns false
+func ReadRequestParams(writer http.ResponseWriter, request *http.Request, target interface{}) bool {
+	if err := Bind(request, target); err != nil {
+		types.HandleServerError(writer, err)
+		return false
+	}
+	return true
+}
+
+// bindParam binds field from path, query or header
+func bindParam(
+	request *http.Request, field reflect.StructField, fieldValue reflect.Value,
+) (*types.ValidationError, error) {
+	isSlice := fieldValue.Kind() == reflect.Slice
+	name, rawValues := lookupParam(request, field, isSlice)
+	if name == "" {
+		return nil, nil
+	}
+
+	rules := strings.Split(field.Tag.Get(validateTag), ",")
+	if len(rawValues) == 0 {
+		if defaultValue, found := field.Tag.Lookup(defaultTag); found {
+			rawValues = splitParam(defaultValue, isSlice)
+		} else if slices.Contains(rules, "required") {
+			return &types.ValidationError{ParamName: name, ErrString: "value is required"}, nil
+		} else {
+			return nil, nil
+		}
+	}
+
+	if !isSlice && len(rawValues) > 1 {
+		return &types.ValidationError{
+			ParamName: name, ParamValue: strings.Join(rawValues, ","), ErrString: "single value expected",
+		}, nil
+	}
+
+	paramValue := interface{}(rawValues[0])
+	if isSlice {
+		paramValue = rawValues
+	}
+
+	if err := setField(fieldValue, rawValues); err != nil {
+		return &types.ValidationError{ParamName: name, ParamValue: paramValue, ErrString: err.Error()}, nil
+	}
+
+	for _, rule := range rules {
+		ruleName, argument, _ := strings.Cut(rule, "=")
+		if ruleName == "" || ruleName == "required" {
+			continue
+		}
+		validator, found := bindingValidators[ruleName]
+		if !found {
+			return nil, fmt.Errorf("unknown validation rule '%s' of field %s", ruleName, field.Name)
+		}
+		if err := validateField(fieldValue, validator, argument); err != nil {
+			return &types.ValidationError{ParamName: name, ParamValue: paramValue, ErrString: err.Error()}, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// lookupParam returns name of the parameter and its values, the name is
+// empty when the field is not bound. Comma separated values are split only
+// for slice fields, values of other fields can contain commas.
+func lookupParam(request *http.Request, field reflect.StructField, split bool) (string, []string) {
+	if name, found := field.Tag.Lookup(pathTag); found {
+		value, found := mux.Vars(request)[name]
+		if !found || value == "" {
+			return name, nil
+		}
+		return name, splitParam(value, split)
+	}
+
+	if name, found := field.Tag.Lookup(queryTag); found {
+		values := request.URL.Query()[name]
+		if len(values) == 1 {
+			if values[0] == "" {
+				return name, nil
+			}
+			values = splitParam(values[0], split)
+		}
+		return name, values
+	}
+
+	if name, found := field.Tag.Lookup(headerTag); found {
+		value := request.Header.Get(name)
+		if value == "" {
+			return name, nil
+		}
+		return name, []string{value}
+	}
+
+	return "", nil
+}
+
+func splitParam(value string, split bool) []string {
+	if split {
+		return SplitRequestParamArray(value)
+	}
+	return []string{value}
+}
+
+// bindBody decodes JSON body into the field, problems with the JSON itself
+// are reported as violations, other errors (size, media type) are returned
+func bindBody(
+	request *http.Request, field reflect.StructField, fieldValue reflect.Value,
+) (*types.ValidationError, error) {
+	err := DecodeJSONBody(nil, request, fieldValue.Addr().Interface(), BodyOptions{})
+
+	var noBodyErr *types.NoBodyError
+	var invalidJSONErr *types.InvalidJSONError
+	switch {
+	case err == nil:
+		return nil, nil
+	case errors.As(err, &noBodyErr):
+		if slices.Contains(strings.Split(field.Tag.Get(validateTag), ","), "required") {
+			return &types.ValidationError{ParamName: bodyTag, ErrString: "body is required"}, nil
+		}
+		return nil, nil
+	case errors.As(err, &invalidJSONErr):
+		return &types.ValidationError{ParamName: bodyTag, ErrString: invalidJSONErr.Error()}, nil
+	default:
+		return nil, err
+	}
+}
+
+func setField(fieldValue reflect.Value, rawValues []string) error {
+	if fieldValue.Kind() != reflect.Slice {
+		return setValue(fieldValue, strings.TrimSpace(rawValues[0]))
+	}
+
+	slice := reflect.MakeSlice(fieldValue.Type(), len(rawValues), len(rawValues))
+	for i, raw := range rawValues {
+		if err := setValue(slice.Index(i), strings.TrimSpace(raw)); err != nil {
+			return err
+		}
+	}
+	fieldValue.Set(slice)
+	return nil
+}
+
+func setValue(value reflect.Value, raw string) error {
+	switch value.Kind() {
+	case reflect.String:
+		value.SetString(raw)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		parsed, err := strconv.ParseInt(raw, 10, value.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("integer expected")
+		}
+		value.SetInt(parsed)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		parsed, err := strconv.ParseUint(raw, 10, value.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("unsigned integer expected")
+		}
+		value.SetUint(parsed)
+	case reflect.Float32, reflect.Float64:
+		parsed, err := strconv.ParseFloat(raw, value.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("number expected")
+		}
+		value.SetFloat(parsed)
+	case reflect.Bool:
+		parsed, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("boolean expected")
+		}
+		value.SetBool(parsed)
+	default:
+		return fmt.Errorf("unsupported type %s", value.Type())
+	}
+	return nil
+}
+
+// validateField runs the validator for the value or for all items of slice
+func validateField(fieldValue reflect.Value, validator BindingValidator, argument string) error {
+	if fieldValue.Kind() != reflect.Slice {
+		return validator(fieldValue, argument)
+	}
+	for i := 0; i < fieldValue.Len(); i++ {
+		if err := validator(fieldValue.Index(i), argument); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// numericValue converts numeric value to float64
+func numericValue(value reflect.Value) (float64, error) {
+	switch {
+	case value.CanInt():
+		return float64(value.Int()), nil
+	case value.CanUint():
+		return float64(value.Uint()), nil
+	case value.CanFloat():
+		return value.Float(), nil
+	default:
+		return 0, fmt.Errorf("numeric value expected")
+	}
+}
+
+func validatePositive(value reflect.Value, _ string) error {
+	number, err := numericValue(value)
+	if err != nil {
+		return err
+	}
+	if number <= 0 {
+		return fmt.Errorf("positive value expected")
+	}
+	return nil
+}
+
+func validateMin(value reflect.Value, argument string) error {
+	number, err := numericValue(value)
+	if err != nil {
+		return err
+	}
+	minimum, err := strconv.ParseFloat(argument, 64)
+	if err != nil {
+		return fmt.Errorf("invalid min rule '%s'", argument)
+	}
+	if number < minimum {
+		return fmt.Errorf("value must be at least %s", argument)
+	}
+	return nil
+}
+
+func validateMax(value reflect.Value, argument string) error {
+	number, err := numericValue(value)
+	if err != nil {
+		return err
+	}
+	maximum, err := strconv.ParseFloat(argument, 64)
+	if err != nil {
+		return fmt.Errorf("invalid max rule '%s'", argument)
+	}
+	if number > maximum {
+		return fmt.Errorf("value must be at most %s", argument)
+	}
+	return nil
+}
+
+func validateOneOf(value reflect.Value, argument string) error {
+	allowed := strings.Split(argument, "|")
+	if !slices.Contains(allowed, fmt.Sprint(value.Interface())) {
+		return fmt.Errorf("value must be one of %s", strings.Join(allowed, ", "))
+	}
+	return nil
+}
+
+func validateClusterName(value reflect.Value, _ string) error {
+	if _, err := ValidateClusterName(value.String()); err != nil {
+		return fmt.Errorf("invalid cluster name: %s", err.(*types.RouterParsingError).ErrString)
+	}
+	return nil
+}
+
+func validateRuleID(value reflect.Value, _ string) error {
+	if !RuleIDValidator.MatchString(value.String()) {
+		return fmt.Errorf("invalid rule ID, it must contain only from latin characters, number, underscores or dots")
+	}
+	return nil
+}
+
+func validateRuleSelector(value reflect.Value, _ string) error {
+	if !RuleSelectorValidator.MatchString(value.String()) {
+		return fmt.Errorf("invalid rule selector, it must be in the format 'rule.module|ERROR_KEY'")
+	}
+	return nil
+}
@@ -0,0 +1,170 @@
+// Copyright 2026 Red Hat, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httputils_test
+
+// Documentation in literate-programming-style is available at:
+// https://redhatinsights.github.io/insights-operator-utils/packages/http/binding_test.html
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	ctypes "github.com/RedHatInsights/insights-results-types"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+
+	httputils "github.com/RedHatInsights/insights-operator-utils/http"
+	"github.com/RedHatInsights/insights-operator-utils/tests/helpers"
+	"github.com/RedHatInsights/insights-operator-utils/types"
+)
+
+type reportRequest struct {
+	OrgID     ctypes.OrgID       `path:"organization" validate:"positive"`
+	Cluster   ctypes.ClusterName `path:"cluster" validate:"required,cluster_name"`
+	Limit     int                `query:"limit" default:"50" validate:"min=1,max=100"`
+	Sort      string             `query:"sort" default:"asc" validate:"oneof=asc|desc"`
+	Rules     []string           `query:"rule" validate:"rule_selector"`
+	Verbose   bool               `query:"verbose"`
+	RequestID string             `header:"X-Request-Id"`
+	Body      *reportRequestBody `body:"json"`
+}
+
+type reportRequestBody struct {
+	Comment string `json:"comment"`
+}
+
+const validClusterName = "d2fe1c5d-6b3e-4d53-8a7c-0a8b1c3d2f5e"
+
+func bindRequest(t *testing.T, target, body string, vars map[string]string, dest interface{}) error {
+	var request *http.Request
+	if body == "" {
+		request = httptest.NewRequest(http.MethodPost, target, http.NoBody)
+	} else {
+		request = httptest.NewRequest(http.MethodPost, target, strings.NewReader(body))
+	}
+	request.Header.Set(httputils.RequestIDHeader, "req-1")
+	return httputils.Bind(mux.SetURLVars(request, vars), dest)
+}
+
+func TestBind(t *testing.T) {
+	var params reportRequest
+	err := bindRequest(t, "/report?limit=10&sort=desc&rule=ccx.rule|KEY&rule=ccx.other|KEY2&verbose=true",
+		`{"comment": "hello"}`,
+		map[string]string{"organization": "42", "cluster": validClusterName}, &params)
+	helpers.FailOnError(t, err)
+
+	assert.Equal(t, ctypes.OrgID(42), params.OrgID)
+	assert.Equal(t, ctypes.ClusterName(validClusterName), params.Cluster)
+	assert.Equal(t, 10, params.Limit)
+	assert.Equal(t, "desc", params.Sort)
+	assert.Equal(t, []string{"ccx.rule|KEY", "ccx.other|KEY2"}, params.Rules)
+	assert.True(t, params.Verbose)
+	assert.Equal(t, "req-1", params.RequestID)
+	assert.Equal(t, "hello", params.Body.Comment)
+}
+
+func TestBindDefaultsAndCommaSeparatedValues(t *testing.T) {
+	var params reportRequest
+	err := bindRequest(t, "/report?rule=ccx.rule|KEY,ccx.other|KEY2", "",
+		map[string]string{"organization": "42", "cluster": validClusterName}, &params)
+	helpers.FailOnError(t, err)
+
+	assert.Equal(t, 50, params.Limit)
+	assert.Equal(t, "asc", params.Sort)
+	assert.Equal(t, []string{"ccx.rule|KEY", "ccx.other|KEY2"}, params.Rules)
+	assert.Nil(t, params.Body)
+}
+
+func TestBindScalarWithComma(t *testing.T) {
+	var params struct {
+		Sort    string   `query:"sort"`
+		Columns string   `query:"columns" default:"name,last_checked"`
+		Fields  []string `query:"field" default:"a,b"`
+	}
+	err := bindRequest(t, "/report?sort=-last_checked,name", "", nil, &params)
+	helpers.FailOnError(t, err)
+
+	assert.Equal(t, "-last_checked,name", params.Sort)
+	assert.Equal(t, "name,last_checked", params.Columns)
+	assert.Equal(t, []string{"a", "b"}, params.Fields)
+
+	// repeated parameter is still refused for scalar field
+	err = bindRequest(t, "/report?sort=name&sort=age", "", nil, &params)
+	var validationErrs *types.ValidationErrors
+	assert.True(t, errors.As(err, &validationErrs))
+	assert.Equal(t, "single value expected", validationErrs.Errors[0].ErrString)
+}
+
+func TestBindAggregatesViolations(t *testing.T) {
+	var params reportRequest
+	err := bindRequest(t, "/report?limit=1000&sort=up&rule=wrong&verbose=maybe", `{"comment":`,
+		map[string]string{"organization": "0"}, &params)
+
+	var validationErrs *types.ValidationErrors
+	assert.True(t, errors.As(err, &validationErrs))
+
+	reasons := map[string]string{}
+	for _, violation := range validationErrs.Errors {
+		reasons[violation.ParamName] = violation.ErrString
+	}
+	assert.Equal(t, map[string]string{
+		"organization": "positive value expected",
+		"cluster":      "value is required",
+		"limit":        "value must be at most 100",
+		"sort":         "value must be one of asc, desc",
+		"rule":         "invalid rule selector, it must be in the format 'rule.module|ERROR_KEY'",
+		"verbose":      "boolean expected",
+		"body":         "invalid JSON in request body: body is truncated",
+	}, reasons)
+}
+
+func TestBindInvalidClusterName(t *testing.T) {
+	var params reportRequest
+	err := bindRequest(t, "/report", "",
+		map[string]string{"organization": "42", "cluster": "not-a-uuid"}, &params)
+
+	var validationErrs *types.ValidationErrors
+	assert.True(t, errors.As(err, &validationErrs))
+	assert.Len(t, validationErrs.Errors, 1)
+	assert.Equal(t, "cluster", validationErrs.Errors[0].ParamName)
+	assert.Equal(t, "not-a-uuid", validationErrs.Errors[0].ParamValue)
+}
+
+func TestBindInvalidTarget(t *testing.T) {
+	var params reportRequest
+	err := bindRequest(t, "/report", "", nil, params)
+	assert.EqualError(t, err, "binding target must be a pointer to structure, got httputils_test.reportRequest")
+
+	var unknownRule struct {
+		Name string `query:"name" default:"x" validate:"unknown"`
+	}
+	err = bindRequest(t, "/report", "", nil, &unknownRule)
+	assert.EqualError(t, err, "unknown validation rule 'unknown' of field Name")
+}
+
+func TestReadRequestParams(t *testing.T) {
+	var params reportRequest
+	recorder := httptest.NewRecorder()
+	request := mux.SetURLVars(httptest.NewRequest(http.MethodGet, "/report?limit=0", http.NoBody),
+		map[string]string{"organization": "42", "cluster": validClusterName})
+
+	ok := httputils.ReadRequestParams(recorder, request, &params)
+	assert.False(t, ok)
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "value must be at least 1")
+}
@@ -0,0 +1,173 @@
+// Copyright 2026 Red Hat, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httputils
+
+// Documentation in literate-programming-style is available at:
+// https://redhatinsights.github.io/insights-operator-utils/packages/http/body.html
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/RedHatInsights/insights-operator-utils/types"
+)
+
+const (
+	// DefaultMaxBodySize is the maximum size of request body used when
+	// BodyOptions.MaxSize is not set
+	DefaultMaxBodySize int64 = 1 << 20
+
+	// JSONContentType is the media type accepted by default
+	JSONContentType = "application/json"
+
+	contentTypeHeader = "Content-Type"
+)
+
+// BodyOptions configures decoding of JSON request body
+type BodyOptions struct {
+	// MaxSize is the maximum size of body in bytes, DefaultMaxBodySize is
+	// used when not set
+	MaxSize int64
+	// ContentTypes lists accepted media types, application/json and any
+	// type with +json suffix are accepted when empty
+	ContentTypes []string
+	// RequireContentType rejects requests without Content-Type header,
+	// otherwise such requests are decoded as JSON
+	RequireContentType bool
+	// DisallowUnknownFields rejects bodies with fields not present in the
+	// target structure
+	DisallowUnknownFields bool
+}
+
+// DecodeJSONBody decodes JSON request body into the target. The body is
+// limited by http.MaxBytesReader and must contain exactly one JSON value.
+// Returned errors are types.NoBodyError, types.InvalidJSONError,
+// types.PayloadTooLargeError and types.UnsupportedMediaTypeError, so they
+// can be passed directly to types.HandleServerError.
+func DecodeJSONBody(writer http.ResponseWriter, request *http.Request, target interface{}, options BodyOptions) error {
+	if err := checkContentType(request, options); err != nil {
+		return err
+	}
+
+	if request.Body == nil || request.Body == http.NoBody {
+		return &types.NoBodyError{}
+	}
+
+	maxSize := options.MaxSize
+	if maxSize <= 0 {
+		maxSize = DefaultMaxBodySize
+	}
+	if request.ContentLength > maxSize {
+		return &types.PayloadTooLargeError{Limit: maxSize}
+	}
+
+	// chunked bodies have unknown length, so the limit is enforced by reader
+	request.Body = http.MaxBytesReader(writer, request.Body, maxSize)
+
+	decoder := json.NewDecoder(request.Body)
+	if options.DisallowUnknownFields {
+		decoder.DisallowUnknownFields()
+	}
+
+	if err := decoder.Decode(target); err != nil {
+		return translateDecodeError(err, maxSize)
+	}
+
+	// anything except whitespace after the JSON value is an error
+	if _, err := decoder.Token(); !errors.Is(err, io.EOF) {
+		if err != nil {
+			return translateDecodeError(err, maxSize)
+		}
+		return &types.InvalidJSONError{
+			Offset:    decoder.InputOffset(),
+			ErrString: "body must contain single JSON value",
+		}
+	}
+
+	return nil
+}
+
+// ReadJSONBody decodes JSON request body into new value of type T
+// if it's not possible, it writes http error to the writer and returns false
+func ReadJSONBody[T any](writer http.ResponseWriter, request *http.Request, options BodyOptions) (T, bool) {
+	var value T
+	if err := DecodeJSONBody(writer, request, &value, options); err != nil {
+		types.HandleServerError(writer, err)
+		return value, false
+	}
+	return value, true
+}
+
+func checkContentType(request *http.Request, options BodyOptions) error {
+	supported := options.ContentTypes
+	if len(supported) == 0 {
+		supported = []string{JSONContentType}
+	}
+
+	header := request.Header.Get(contentTypeHeader)
+	if header == "" {
+		if options.RequireContentType {
+			return &types.UnsupportedMediaTypeError{Supported: supported}
+		}
+		return nil
+	}
+
+	mediaType, _, err := mime.ParseMediaType(header)
+	if err != nil {
+		return &types.UnsupportedMediaTypeError{ContentType: header, Supported: supported}
+	}
+
+	if slices.Contains(supported, mediaType) {
+		return nil
+	}
+	if len(options.ContentTypes) == 0 && strings.HasSuffix(mediaType, "+json") {
+		return nil
+	}
+	return &types.UnsupportedMediaTypeError{ContentType: mediaType, Supported: supported}
+}
+
+func translateDecodeError(err error, maxSize int64) error {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	var maxBytesErr *http.MaxBytesError
+
+	switch {
+	case errors.Is(err, io.EOF):
+		return &types.NoBodyError{}
+	case errors.As(err, &maxBytesErr):
+		return &types.PayloadTooLargeError{Limit: maxSize}
+	case errors.As(err, &syntaxErr):
+		return &types.InvalidJSONError{Offset: syntaxErr.Offset, ErrString: syntaxErr.Error()}
+	case errors.As(err, &typeErr):
+		return &types.InvalidJSONError{
+			Offset:    typeErr.Offset,
+			Field:     typeErr.Field,
+			ErrString: "value of type " + typeErr.Value + " is not allowed",
+		}
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		return &types.InvalidJSONError{ErrString: "body is truncated"}
+	}
+
+	// json package does not export error for unknown fields
+	if field, found := strings.CutPrefix(err.Error(), "json: unknown field "); found {
+		return &types.InvalidJSONError{Field: strings.Trim(field, `"`), ErrString: "unknown field"}
+	}
+	return &types.InvalidJSONError{ErrString: err.Error()}
+}
@@ -0,0 +1,165 @@
+// Copyright 2026 Red Hat, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httputils_test
+
+// Documentation in literate-programming-style is available at:
+// https://redhatinsights.github.io/insights-operator-utils/packages/http/body_test.html
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	httputils "github.com/RedHatInsights/insights-operator-utils/http"
+	"github.com/RedHatInsights/insights-operator-utils/tests/helpers"
+	"github.com/RedHatInsights/insights-operator-utils/types"
+)
+
+type acknowledgeRequest struct {
+	RuleID  string `json:"rule_id"`
+	Justify string `json:"justification"`
+	Count   int    `json:"count"`
+}
+
+func makeBodyRequest(body, contentType string) *http.Request {
+	request := httptest.NewRequest(http.MethodPost, "/ack", strings.NewReader(body))
+	if contentType != "" {
+		request.Header.Set("Content-Type", contentType)
+	}
+	return request
+}
+
+func decodeBody(request *http.Request, options httputils.BodyOptions) (acknowledgeRequest, error) {
+	var target acknowledgeRequest
+	err := httputils.DecodeJSONBody(httptest.NewRecorder(), request, &target, options)
+	return target, err
+}
+
+func TestDecodeJSONBody(t *testing.T) {
+	for _, contentType := range []string{"", "application/json", "application/json; charset=utf-8", "application/merge-patch+json"} {
+		target, err := decodeBody(makeBodyRequest(`{"rule_id": "rule.id", "count": 2}`, contentType), httputils.BodyOptions{})
+		helpers.FailOnError(t, err)
+		assert.Equal(t, acknowledgeRequest{RuleID: "rule.id", Count: 2}, target, contentType)
+	}
+}
+
+func TestDecodeJSONBodyErrors(t *testing.T) {
+	testCases := []struct {
+		name     string
+		body     string
+		options  httputils.BodyOptions
+		expected error
+	}{
+		{"empty", "", httputils.BodyOptions{}, &types.NoBodyError{}},
+		{"syntax", `{"rule_id": }`, httputils.BodyOptions{}, &types.InvalidJSONError{
+			Offset: 13, ErrString: "invalid character '}' looking for beginning of value",
+		}},
+		{"truncated", `{"rule_id": "x"`, httputils.BodyOptions{}, &types.InvalidJSONError{
+			ErrString: "body is truncated",
+		}},
+		{"type", `{"count": "2"}`, httputils.BodyOptions{}, &types.InvalidJSONError{
+			Offset: 13, Field: "count", ErrString: "value of type string is not allowed",
+		}},
+		{"unknown field", `{"rule": "x"}`, httputils.BodyOptions{DisallowUnknownFields: true}, &types.InvalidJSONError{
+			Field: "rule", ErrString: "unknown field",
+		}},
+		{"multiple values", `{} {}`, httputils.BodyOptions{}, &types.InvalidJSONError{
+			Offset: 4, ErrString: "body must contain single JSON value",
+		}},
+		{"too large", `{"justification": "` + strings.Repeat("x", 100) + `"}`, httputils.BodyOptions{MaxSize: 64},
+			&types.PayloadTooLargeError{Limit: 64}},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			_, err := decodeBody(makeBodyRequest(testCase.body, ""), testCase.options)
+			assert.Equal(t, testCase.expected, err)
+		})
+	}
+}
+
+func TestDecodeJSONBodyUnknownFieldsAllowedByDefault(t *testing.T) {
+	target, err := decodeBody(makeBodyRequest(`{"rule_id": "x", "other": 1}`, ""), httputils.BodyOptions{})
+	helpers.FailOnError(t, err)
+	assert.Equal(t, "x", target.RuleID)
+}
+
+// chunkedReader hides the length of the body, like chunked transfer encoding
+type chunkedReader struct {
+	io.Reader
+}
+
+func TestDecodeJSONBodyChunked(t *testing.T) {
+	body := `{"rule_id": "x"}`
+	request := httptest.NewRequest(http.MethodPost, "/ack", chunkedReader{strings.NewReader(body)})
+	request.ContentLength = -1
+
+	target, err := decodeBody(request, httputils.BodyOptions{})
+	helpers.FailOnError(t, err)
+	assert.Equal(t, "x", target.RuleID)
+
+	request = httptest.NewRequest(http.MethodPost, "/ack",
+		chunkedReader{strings.NewReader(`{"justification": "` + strings.Repeat("x", 100) + `"}`)})
+	request.ContentLength = -1
+
+	_, err = decodeBody(request, httputils.BodyOptions{MaxSize: 64})
+	var tooLargeErr *types.PayloadTooLargeError
+	assert.True(t, errors.As(err, &tooLargeErr))
+}
+
+func TestDecodeJSONBodyContentType(t *testing.T) {
+	_, err := decodeBody(makeBodyRequest(`{}`, "text/plain"), httputils.BodyOptions{})
+	assert.Equal(t, &types.UnsupportedMediaTypeError{
+		ContentType: "text/plain", Supported: []string{httputils.JSONContentType},
+	}, err)
+
+	_, err = decodeBody(makeBodyRequest(`{}`, ""), httputils.BodyOptions{RequireContentType: true})
+	assert.Equal(t, &types.UnsupportedMediaTypeError{Supported: []string{httputils.JSONContentType}}, err)
+
+	// +json suffix is accepted only when the list is not configured
+	_, err = decodeBody(makeBodyRequest(`{}`, "application/merge-patch+json"),
+		httputils.BodyOptions{ContentTypes: []string{"application/vnd.api+json"}})
+	assert.Error(t, err)
+}
+
+func TestReadJSONBody(t *testing.T) {
+	testCases := []struct {
+		body           string
+		contentType    string
+		expectedStatus int
+	}{
+		{`{"rule_id": "x"}`, "application/json", http.StatusOK},
+		{`{"rule_id": `, "application/json", http.StatusBadRequest},
+		{strings.Repeat(" ", 100) + "{}", "application/json", http.StatusRequestEntityTooLarge},
+		{`{}`, "application/xml", http.StatusUnsupportedMediaType},
+	}
+
+	for _, testCase := range testCases {
+		recorder := httptest.NewRecorder()
+		target, ok := httputils.ReadJSONBody[acknowledgeRequest](recorder, makeBodyRequest(testCase.body, testCase.contentType),
+			httputils.BodyOptions{MaxSize: 64})
+
+		assert.Equal(t, testCase.expectedStatus == http.StatusOK, ok, testCase.body)
+		assert.Equal(t, testCase.expectedStatus, recorder.Code, testCase.body)
+		if ok {
+			assert.Equal(t, "x", target.RuleID)
+		}
+	}
+}
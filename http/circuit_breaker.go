@@ -0,0 +1,257 @@
+// Copyright 2026 Red Hat, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httputils
+
+// Documentation in literate-programming-style is available at:
+// https://redhatinsights.github.io/insights-operator-utils/packages/http/circuit_breaker.html
+
+import (
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/RedHatInsights/insights-operator-utils/metrics"
+	"github.com/RedHatInsights/insights-operator-utils/types"
+)
+
+// Default values used for unset fields of CircuitBreakerConfiguration
+const (
+	DefaultFailureRateThreshold = 0.5
+	DefaultMinRequests          = 10
+	DefaultFailureWindow        = 30 * time.Second
+	DefaultCoolDown             = 10 * time.Second
+	DefaultHalfOpenRequests     = 1
+)
+
+// CircuitState is a state of circuit breaker
+type CircuitState int
+
+const (
+	// CircuitClosed lets all requests through
+	CircuitClosed CircuitState = iota
+	// CircuitHalfOpen lets a limited number of trial requests through
+	CircuitHalfOpen
+	// CircuitOpen refuses all requests
+	CircuitOpen
+)
+
+func (state CircuitState) String() string {
+	switch state {
+	case CircuitClosed:
+		return "closed"
+	case CircuitHalfOpen:
+		return "half-open"
+	case CircuitOpen:
+		return "open"
+	default:
+		return "unknown"
+	}
+}
+
+// CircuitGeneration identifies the period between two state changes of the
+// breaker. It's returned by Allow and passed to Record, so results of
+// requests allowed before the state changed are ignored.
+type CircuitGeneration uint64
+
+// CircuitBreakerConfiguration represents configuration of CircuitBreaker
+type CircuitBreakerConfiguration struct {
+	// FailureRateThreshold is the ratio of failed requests (0-1) that
+	// opens the breaker
+	FailureRateThreshold float64 `mapstructure:"failure_rate_threshold" toml:"failure_rate_threshold"`
+	// MinRequests is the number of requests in the window needed to
+	// evaluate the failure rate
+	MinRequests int `mapstructure:"min_requests" toml:"min_requests"`
+	// FailureWindow is the length of windows in which the requests are
+	// counted
+	FailureWindow time.Duration `mapstructure:"failure_window" toml:"failure_window"`
+	// CoolDown is the time the breaker stays open before it lets trial
+	// requests through
+	CoolDown time.Duration `mapstructure:"cool_down" toml:"cool_down"`
+	// HalfOpenRequests is the number of trial requests that need to succeed
+	// to close the breaker
+	HalfOpenRequests int `mapstructure:"half_open_requests" toml:"half_open_requests"`
+}
+
+// CircuitBreaker stops calls to a failing target for a while, so the callers
+// fail fast instead of waiting for timeouts. Requests are counted in
+// consecutive windows and the breaker opens when the ratio of failed
+// requests in a window reaches the threshold. After the cool-down period
+// the breaker is half-open and lets the trial requests through. It closes
+// when all of them succeed and opens again when any of them fails.
+type CircuitBreaker struct {
+	target        string
+	configuration CircuitBreakerConfiguration
+
+	mutex            sync.Mutex
+	state            CircuitState
+	generation       CircuitGeneration
+	windowStart      time.Time
+	requests         int
+	failures         int
+	openedAt         time.Time
+	halfOpenInFlight int
+	halfOpenSuccess  int
+}
+
+// NewCircuitBreaker creates a closed circuit breaker for the given target.
+// Unset fields of the configuration are replaced by defaults.
+func NewCircuitBreaker(target string, configuration CircuitBreakerConfiguration) *CircuitBreaker {
+	if configuration.FailureRateThreshold <= 0 || configuration.FailureRateThreshold > 1 {
+		configuration.FailureRateThreshold = DefaultFailureRateThreshold
+	}
+	if configuration.MinRequests <= 0 {
+		configuration.MinRequests = DefaultMinRequests
+	}
+	if configuration.FailureWindow <= 0 {
+		configuration.FailureWindow = DefaultFailureWindow
+	}
+	if configuration.CoolDown <= 0 {
+		configuration.CoolDown = DefaultCoolDown
+	}
+	if configuration.HalfOpenRequests <= 0 {
+		configuration.HalfOpenRequests = DefaultHalfOpenRequests
+	}
+
+	breaker := &CircuitBreaker{
+		target:        target,
+		configuration: configuration,
+		windowStart:   time.Now(),
+	}
+	breaker.setState(CircuitClosed)
+	return breaker
+}
+
+// State returns the current state of the breaker
+func (breaker *CircuitBreaker) State() CircuitState {
+	breaker.mutex.Lock()
+	defer breaker.mutex.Unlock()
+
+	breaker.checkCoolDown()
+	return breaker.state
+}
+
+// Allow checks whether a request can be sent. CircuitOpenError is returned
+// when the breaker is open or when all trial requests of half-open breaker
+// are in flight. Result of every allowed request needs to be recorded by
+// Record, or the request released by Cancel, together with the returned
+// generation.
+func (breaker *CircuitBreaker) Allow() (CircuitGeneration, error) {
+	breaker.mutex.Lock()
+	defer breaker.mutex.Unlock()
+
+	breaker.checkCoolDown()
+
+	switch breaker.state {
+	case CircuitOpen:
+		return breaker.generation, &types.CircuitOpenError{
+			Target:     breaker.target,
+			RetryAfter: time.Until(breaker.openedAt.Add(breaker.configuration.CoolDown)),
+		}
+	case CircuitHalfOpen:
+		if breaker.halfOpenInFlight+breaker.halfOpenSuccess >= breaker.configuration.HalfOpenRequests {
+			return breaker.generation, &types.CircuitOpenError{Target: breaker.target}
+		}
+		breaker.halfOpenInFlight++
+	}
+	return breaker.generation, nil
+}
+
+// Record records result of a request allowed by Allow. Results of requests
+// allowed in a previous generation, i.e. before the state of the breaker
+// changed, are ignored.
+func (breaker *CircuitBreaker) Record(generation CircuitGeneration, success bool) {
+	breaker.mutex.Lock()
+	defer breaker.mutex.Unlock()
+
+	if generation != breaker.generation {
+		return
+	}
+
+	switch breaker.state {
+	case CircuitHalfOpen:
+		breaker.halfOpenInFlight--
+		if !success {
+			breaker.open()
+			return
+		}
+		breaker.halfOpenSuccess++
+		if breaker.halfOpenSuccess >= breaker.configuration.HalfOpenRequests {
+			breaker.close()
+		}
+	case CircuitClosed:
+		if time.Since(breaker.windowStart) > breaker.configuration.FailureWindow {
+			breaker.resetWindow()
+		}
+		breaker.requests++
+		if !success {
+			breaker.failures++
+		}
+		if breaker.requests >= breaker.configuration.MinRequests &&
+			float64(breaker.failures)/float64(breaker.requests) >= breaker.configuration.FailureRateThreshold {
+			breaker.open()
+		}
+	}
+}
+
+// Cancel releases a request allowed by Allow without recording its result,
+// e.g. when the request was canceled by the caller and the target didn't
+// answer. Trial request of half-open breaker can be sent again.
+func (breaker *CircuitBreaker) Cancel(generation CircuitGeneration) {
+	breaker.mutex.Lock()
+	defer breaker.mutex.Unlock()
+
+	if generation == breaker.generation && breaker.state == CircuitHalfOpen {
+		breaker.halfOpenInFlight--
+	}
+}
+
+// checkCoolDown switches open breaker to half-open after the cool-down
+// period
+func (breaker *CircuitBreaker) checkCoolDown() {
+	if breaker.state == CircuitOpen && time.Since(breaker.openedAt) >= breaker.configuration.CoolDown {
+		breaker.halfOpenInFlight = 0
+		breaker.halfOpenSuccess = 0
+		breaker.setState(CircuitHalfOpen)
+	}
+}
+
+func (breaker *CircuitBreaker) open() {
+	log.Warn().
+		Str("target", breaker.target).
+		Int("requests", breaker.requests).
+		Int("failures", breaker.failures).
+		Msg("Circuit breaker opened")
+	breaker.openedAt = time.Now()
+	breaker.setState(CircuitOpen)
+}
+
+func (breaker *CircuitBreaker) close() {
+	log.Info().Str("target", breaker.target).Msg("Circuit breaker closed")
+	breaker.resetWindow()
+	breaker.setState(CircuitClosed)
+}
+
+func (breaker *CircuitBreaker) resetWindow() {
+	breaker.windowStart = time.Now()
+	breaker.requests = 0
+	breaker.failures = 0
+}
+
+func (breaker *CircuitBreaker) setState(state CircuitState) {
+	breaker.state = state
+	breaker.generation++
+	metrics.HTTPClientCircuitBreakerState.WithLabelValues(breaker.target).Set(float64(state))
+}
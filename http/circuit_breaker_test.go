@@ -0,0 +1,265 @@
+// Copyright 2026 Red Hat, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httputils_test
+
+// Documentation in literate-programming-style is available at:
+// https://redhatinsights.github.io/insights-operator-utils/packages/http/circuit_breaker_test.html
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+
+	httputils "github.com/RedHatInsights/insights-operator-utils/http"
+	"github.com/RedHatInsights/insights-operator-utils/metrics"
+	"github.com/RedHatInsights/insights-operator-utils/tests/helpers"
+	"github.com/RedHatInsights/insights-operator-utils/types"
+)
+
+const coolDown = 50 * time.Millisecond
+
+var breakerConfiguration = httputils.CircuitBreakerConfiguration{
+	FailureRateThreshold: 0.5,
+	MinRequests:          4,
+	FailureWindow:        time.Minute,
+	CoolDown:             coolDown,
+	HalfOpenRequests:     2,
+}
+
+func breakerState(target string) float64 {
+	return testutil.ToFloat64(metrics.HTTPClientCircuitBreakerState.WithLabelValues(target))
+}
+
+// allow checks that the request is allowed by the breaker
+func allow(t *testing.T, breaker *httputils.CircuitBreaker) httputils.CircuitGeneration {
+	generation, err := breaker.Allow()
+	helpers.FailOnError(t, err)
+	return generation
+}
+
+// send records result of one request allowed by the breaker
+func send(t *testing.T, breaker *httputils.CircuitBreaker, success bool) {
+	breaker.Record(allow(t, breaker), success)
+}
+
+func TestCircuitBreakerOpensOnFailureRate(t *testing.T) {
+	breaker := httputils.NewCircuitBreaker("opens", breakerConfiguration)
+
+	for _, success := range []bool{true, false, true} {
+		send(t, breaker, success)
+	}
+	assert.Equal(t, httputils.CircuitClosed, breaker.State())
+
+	send(t, breaker, false)
+	assert.Equal(t, httputils.CircuitOpen, breaker.State())
+	assert.Equal(t, float64(httputils.CircuitOpen), breakerState("opens"))
+
+	_, err := breaker.Allow()
+	var openErr *types.CircuitOpenError
+	assert.True(t, errors.As(err, &openErr))
+	assert.Equal(t, "opens", openErr.Target)
+	assert.Positive(t, openErr.RetryAfter)
+}
+
+func TestCircuitBreakerHalfOpen(t *testing.T) {
+	breaker := httputils.NewCircuitBreaker("half-open", breakerConfiguration)
+	for i := 0; i < 4; i++ {
+		send(t, breaker, false)
+	}
+	assert.Equal(t, httputils.CircuitOpen, breaker.State())
+
+	time.Sleep(coolDown)
+	assert.Equal(t, httputils.CircuitHalfOpen, breaker.State())
+	assert.Equal(t, float64(httputils.CircuitHalfOpen), breakerState("half-open"))
+
+	// only the configured number of trial requests is allowed
+	first := allow(t, breaker)
+	second := allow(t, breaker)
+	_, err := breaker.Allow()
+	assert.Error(t, err)
+
+	breaker.Record(first, true)
+	assert.Equal(t, httputils.CircuitHalfOpen, breaker.State())
+	breaker.Record(second, true)
+	assert.Equal(t, httputils.CircuitClosed, breaker.State())
+	assert.Equal(t, float64(httputils.CircuitClosed), breakerState("half-open"))
+}
+
+func TestCircuitBreakerReopensOnTrialFailure(t *testing.T) {
+	breaker := httputils.NewCircuitBreaker("reopens", breakerConfiguration)
+	for i := 0; i < 4; i++ {
+		send(t, breaker, false)
+	}
+
+	time.Sleep(coolDown)
+	send(t, breaker, false)
+	assert.Equal(t, httputils.CircuitOpen, breaker.State())
+}
+
+func TestCircuitBreakerIgnoresStaleResults(t *testing.T) {
+	breaker := httputils.NewCircuitBreaker("stale", breakerConfiguration)
+	// request allowed while the breaker is closed finishes after it's half-open
+	slow := allow(t, breaker)
+	for i := 0; i < 4; i++ {
+		send(t, breaker, false)
+	}
+	time.Sleep(coolDown)
+	assert.Equal(t, httputils.CircuitHalfOpen, breaker.State())
+
+	breaker.Record(slow, true)
+	breaker.Record(slow, true)
+	assert.Equal(t, httputils.CircuitHalfOpen, breaker.State())
+
+	// both trial requests are still allowed
+	first := allow(t, breaker)
+	second := allow(t, breaker)
+	breaker.Record(first, true)
+	breaker.Record(second, true)
+	assert.Equal(t, httputils.CircuitClosed, breaker.State())
+}
+
+func TestCircuitBreakerCancel(t *testing.T) {
+	configuration := breakerConfiguration
+	configuration.HalfOpenRequests = 1
+	breaker := httputils.NewCircuitBreaker("cancel", configuration)
+	for i := 0; i < 4; i++ {
+		send(t, breaker, false)
+	}
+	time.Sleep(coolDown)
+
+	// canceled trial request is neither success nor failure
+	breaker.Cancel(allow(t, breaker))
+	assert.Equal(t, httputils.CircuitHalfOpen, breaker.State())
+
+	send(t, breaker, true)
+	assert.Equal(t, httputils.CircuitClosed, breaker.State())
+}
+
+func TestCircuitBreakerFailureWindow(t *testing.T) {
+	configuration := breakerConfiguration
+	configuration.FailureWindow = coolDown
+	breaker := httputils.NewCircuitBreaker("window", configuration)
+
+	for i := 0; i < 3; i++ {
+		send(t, breaker, false)
+	}
+
+	// failures from the previous window are forgotten
+	time.Sleep(2 * coolDown)
+	send(t, breaker, false)
+	assert.Equal(t, httputils.CircuitClosed, breaker.State())
+}
+
+func TestCircuitStateString(t *testing.T) {
+	assert.Equal(t, "closed", httputils.CircuitClosed.String())
+	assert.Equal(t, "half-open", httputils.CircuitHalfOpen.String())
+	assert.Equal(t, "open", httputils.CircuitOpen.String())
+	assert.Equal(t, "unknown", httputils.CircuitState(42).String())
+}
+
+func TestClientCircuitBreaker(t *testing.T) {
+	var healthy atomic.Bool
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		attempts.Add(1)
+		if !healthy.Load() {
+			writer.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	configuration := breakerConfiguration
+	configuration.HalfOpenRequests = 1
+	client := httputils.NewClient(httputils.ClientConfiguration{CircuitBreaker: &configuration})
+
+	for i := 0; i < 4; i++ {
+		_, err := client.Get(context.Background(), server.URL)
+		assert.Error(t, err)
+	}
+
+	// the breaker is open, so the server is not called
+	_, err := client.Get(context.Background(), server.URL)
+	var openErr *types.CircuitOpenError
+	assert.True(t, errors.As(err, &openErr))
+	assert.Equal(t, int32(4), attempts.Load())
+
+	recorder := httptest.NewRecorder()
+	types.HandleServerError(recorder, err)
+	assert.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+
+	// the trial request succeeds and closes the breaker
+	healthy.Store(true)
+	time.Sleep(coolDown)
+	_, err = client.Get(context.Background(), server.URL)
+	helpers.FailOnError(t, err)
+	assert.Equal(t, float64(httputils.CircuitClosed), breakerState(targetOf(t, server)))
+}
+
+func TestClientCircuitBreakerCanceledTrial(t *testing.T) {
+	var status atomic.Int32
+	status.Store(http.StatusInternalServerError)
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if status.Load() == 0 {
+			// the target doesn't answer until the caller gives up
+			<-request.Context().Done()
+			return
+		}
+		writer.WriteHeader(int(status.Load()))
+	}))
+	defer server.Close()
+
+	configuration := breakerConfiguration
+	configuration.HalfOpenRequests = 1
+	client := httputils.NewClient(httputils.ClientConfiguration{CircuitBreaker: &configuration})
+
+	for i := 0; i < 4; i++ {
+		_, err := client.Get(context.Background(), server.URL)
+		assert.Error(t, err)
+	}
+	time.Sleep(coolDown)
+
+	// the trial request is canceled by the caller
+	status.Store(0)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err := client.Get(ctx, server.URL)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, float64(httputils.CircuitHalfOpen), breakerState(targetOf(t, server)))
+
+	// so another trial request is allowed
+	status.Store(http.StatusOK)
+	_, err = client.Get(context.Background(), server.URL)
+	helpers.FailOnError(t, err)
+	assert.Equal(t, float64(httputils.CircuitClosed), breakerState(targetOf(t, server)))
+}
+
+func TestClientCircuitBreakerIgnoresClientErrors(t *testing.T) {
+	server, _ := startFlakyServer(t, http.StatusNotFound)
+	client := httputils.NewClient(httputils.ClientConfiguration{CircuitBreaker: &breakerConfiguration})
+
+	for i := 0; i < 10; i++ {
+		_, err := client.Get(context.Background(), server.URL)
+		var responseErr *httputils.ResponseError
+		assert.True(t, errors.As(err, &responseErr))
+	}
+	assert.Equal(t, float64(httputils.CircuitClosed), breakerState(targetOf(t, server)))
+}
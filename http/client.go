@@ -0,0 +1,361 @@
+// Copyright 2026 Red Hat, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httputils
+
+// Documentation in literate-programming-style is available at:
+// https://redhatinsights.github.io/insights-operator-utils/packages/http/client.html
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rs/zerolog/log"
+
+	"github.com/RedHatInsights/insights-operator-utils/metrics"
+)
+
+// Default values used for unset fields of ClientConfiguration
+const (
+	DefaultClientTimeout        = 10 * time.Second
+	DefaultClientInitialBackoff = 100 * time.Millisecond
+	DefaultClientMaxBackoff     = 5 * time.Second
+	DefaultClientMaxBodySize    = 10 * 1024 * 1024
+	DefaultClientMaxIdleConns   = 10
+)
+
+const statusCodeError = "error"
+
+// ClientConfiguration represents configuration of Client
+type ClientConfiguration struct {
+	// Timeout limits duration of each attempt
+	Timeout time.Duration `mapstructure:"timeout" toml:"timeout"`
+	// MaxRetries is the number of retries after the first attempt, zero
+	// disables retries
+	MaxRetries     int           `mapstructure:"max_retries" toml:"max_retries"`
+	InitialBackoff time.Duration `mapstructure:"initial_backoff" toml:"initial_backoff"`
+	// MaxBackoff limits the delay between attempts. Responses asking for
+	// longer delay in Retry-After header are not retried.
+	MaxBackoff time.Duration `mapstructure:"max_backoff" toml:"max_backoff"`
+	// MaxBodySize limits size of response bodies in bytes
+	MaxBodySize int64 `mapstructure:"max_body_size" toml:"max_body_size"`
+	// MaxIdleConnsPerHost limits the number of pooled connections per host
+	MaxIdleConnsPerHost int `mapstructure:"max_idle_conns_per_host" toml:"max_idle_conns_per_host"`
+	// CircuitBreaker enables circuit breakers per target host when set
+	CircuitBreaker *CircuitBreakerConfiguration `mapstructure:"circuit_breaker" toml:"circuit_breaker"`
+}
+
+// ClientResponse represents a successful response read by Client
+type ClientResponse struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+// ResponseError is returned by Client when the response status code is not
+// 2xx
+type ResponseError struct {
+	Target     string
+	StatusCode int
+	Body       []byte
+}
+
+func (e *ResponseError) Error() string {
+	return fmt.Sprintf("request to %s failed with status code %d", e.Target, e.StatusCode)
+}
+
+// BodyTooLargeError is returned by Client when the response body exceeds
+// the configured limit
+type BodyTooLargeError struct {
+	Target string
+	Limit  int64
+}
+
+func (e *BodyTooLargeError) Error() string {
+	return fmt.Sprintf("response from %s exceeds the limit of %d bytes", e.Target, e.Limit)
+}
+
+// Client is HTTP client for service to service communication. It reuses
+// connections, retries idempotent requests failing on network errors or with
+// 5xx or 429 status codes, and records metrics per target host. Optionally,
+// requests to each target host are protected by a circuit breaker.
+type Client struct {
+	httpClient    *http.Client
+	configuration ClientConfiguration
+
+	breakersMutex sync.Mutex
+	breakers      map[string]*CircuitBreaker
+}
+
+// NewClient creates a new client with the given configuration. Unset fields
+// are replaced by defaults.
+func NewClient(configuration ClientConfiguration) *Client {
+	if configuration.Timeout <= 0 {
+		configuration.Timeout = DefaultClientTimeout
+	}
+	if configuration.InitialBackoff <= 0 {
+		configuration.InitialBackoff = DefaultClientInitialBackoff
+	}
+	if configuration.MaxBackoff <= 0 {
+		configuration.MaxBackoff = DefaultClientMaxBackoff
+	}
+	if configuration.MaxBodySize <= 0 {
+		configuration.MaxBodySize = DefaultClientMaxBodySize
+	}
+	if configuration.MaxIdleConnsPerHost <= 0 {
+		configuration.MaxIdleConnsPerHost = DefaultClientMaxIdleConns
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConnsPerHost = configuration.MaxIdleConnsPerHost
+
+	return &Client{
+		httpClient: &http.Client{
+			Transport: transport,
+			Timeout:   configuration.Timeout,
+		},
+		configuration: configuration,
+		breakers:      make(map[string]*CircuitBreaker),
+	}
+}
+
+// Get sends GET request to the given URL
+func (client *Client) Get(ctx context.Context, url string) (*ClientResponse, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, url, http.NoBody)
+	if err != nil {
+		return nil, err
+	}
+	return client.Do(request)
+}
+
+// Do sends the request and reads the response body. Idempotent requests are
+// retried, their body needs to be replayable (request.GetBody is set by
+// http.NewRequest for common body types). ID of the request stored in the
+// request context by RequestID middleware is forwarded in X-Request-Id
+// header. ResponseError is returned for responses with other than 2xx status
+// code and types.CircuitOpenError when the circuit breaker of the target is
+// open.
+func (client *Client) Do(request *http.Request) (*ClientResponse, error) {
+	if requestID := GetRequestID(request.Context()); requestID != "" && request.Header.Get(RequestIDHeader) == "" {
+		request.Header.Set(RequestIDHeader, requestID)
+	}
+
+	target := request.URL.Host
+	retryable := isIdempotent(request.Method) &&
+		(request.Body == nil || request.Body == http.NoBody || request.GetBody != nil)
+
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 && request.GetBody != nil {
+			body, err := request.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			request.Body = body
+		}
+
+		breaker := client.circuitBreaker(target)
+		var generation CircuitGeneration
+		if breaker != nil {
+			var err error
+			if generation, err = breaker.Allow(); err != nil {
+				return nil, err
+			}
+		}
+
+		response, err := client.send(request, target)
+		switch {
+		case breaker == nil:
+		case request.Context().Err() != nil:
+			// the target didn't necessarily answer, so the result is unknown
+			breaker.Cancel(generation)
+		default:
+			breaker.Record(generation, !isDependencyFailure(request, err))
+		}
+		if err != nil && (request.Context().Err() != nil || !isRetryableError(err)) {
+			return response, err
+		}
+
+		delay, retry := client.retryDelay(response, err, attempt)
+		if !retryable || !retry {
+			return response, err
+		}
+
+		log.Warn().Err(err).Str("target", target).Int("attempt", attempt+1).
+			Dur("delay", delay).Msg("Retrying HTTP request")
+		metrics.HTTPClientRetries.With(prometheus.Labels{"target": target, "method": request.Method}).Inc()
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-request.Context().Done():
+			timer.Stop()
+			return nil, request.Context().Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// circuitBreaker returns breaker of the given target or nil when circuit
+// breakers are disabled
+func (client *Client) circuitBreaker(target string) *CircuitBreaker {
+	if client.configuration.CircuitBreaker == nil {
+		return nil
+	}
+
+	client.breakersMutex.Lock()
+	defer client.breakersMutex.Unlock()
+
+	breaker, found := client.breakers[target]
+	if !found {
+		breaker = NewCircuitBreaker(target, *client.configuration.CircuitBreaker)
+		client.breakers[target] = breaker
+	}
+	return breaker
+}
+
+// send makes one attempt to send the request and read the response
+func (client *Client) send(request *http.Request, target string) (*ClientResponse, error) {
+	startTime := time.Now()
+	// #nosec G704 -- URLs are provided by the service configuration, callers
+	// are responsible for URL validation if needed
+	response, err := client.httpClient.Do(request)
+	metrics.HTTPClientRequestDuration.With(
+		prometheus.Labels{"target": target, "method": request.Method},
+	).Observe(time.Since(startTime).Seconds())
+
+	statusCode := statusCodeError
+	if err == nil {
+		statusCode = strconv.Itoa(response.StatusCode)
+	}
+	metrics.HTTPClientRequests.With(
+		prometheus.Labels{"target": target, "method": request.Method, "status_code": statusCode},
+	).Inc()
+
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := response.Body.Close(); err != nil {
+			log.Error().Err(err).Msg("Unable to close response body")
+		}
+	}()
+
+	body, err := io.ReadAll(io.LimitReader(response.Body, client.configuration.MaxBodySize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(body)) > client.configuration.MaxBodySize {
+		return nil, &BodyTooLargeError{Target: target, Limit: client.configuration.MaxBodySize}
+	}
+
+	clientResponse := &ClientResponse{
+		StatusCode: response.StatusCode,
+		Header:     response.Header,
+		Body:       body,
+	}
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return clientResponse, &ResponseError{Target: target, StatusCode: response.StatusCode, Body: body}
+	}
+	return clientResponse, nil
+}
+
+// retryDelay decides whether the attempt should be retried and how long to
+// wait before the next attempt
+func (client *Client) retryDelay(response *ClientResponse, err error, attempt int) (time.Duration, bool) {
+	if attempt >= client.configuration.MaxRetries {
+		return 0, false
+	}
+
+	var responseErr *ResponseError
+	if errors.As(err, &responseErr) {
+		if responseErr.StatusCode != http.StatusTooManyRequests && responseErr.StatusCode < 500 {
+			return 0, false
+		}
+		if retryAfter, found := parseRetryAfter(response.Header.Get("Retry-After")); found {
+			return retryAfter, retryAfter <= client.configuration.MaxBackoff
+		}
+	} else if err == nil {
+		return 0, false
+	}
+
+	return client.backoff(attempt), true
+}
+
+// backoff returns exponentially growing delay with jitter, the delay is
+// doubled only until it reaches MaxBackoff, so it never overflows
+func (client *Client) backoff(attempt int) time.Duration {
+	maxBackoff := client.configuration.MaxBackoff
+	delay := min(client.configuration.InitialBackoff, maxBackoff)
+	for i := 0; i < attempt && delay < maxBackoff; i++ {
+		if delay > maxBackoff/2 {
+			delay = maxBackoff
+		} else {
+			delay *= 2
+		}
+	}
+	// jitter between a half and the full delay
+	// #nosec G404 -- the random value is not used for security purposes
+	return delay/2 + rand.N(delay/2+1)
+}
+
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return max(time.Until(date), 0), true
+	}
+	return 0, false
+}
+
+// isRetryableError returns false for errors that are not expected to be
+// solved by retrying the request
+func isRetryableError(err error) bool {
+	var bodyTooLarge *BodyTooLargeError
+	return !errors.As(err, &bodyTooLarge)
+}
+
+// isDependencyFailure returns true when the error indicates that the target
+// is not healthy, i.e. on network errors and 5xx responses
+func isDependencyFailure(request *http.Request, err error) bool {
+	if err == nil || request.Context().Err() != nil {
+		return false
+	}
+
+	var responseErr *ResponseError
+	if errors.As(err, &responseErr) {
+		return responseErr.StatusCode >= http.StatusInternalServerError
+	}
+	return isRetryableError(err)
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace,
+		http.MethodPut, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,91 @@
+// Copyright 2026 Red Hat, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httputils
+
+// Documentation in literate-programming-style is available at:
+// https://redhatinsights.github.io/insights-operator-utils/packages/http/client_certificate.html
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/RedHatInsights/insights-operator-utils/types"
+)
+
+// ContextKey is a type for keys of values stored in request context by
+// middlewares from this package
+type ContextKey string
+
+// ContextKeyPeerIdentity is a key of PeerIdentity stored in request context
+const ContextKeyPeerIdentity = ContextKey("peer_identity")
+
+// PeerIdentity describes the verified client certificate of a request
+type PeerIdentity struct {
+	// Subject is the distinguished name of the certificate subject
+	Subject      string
+	CommonName   string
+	Organization []string
+	SerialNumber string
+	// Fingerprint is a hex encoded SHA-256 checksum of the certificate
+	Fingerprint string
+}
+
+// ClientCertificateIdentity creates a middleware storing identity of the
+// verified client certificate in request context. Only certificates verified
+// by the server are taken into account, so the server needs to be configured
+// with "verify_if_given" or "require_and_verify" client authentication. When
+// required is true, requests without verified client certificate are refused
+// with 401 Unauthorized.
+func ClientCertificateIdentity(required bool) mux.MiddlewareFunc {
+	return func(nextHandler http.Handler) http.Handler {
+		return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			if request.TLS == nil || len(request.TLS.VerifiedChains) == 0 ||
+				len(request.TLS.VerifiedChains[0]) == 0 {
+				if required {
+					types.HandleServerError(writer, &types.UnauthorizedError{
+						ErrString: "verified client certificate is required",
+					})
+					return
+				}
+				nextHandler.ServeHTTP(writer, request)
+				return
+			}
+
+			certificate := request.TLS.VerifiedChains[0][0]
+			fingerprint := sha256.Sum256(certificate.Raw)
+			identity := PeerIdentity{
+				Subject:      certificate.Subject.String(),
+				CommonName:   certificate.Subject.CommonName,
+				Organization: certificate.Subject.Organization,
+				SerialNumber: certificate.SerialNumber.String(),
+				Fingerprint:  hex.EncodeToString(fingerprint[:]),
+			}
+
+			ctx := context.WithValue(request.Context(), ContextKeyPeerIdentity, identity)
+			nextHandler.ServeHTTP(writer, request.WithContext(ctx))
+		})
+	}
+}
+
+// GetPeerIdentity returns identity of the verified client certificate stored
+// in request context by ClientCertificateIdentity middleware
+func GetPeerIdentity(request *http.Request) (PeerIdentity, bool) {
+	identity, ok := request.Context().Value(ContextKeyPeerIdentity).(PeerIdentity)
+	return identity, ok
+}
@@ -0,0 +1,132 @@
+// Copyright 2026 Red Hat, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httputils_test
+
+// Documentation in literate-programming-style is available at:
+// https://redhatinsights.github.io/insights-operator-utils/packages/http/client_certificate_test.html
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	httputils "github.com/RedHatInsights/insights-operator-utils/http"
+	"github.com/RedHatInsights/insights-operator-utils/tests/helpers"
+)
+
+const certificateValidity = time.Hour
+
+// startMutualTLSServer starts HTTPS server verifying client certificates
+// issued by the given CA, if they are provided. The handler writes identity
+// of the peer as JSON.
+func startMutualTLSServer(
+	t *testing.T, ca *helpers.TestCertificateAuthority, required bool,
+) *httptest.Server {
+	issued := ca.Issue(t, "server", certificateValidity)
+	certificate, err := tls.X509KeyPair(issued.CertificatePEM, issued.KeyPEM)
+	helpers.FailOnError(t, err)
+
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(ca.Certificate)
+
+	handler := http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		identity, found := httputils.GetPeerIdentity(request)
+		if !found {
+			writer.WriteHeader(http.StatusNoContent)
+			return
+		}
+		helpers.FailOnError(t, json.NewEncoder(writer).Encode(identity))
+	})
+
+	server := httptest.NewUnstartedServer(httputils.ClientCertificateIdentity(required)(handler))
+	server.TLS = &tls.Config{
+		Certificates: []tls.Certificate{certificate},
+		ClientCAs:    clientCAs,
+		ClientAuth:   tls.VerifyClientCertIfGiven,
+		MinVersion:   tls.VersionTLS12,
+	}
+	server.StartTLS()
+	t.Cleanup(server.Close)
+	return server
+}
+
+func getWithCertificate(
+	t *testing.T, ca *helpers.TestCertificateAuthority, client *helpers.TestCertificate, url string,
+) *http.Response {
+	rootCAs := x509.NewCertPool()
+	rootCAs.AddCert(ca.Certificate)
+	tlsConfig := &tls.Config{RootCAs: rootCAs, MinVersion: tls.VersionTLS12}
+
+	if client != nil {
+		certificate, err := tls.X509KeyPair(client.CertificatePEM, client.KeyPEM)
+		helpers.FailOnError(t, err)
+		tlsConfig.Certificates = []tls.Certificate{certificate}
+	}
+
+	httpClient := http.Client{
+		Transport: &http.Transport{TLSClientConfig: tlsConfig},
+		Timeout:   5 * time.Second,
+	}
+	response, err := httpClient.Get(url)
+	helpers.FailOnError(t, err)
+	t.Cleanup(func() { _ = response.Body.Close() })
+	return response
+}
+
+func TestClientCertificateIdentity(t *testing.T) {
+	ca := helpers.NewTestCertificateAuthority(t, "ca", certificateValidity)
+	client := ca.Issue(t, "client", certificateValidity)
+	server := startMutualTLSServer(t, ca, true)
+
+	response := getWithCertificate(t, ca, client, server.URL)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+
+	var identity httputils.PeerIdentity
+	helpers.FailOnError(t, json.NewDecoder(response.Body).Decode(&identity))
+	assert.Equal(t, "client", identity.CommonName)
+	assert.Equal(t, "CN=client,O=Red Hat", identity.Subject)
+	assert.Equal(t, []string{"Red Hat"}, identity.Organization)
+	assert.Equal(t, client.Certificate.SerialNumber.String(), identity.SerialNumber)
+	assert.Len(t, identity.Fingerprint, 64)
+}
+
+func TestClientCertificateIdentityRequired(t *testing.T) {
+	ca := helpers.NewTestCertificateAuthority(t, "ca", certificateValidity)
+	server := startMutualTLSServer(t, ca, true)
+
+	response := getWithCertificate(t, ca, nil, server.URL)
+	assert.Equal(t, http.StatusUnauthorized, response.StatusCode)
+}
+
+func TestClientCertificateIdentityOptional(t *testing.T) {
+	ca := helpers.NewTestCertificateAuthority(t, "ca", certificateValidity)
+	server := startMutualTLSServer(t, ca, false)
+
+	response := getWithCertificate(t, ca, nil, server.URL)
+	assert.Equal(t, http.StatusNoContent, response.StatusCode)
+}
+
+func TestClientCertificateIdentityWithoutTLS(t *testing.T) {
+	handler := httputils.ClientCertificateIdentity(true)(http.NotFoundHandler())
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", http.NoBody))
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+}
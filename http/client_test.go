@@ -0,0 +1,264 @@
+// Copyright 2026 Red Hat, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httputils_test
+
+// Documentation in literate-programming-style is available at:
+// https://redhatinsights.github.io/insights-operator-utils/packages/http/client_test.html
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+
+	httputils "github.com/RedHatInsights/insights-operator-utils/http"
+	"github.com/RedHatInsights/insights-operator-utils/metrics"
+	"github.com/RedHatInsights/insights-operator-utils/tests/helpers"
+)
+
+var retryingClientConfiguration = httputils.ClientConfiguration{
+	Timeout:        time.Second,
+	MaxRetries:     3,
+	InitialBackoff: time.Millisecond,
+	MaxBackoff:     10 * time.Millisecond,
+}
+
+// startFlakyServer starts a server responding with the given status codes,
+// the last status code is used for all remaining requests
+func startFlakyServer(t *testing.T, statusCodes ...int) (*httptest.Server, *atomic.Int32) {
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		attempt := int(attempts.Add(1)) - 1
+		statusCode := statusCodes[min(attempt, len(statusCodes)-1)]
+		writer.WriteHeader(statusCode)
+		_, _ = writer.Write([]byte(http.StatusText(statusCode)))
+	}))
+	t.Cleanup(server.Close)
+	return server, &attempts
+}
+
+func targetOf(t *testing.T, server *httptest.Server) string {
+	parsed, err := url.Parse(server.URL)
+	helpers.FailOnError(t, err)
+	return parsed.Host
+}
+
+func TestClientGet(t *testing.T) {
+	server, _ := startFlakyServer(t, http.StatusOK)
+	client := httputils.NewClient(httputils.ClientConfiguration{})
+
+	response, err := client.Get(context.Background(), server.URL)
+	helpers.FailOnError(t, err)
+
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, []byte("OK"), response.Body)
+	assert.Equal(t, 1.0, testutil.ToFloat64(
+		metrics.HTTPClientRequests.WithLabelValues(targetOf(t, server), http.MethodGet, "200"),
+	))
+}
+
+func TestClientRetriesServerErrors(t *testing.T) {
+	server, attempts := startFlakyServer(t, http.StatusServiceUnavailable, http.StatusTooManyRequests, http.StatusOK)
+	client := httputils.NewClient(retryingClientConfiguration)
+
+	response, err := client.Get(context.Background(), server.URL)
+	helpers.FailOnError(t, err)
+
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, int32(3), attempts.Load())
+	assert.Equal(t, 2.0, testutil.ToFloat64(
+		metrics.HTTPClientRetries.WithLabelValues(targetOf(t, server), http.MethodGet),
+	))
+}
+
+func TestClientGivesUpAfterMaxRetries(t *testing.T) {
+	server, attempts := startFlakyServer(t, http.StatusBadGateway)
+	client := httputils.NewClient(retryingClientConfiguration)
+
+	response, err := client.Get(context.Background(), server.URL)
+
+	var responseErr *httputils.ResponseError
+	assert.True(t, errors.As(err, &responseErr))
+	assert.Equal(t, http.StatusBadGateway, responseErr.StatusCode)
+	assert.Equal(t, []byte("Bad Gateway"), responseErr.Body)
+	assert.Equal(t, http.StatusBadGateway, response.StatusCode)
+	assert.Equal(t, int32(4), attempts.Load())
+}
+
+// TestClientBackoffManyAttempts checks that the delay doesn't overflow when
+// initial backoff is doubled many times
+func TestClientBackoffManyAttempts(t *testing.T) {
+	server, attempts := startFlakyServer(t, http.StatusServiceUnavailable)
+	client := httputils.NewClient(httputils.ClientConfiguration{
+		MaxRetries:     70,
+		InitialBackoff: 10 * time.Second,
+		MaxBackoff:     time.Millisecond,
+	})
+
+	_, err := client.Get(context.Background(), server.URL)
+	assert.Error(t, err)
+	assert.Equal(t, int32(71), attempts.Load())
+}
+
+func TestClientDoesNotRetryClientErrors(t *testing.T) {
+	server, attempts := startFlakyServer(t, http.StatusNotFound)
+	client := httputils.NewClient(retryingClientConfiguration)
+
+	_, err := client.Get(context.Background(), server.URL)
+
+	var responseErr *httputils.ResponseError
+	assert.True(t, errors.As(err, &responseErr))
+	assert.Equal(t, http.StatusNotFound, responseErr.StatusCode)
+	assert.Equal(t, int32(1), attempts.Load())
+}
+
+func TestClientDoesNotRetryPost(t *testing.T) {
+	server, attempts := startFlakyServer(t, http.StatusServiceUnavailable)
+	client := httputils.NewClient(retryingClientConfiguration)
+
+	request, err := http.NewRequest(http.MethodPost, server.URL, strings.NewReader("{}"))
+	helpers.FailOnError(t, err)
+	_, err = client.Do(request)
+
+	assert.Error(t, err)
+	assert.Equal(t, int32(1), attempts.Load())
+}
+
+func TestClientReplaysBodyOfRetriedRequests(t *testing.T) {
+	var bodies []string
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		body, _ := io.ReadAll(request.Body)
+		bodies = append(bodies, string(body))
+		if attempts.Add(1) == 1 {
+			writer.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	client := httputils.NewClient(retryingClientConfiguration)
+	request, err := http.NewRequest(http.MethodPut, server.URL, bytes.NewReader([]byte("payload")))
+	helpers.FailOnError(t, err)
+
+	_, err = client.Do(request)
+	helpers.FailOnError(t, err)
+	assert.Equal(t, []string{"payload", "payload"}, bodies)
+}
+
+func TestClientRetryAfter(t *testing.T) {
+	var attempts atomic.Int32
+	var retryAfter string
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if attempts.Add(1) == 1 {
+			writer.Header().Set("Retry-After", retryAfter)
+			writer.WriteHeader(http.StatusTooManyRequests)
+		}
+	}))
+	defer server.Close()
+
+	client := httputils.NewClient(httputils.ClientConfiguration{
+		MaxRetries:     1,
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     2 * time.Second,
+	})
+
+	// delay within the limit is respected
+	retryAfter = "1"
+	startTime := time.Now()
+	_, err := client.Get(context.Background(), server.URL)
+	helpers.FailOnError(t, err)
+	assert.GreaterOrEqual(t, time.Since(startTime), time.Second)
+
+	// longer delay is not waited for
+	attempts.Store(0)
+	retryAfter = time.Now().Add(time.Hour).UTC().Format(http.TimeFormat)
+	_, err = client.Get(context.Background(), server.URL)
+	assert.Error(t, err)
+	assert.Equal(t, int32(1), attempts.Load())
+}
+
+func TestClientMaxBodySize(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		_, _ = writer.Write(bytes.Repeat([]byte("x"), 11))
+	}))
+	defer server.Close()
+
+	client := httputils.NewClient(httputils.ClientConfiguration{MaxBodySize: 10, MaxRetries: 3})
+	_, err := client.Get(context.Background(), server.URL)
+
+	var tooLarge *httputils.BodyTooLargeError
+	assert.True(t, errors.As(err, &tooLarge))
+	assert.Equal(t, int64(10), tooLarge.Limit)
+}
+
+func TestClientCancelledContext(t *testing.T) {
+	server, attempts := startFlakyServer(t, http.StatusServiceUnavailable)
+	client := httputils.NewClient(httputils.ClientConfiguration{
+		MaxRetries:     5,
+		InitialBackoff: time.Second,
+		MaxBackoff:     time.Second,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	_, err := client.Get(ctx, server.URL)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, int32(1), attempts.Load())
+}
+
+func TestClientRetriesNetworkErrors(t *testing.T) {
+	server, _ := startFlakyServer(t, http.StatusOK)
+	serverURL := server.URL
+	server.Close()
+
+	client := httputils.NewClient(retryingClientConfiguration)
+	_, err := client.Get(context.Background(), serverURL)
+
+	assert.Error(t, err)
+	assert.Equal(t, 3.0, testutil.ToFloat64(
+		metrics.HTTPClientRetries.WithLabelValues(strings.TrimPrefix(serverURL, "http://"), http.MethodGet),
+	))
+}
+
+func TestClientForwardsRequestID(t *testing.T) {
+	var forwarded string
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		forwarded = request.Header.Get(httputils.RequestIDHeader)
+	}))
+	defer server.Close()
+
+	client := httputils.NewClient(httputils.ClientConfiguration{})
+	handler := httputils.RequestID(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		_, err := client.Get(request.Context(), server.URL)
+		helpers.FailOnError(t, err)
+	}))
+
+	request := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+	request.Header.Set(httputils.RequestIDHeader, "request-42")
+	handler.ServeHTTP(httptest.NewRecorder(), request)
+
+	assert.Equal(t, "request-42", forwarded)
+}
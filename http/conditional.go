@@ -0,0 +1,86 @@
+// Copyright 2026 Red Hat, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httputils
+
+// Documentation in literate-programming-style is available at:
+// https://redhatinsights.github.io/insights-operator-utils/packages/http/conditional.html
+
+import (
+	"net/http"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/RedHatInsights/insights-operator-utils/responses"
+)
+
+// ConditionalGET is a middleware adding conditional request support to GET
+// and HEAD endpoints. The whole response of the handler is buffered and,
+// unless the handler set ETag itself, a strong ETag is computed from the
+// body. Successful responses are replaced by 304 Not Modified without body
+// when If-None-Match or If-Modified-Since headers of the request match. The
+// Last-Modified header set by the handler is honored too. As the response is
+// buffered, the middleware is not suitable for streamed responses.
+func ConditionalGET(nextHandler http.Handler) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if request.Method != http.MethodGet && request.Method != http.MethodHead {
+			nextHandler.ServeHTTP(writer, request)
+			return
+		}
+
+		buffered := newBufferedResponse()
+		nextHandler.ServeHTTP(buffered, request)
+
+		if buffered.StatusCode() == http.StatusOK {
+			validators := bufferedValidators(buffered)
+			validators.SetHeaders(buffered)
+
+			if validators.NotModified(request) {
+				sendNotModified(writer, buffered)
+				return
+			}
+		}
+
+		if err := buffered.sendTo(writer); err != nil {
+			log.Error().Err(err).Msg("error writing response")
+		}
+	})
+}
+
+// bufferedValidators returns validators set by the handler, the ETag is
+// computed from the body when not set
+func bufferedValidators(buffered *bufferedResponse) responses.Validators {
+	validators := responses.Validators{ETag: buffered.header.Get(responses.ETagHeader)}
+	if validators.ETag == "" {
+		validators.ETag = responses.StrongETag(buffered.body.Bytes())
+	}
+	if lastModified := buffered.header.Get(responses.LastModifiedHeader); lastModified != "" {
+		if parsed, err := http.ParseTime(lastModified); err == nil {
+			validators.LastModified = parsed
+		}
+	}
+	return validators
+}
+
+// sendNotModified sends 304 Not Modified with headers of the buffered
+// response, except the ones describing the body that is not sent
+func sendNotModified(writer http.ResponseWriter, buffered *bufferedResponse) {
+	for key, values := range buffered.header {
+		if key == contentTypeHeader || key == "Content-Length" {
+			continue
+		}
+		writer.Header()[key] = values
+	}
+	writer.WriteHeader(http.StatusNotModified)
+}
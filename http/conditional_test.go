@@ -0,0 +1,102 @@
+// Copyright 2026 Red Hat, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httputils_test
+
+// Documentation in literate-programming-style is available at:
+// https://redhatinsights.github.io/insights-operator-utils/packages/http/conditional_test.html
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	httputils "github.com/RedHatInsights/insights-operator-utils/http"
+	"github.com/RedHatInsights/insights-operator-utils/responses"
+	"github.com/RedHatInsights/insights-operator-utils/tests/helpers"
+)
+
+func reportHandler(t *testing.T, statusCode int, header map[string]string) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		for key, value := range header {
+			writer.Header().Set(key, value)
+		}
+		err := responses.Send(statusCode, writer, map[string]interface{}{"report": []string{"rule1"}})
+		helpers.FailOnError(t, err)
+	})
+}
+
+func serveConditional(handler http.Handler, method string, header map[string]string) *httptest.ResponseRecorder {
+	request := httptest.NewRequest(method, "/report", http.NoBody)
+	for key, value := range header {
+		request.Header.Set(key, value)
+	}
+	recorder := httptest.NewRecorder()
+	httputils.ConditionalGET(handler).ServeHTTP(recorder, request)
+	return recorder
+}
+
+func TestConditionalGET(t *testing.T) {
+	handler := reportHandler(t, http.StatusOK, map[string]string{"Cache-Control": "private"})
+
+	recorder := serveConditional(handler, http.MethodGet, nil)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.JSONEq(t, `{"report":["rule1"]}`, recorder.Body.String())
+	etag := recorder.Header().Get(responses.ETagHeader)
+	assert.Equal(t, responses.StrongETag(recorder.Body.Bytes()), etag)
+
+	recorder = serveConditional(handler, http.MethodGet, map[string]string{responses.IfNoneMatchHeader: etag})
+	assert.Equal(t, http.StatusNotModified, recorder.Code)
+	assert.Empty(t, recorder.Body.String())
+	assert.Equal(t, etag, recorder.Header().Get(responses.ETagHeader))
+	assert.Equal(t, "private", recorder.Header().Get("Cache-Control"))
+	assert.Empty(t, recorder.Header().Get("Content-Type"))
+
+	recorder = serveConditional(handler, http.MethodHead, map[string]string{responses.IfNoneMatchHeader: etag})
+	assert.Equal(t, http.StatusNotModified, recorder.Code)
+
+	recorder = serveConditional(handler, http.MethodGet, map[string]string{responses.IfNoneMatchHeader: `"other"`})
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.JSONEq(t, `{"report":["rule1"]}`, recorder.Body.String())
+}
+
+func TestConditionalGETHandlerValidators(t *testing.T) {
+	lastModified := time.Date(2026, 3, 1, 12, 30, 15, 0, time.UTC).Format(http.TimeFormat)
+	handler := reportHandler(t, http.StatusOK, map[string]string{
+		responses.ETagHeader:         `"v1"`,
+		responses.LastModifiedHeader: lastModified,
+	})
+
+	recorder := serveConditional(handler, http.MethodGet, map[string]string{responses.IfNoneMatchHeader: `"v1"`})
+	assert.Equal(t, http.StatusNotModified, recorder.Code)
+
+	recorder = serveConditional(handler, http.MethodGet, map[string]string{responses.IfModifiedSinceHeader: lastModified})
+	assert.Equal(t, http.StatusNotModified, recorder.Code)
+	assert.Equal(t, lastModified, recorder.Header().Get(responses.LastModifiedHeader))
+}
+
+func TestConditionalGETPassThrough(t *testing.T) {
+	handler := reportHandler(t, http.StatusNotFound, nil)
+	recorder := serveConditional(handler, http.MethodGet, map[string]string{responses.IfNoneMatchHeader: "*"})
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+	assert.Empty(t, recorder.Header().Get(responses.ETagHeader))
+
+	handler = reportHandler(t, http.StatusCreated, nil)
+	recorder = serveConditional(handler, http.MethodPost, map[string]string{responses.IfNoneMatchHeader: "*"})
+	assert.Equal(t, http.StatusCreated, recorder.Code)
+	assert.JSONEq(t, `{"report":["rule1"]}`, recorder.Body.String())
+}
@@ -0,0 +1,229 @@
+// Copyright 2026 Red Hat, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httputils
+
+// Documentation in literate-programming-style is available at:
+// https://redhatinsights.github.io/insights-operator-utils/packages/http/cors.html
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/RedHatInsights/insights-operator-utils/types"
+)
+
+// Names of headers used by CORS
+const (
+	originHeader                  = "Origin"
+	varyHeader                    = "Vary"
+	accessControlRequestMethod    = "Access-Control-Request-Method"
+	accessControlRequestHeaders   = "Access-Control-Request-Headers"
+	accessControlAllowOrigin      = "Access-Control-Allow-Origin"
+	accessControlAllowMethods     = "Access-Control-Allow-Methods"
+	accessControlAllowHeaders     = "Access-Control-Allow-Headers"
+	accessControlAllowCredentials = "Access-Control-Allow-Credentials"
+	accessControlExposeHeaders    = "Access-Control-Expose-Headers"
+	accessControlMaxAge           = "Access-Control-Max-Age"
+	// corsWildcard allows any origin or any header
+	corsWildcard      = "*"
+	wildcardSubdomain = "*."
+	nullOrigin        = "null"
+)
+
+// DefaultCORSMethods are allowed when CORSConfiguration.AllowedMethods is
+// empty
+var DefaultCORSMethods = []string{
+	http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete,
+}
+
+// DefaultCORSHeaders are allowed when CORSConfiguration.AllowedHeaders is
+// empty
+var DefaultCORSHeaders = []string{"Content-Type", AuthorizationHeader, XRHIdentityHeader, RequestIDHeader}
+
+// CORSConfiguration represents configuration of CORS middleware
+type CORSConfiguration struct {
+	// AllowedOrigins contains origins allowed to call the API, e.g.
+	// "https://console.redhat.com". "*" allows all origins, but it can't be
+	// combined with AllowCredentials, and "https://*.redhat.com" allows all
+	// subdomains of redhat.com. The "null" origin is never allowed.
+	AllowedOrigins []string `mapstructure:"allowed_origins" toml:"allowed_origins"`
+	// AllowedMethods are DefaultCORSMethods when empty
+	AllowedMethods []string `mapstructure:"allowed_methods" toml:"allowed_methods"`
+	// AllowedHeaders are DefaultCORSHeaders when empty, "*" allows all
+	// headers
+	AllowedHeaders []string `mapstructure:"allowed_headers" toml:"allowed_headers"`
+	// ExposedHeaders are response headers readable by the client
+	ExposedHeaders   []string `mapstructure:"exposed_headers" toml:"exposed_headers"`
+	AllowCredentials bool     `mapstructure:"allow_credentials" toml:"allow_credentials"`
+	// MaxAge is the time the result of preflight request can be cached
+	MaxAge time.Duration `mapstructure:"max_age" toml:"max_age"`
+}
+
+// CORS creates a middleware implementing Cross-Origin Resource Sharing. The
+// configuration allowing credentials for all origins is refused, it would
+// let any site read the responses on behalf of the user. Preflight requests
+// are answered with 204 No Content, or with 403 Forbidden when the origin,
+// method or any of the headers is not allowed.
+// Other requests from allowed origins get the CORS headers, requests from
+// other origins are passed through without them.
+//
+// Middlewares added by mux.Router.Use are not called for requests with
+// method not registered for the route, so preflight requests don't reach
+// CORS middleware added that way. Either wrap the whole router by the
+// middleware or use RegisterCORS.
+func CORS(configuration CORSConfiguration) (mux.MiddlewareFunc, error) {
+	anyOrigin := slices.Contains(configuration.AllowedOrigins, corsWildcard)
+	if anyOrigin && configuration.AllowCredentials {
+		return nil, errors.New("CORS credentials can't be allowed for all origins")
+	}
+	if len(configuration.AllowedMethods) == 0 {
+		configuration.AllowedMethods = DefaultCORSMethods
+	}
+	if len(configuration.AllowedHeaders) == 0 {
+		configuration.AllowedHeaders = DefaultCORSHeaders
+	}
+	allowedMethods := strings.Join(configuration.AllowedMethods, ", ")
+	exposedHeaders := strings.Join(configuration.ExposedHeaders, ", ")
+
+	return func(nextHandler http.Handler) http.Handler {
+		return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			origin := request.Header.Get(originHeader)
+			if origin == "" {
+				nextHandler.ServeHTTP(writer, request)
+				return
+			}
+
+			writer.Header().Add(varyHeader, originHeader)
+			preflight := request.Method == http.MethodOptions && request.Header.Get(accessControlRequestMethod) != ""
+
+			if !configuration.originAllowed(origin) {
+				if preflight {
+					types.HandleServerError(writer, &types.ForbiddenError{ErrString: "origin is not allowed"})
+					return
+				}
+				nextHandler.ServeHTTP(writer, request)
+				return
+			}
+
+			if anyOrigin {
+				writer.Header().Set(accessControlAllowOrigin, corsWildcard)
+			} else {
+				writer.Header().Set(accessControlAllowOrigin, origin)
+			}
+			if configuration.AllowCredentials {
+				writer.Header().Set(accessControlAllowCredentials, "true")
+			}
+
+			if !preflight {
+				if exposedHeaders != "" {
+					writer.Header().Set(accessControlExposeHeaders, exposedHeaders)
+				}
+				nextHandler.ServeHTTP(writer, request)
+				return
+			}
+
+			writer.Header().Add(varyHeader, accessControlRequestMethod)
+			writer.Header().Add(varyHeader, accessControlRequestHeaders)
+
+			if !slices.Contains(configuration.AllowedMethods, request.Header.Get(accessControlRequestMethod)) {
+				types.HandleServerError(writer, &types.ForbiddenError{ErrString: "method is not allowed"})
+				return
+			}
+			requestedHeaders := request.Header.Get(accessControlRequestHeaders)
+			if header, allowed := configuration.headersAllowed(requestedHeaders); !allowed {
+				types.HandleServerError(writer, &types.ForbiddenError{
+					ErrString: fmt.Sprintf("header %s is not allowed", header),
+				})
+				return
+			}
+
+			writer.Header().Set(accessControlAllowMethods, allowedMethods)
+			if requestedHeaders != "" {
+				writer.Header().Set(accessControlAllowHeaders, requestedHeaders)
+			}
+			if configuration.MaxAge > 0 {
+				writer.Header().Set(accessControlMaxAge, strconv.Itoa(int(configuration.MaxAge.Seconds())))
+			}
+			writer.WriteHeader(http.StatusNoContent)
+		})
+	}, nil
+}
+
+// RegisterCORS adds CORS middleware to the router together with a route
+// matching OPTIONS requests to any path, so the middleware handles
+// preflight requests for all routes of the router
+func RegisterCORS(router *mux.Router, configuration CORSConfiguration) error {
+	middleware, err := CORS(configuration)
+	if err != nil {
+		return err
+	}
+	router.Use(middleware)
+	router.Methods(http.MethodOptions).HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		writer.WriteHeader(http.StatusNoContent)
+	})
+	return nil
+}
+
+// originAllowed checks the origin against the allowed ones, wildcard
+// "*." matches one or more subdomains. The "null" origin is sent by
+// sandboxed documents and local files, so it's refused.
+func (configuration *CORSConfiguration) originAllowed(origin string) bool {
+	origin = strings.ToLower(origin)
+	if origin == nullOrigin {
+		return false
+	}
+	for _, allowed := range configuration.AllowedOrigins {
+		allowed = strings.ToLower(allowed)
+		if allowed == corsWildcard || allowed == origin {
+			return true
+		}
+		prefix, suffix, wildcard := strings.Cut(allowed, wildcardSubdomain)
+		if !wildcard || !strings.HasPrefix(origin, prefix) || !strings.HasSuffix(origin, "."+suffix) {
+			continue
+		}
+		subdomain := origin[len(prefix) : len(origin)-len(suffix)-1]
+		if subdomain != "" && !strings.ContainsAny(subdomain, "/:@") {
+			return true
+		}
+	}
+	return false
+}
+
+// headersAllowed checks comma separated list of requested headers, the first
+// header that is not allowed is returned
+func (configuration *CORSConfiguration) headersAllowed(requestedHeaders string) (string, bool) {
+	if requestedHeaders == "" || slices.Contains(configuration.AllowedHeaders, corsWildcard) {
+		return "", true
+	}
+	for _, header := range strings.Split(requestedHeaders, ",") {
+		header = strings.TrimSpace(header)
+		if header == "" {
+			continue
+		}
+		if !slices.ContainsFunc(configuration.AllowedHeaders, func(allowed string) bool {
+			return strings.EqualFold(allowed, header)
+		}) {
+			return header, false
+		}
+	}
+	return "", true
+}
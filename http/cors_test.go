@@ -0,0 +1,186 @@
+// Copyright 2026 Red Hat, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httputils_test
+
+// Documentation in literate-programming-style is available at:
+// https://redhatinsights.github.io/insights-operator-utils/packages/http/cors_test.html
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+
+	httputils "github.com/RedHatInsights/insights-operator-utils/http"
+	"github.com/RedHatInsights/insights-operator-utils/tests/helpers"
+)
+
+var corsConfiguration = httputils.CORSConfiguration{
+	AllowedOrigins:   []string{"https://console.redhat.com", "https://*.openshift.com"},
+	ExposedHeaders:   []string{"RateLimit-Remaining"},
+	AllowCredentials: true,
+	MaxAge:           10 * time.Minute,
+}
+
+func corsRouter(t *testing.T, configuration httputils.CORSConfiguration) *mux.Router {
+	router := mux.NewRouter()
+	router.HandleFunc("/api/v1/report", func(writer http.ResponseWriter, _ *http.Request) {
+		writer.WriteHeader(http.StatusOK)
+	}).Methods(http.MethodGet)
+	helpers.FailOnError(t, httputils.RegisterCORS(router, configuration))
+	return router
+}
+
+func corsRequest(router http.Handler, method, origin string, header map[string]string) *httptest.ResponseRecorder {
+	request := httptest.NewRequest(method, "/api/v1/report", http.NoBody)
+	if origin != "" {
+		request.Header.Set("Origin", origin)
+	}
+	for key, value := range header {
+		request.Header.Set(key, value)
+	}
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func preflightHeader(method, headers string) map[string]string {
+	return map[string]string{
+		"Access-Control-Request-Method":  method,
+		"Access-Control-Request-Headers": headers,
+	}
+}
+
+func TestCORSSimpleRequest(t *testing.T) {
+	router := corsRouter(t, corsConfiguration)
+
+	recorder := corsRequest(router, http.MethodGet, "https://console.redhat.com", nil)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "https://console.redhat.com", recorder.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "true", recorder.Header().Get("Access-Control-Allow-Credentials"))
+	assert.Equal(t, "RateLimit-Remaining", recorder.Header().Get("Access-Control-Expose-Headers"))
+	assert.Equal(t, []string{"Origin"}, recorder.Header().Values("Vary"))
+
+	// requests from other origins are handled, but browsers don't let the
+	// callers read the response
+	recorder = corsRequest(router, http.MethodGet, "https://example.com", nil)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Empty(t, recorder.Header().Get("Access-Control-Allow-Origin"))
+
+	recorder = corsRequest(router, http.MethodGet, "", nil)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Empty(t, recorder.Header().Values("Vary"))
+}
+
+func TestCORSPreflight(t *testing.T) {
+	router := corsRouter(t, corsConfiguration)
+
+	recorder := corsRequest(router, http.MethodOptions, "https://console.openshift.com",
+		preflightHeader(http.MethodDelete, "content-type, x-rh-identity"))
+	assert.Equal(t, http.StatusNoContent, recorder.Code)
+	assert.Equal(t, "https://console.openshift.com", recorder.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "GET, HEAD, POST, PUT, PATCH, DELETE", recorder.Header().Get("Access-Control-Allow-Methods"))
+	assert.Equal(t, "content-type, x-rh-identity", recorder.Header().Get("Access-Control-Allow-Headers"))
+	assert.Equal(t, "600", recorder.Header().Get("Access-Control-Max-Age"))
+	assert.Equal(t, []string{"Origin", "Access-Control-Request-Method", "Access-Control-Request-Headers"},
+		recorder.Header().Values("Vary"))
+	assert.Empty(t, recorder.Header().Get("Access-Control-Expose-Headers"))
+}
+
+func TestCORSPreflightRefused(t *testing.T) {
+	router := corsRouter(t, corsConfiguration)
+
+	tests := []struct {
+		name     string
+		origin   string
+		header   map[string]string
+		expected string
+	}{
+		{"unknown origin", "https://example.com", preflightHeader(http.MethodGet, ""),
+			`{"status":"origin is not allowed"}`},
+		{"bare wildcard domain", "https://openshift.com", preflightHeader(http.MethodGet, ""),
+			`{"status":"origin is not allowed"}`},
+		{"wildcard in path", "https://evil.com/.openshift.com", preflightHeader(http.MethodGet, ""),
+			`{"status":"origin is not allowed"}`},
+		{"method", "https://console.redhat.com", preflightHeader("PURGE", ""),
+			`{"status":"method is not allowed"}`},
+		{"header", "https://console.redhat.com", preflightHeader(http.MethodGet, "Content-Type, X-Debug"),
+			`{"status":"header X-Debug is not allowed"}`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			recorder := corsRequest(router, http.MethodOptions, test.origin, test.header)
+			assert.Equal(t, http.StatusForbidden, recorder.Code)
+			assert.JSONEq(t, test.expected, recorder.Body.String())
+		})
+	}
+}
+
+func TestCORSAnyOrigin(t *testing.T) {
+	router := corsRouter(t, httputils.CORSConfiguration{
+		AllowedOrigins: []string{"*"},
+		AllowedHeaders: []string{"*"},
+	})
+
+	recorder := corsRequest(router, http.MethodOptions, "https://example.com",
+		preflightHeader(http.MethodGet, "X-Anything"))
+	assert.Equal(t, http.StatusNoContent, recorder.Code)
+	assert.Equal(t, "*", recorder.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "X-Anything", recorder.Header().Get("Access-Control-Allow-Headers"))
+	assert.Empty(t, recorder.Header().Get("Access-Control-Allow-Credentials"))
+	assert.Empty(t, recorder.Header().Get("Access-Control-Max-Age"))
+
+	// credentials can't be allowed for any origin
+	_, err := httputils.CORS(httputils.CORSConfiguration{AllowedOrigins: []string{"*"}, AllowCredentials: true})
+	assert.Error(t, err)
+	err = httputils.RegisterCORS(mux.NewRouter(), httputils.CORSConfiguration{
+		AllowedOrigins:   []string{"https://console.redhat.com", "*"},
+		AllowCredentials: true,
+	})
+	assert.Error(t, err)
+}
+
+func TestCORSNullOrigin(t *testing.T) {
+	for _, configuration := range []httputils.CORSConfiguration{
+		{AllowedOrigins: []string{"*"}},
+		{AllowedOrigins: []string{"null"}, AllowCredentials: true},
+	} {
+		router := corsRouter(t, configuration)
+
+		recorder := corsRequest(router, http.MethodGet, "null", nil)
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.Empty(t, recorder.Header().Get("Access-Control-Allow-Origin"))
+
+		recorder = corsRequest(router, http.MethodOptions, "null", preflightHeader(http.MethodGet, ""))
+		assert.Equal(t, http.StatusForbidden, recorder.Code)
+	}
+}
+
+func TestCORSWrappingRouter(t *testing.T) {
+	router := mux.NewRouter()
+	router.HandleFunc("/api/v1/report", func(http.ResponseWriter, *http.Request) {}).Methods(http.MethodGet)
+	middleware, err := httputils.CORS(corsConfiguration)
+	helpers.FailOnError(t, err)
+	handler := middleware(router)
+
+	recorder := corsRequest(handler, http.MethodOptions, "https://console.redhat.com",
+		preflightHeader(http.MethodGet, ""))
+	assert.Equal(t, http.StatusNoContent, recorder.Code)
+	assert.Equal(t, "https://console.redhat.com", recorder.Header().Get("Access-Control-Allow-Origin"))
+}
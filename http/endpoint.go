@@ -0,0 +1,254 @@
+// Copyright 2026 Red Hat, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httputils
+
+// Documentation in literate-programming-style is available at:
+// https://redhatinsights.github.io/insights-operator-utils/packages/http/endpoint.html
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// Endpoint describes a REST API endpoint by its method and path template.
+// The same definition can be used to register the route on the server and
+// to build URLs and requests on the client side. Template uses gorilla/mux
+// syntax, parameters are enclosed in braces and can be restricted by a
+// regular expression, e.g. "clusters/{cluster}/rules/{rule_id:[a-z_.]+}".
+// Values of parameters can't contain "/": gorilla/mux matches routes against
+// the decoded path unless the router uses UseEncodedPath, so an escaped
+// slash would split the value into more path segments.
+type Endpoint struct {
+	method   string
+	template string
+	segments []endpointSegment
+	params   []string
+}
+
+// endpointSegment is either literal part of the template or a parameter
+type endpointSegment struct {
+	literal string
+	param   string
+	pattern *regexp.Regexp
+}
+
+// NewEndpoint parses the template of the endpoint. Error is returned for
+// unbalanced braces, empty or duplicate parameter names and invalid
+// patterns.
+func NewEndpoint(method, template string) (Endpoint, error) {
+	endpoint := Endpoint{method: method, template: strings.TrimLeft(template, "/")}
+
+	rest := endpoint.template
+	for rest != "" {
+		start := strings.IndexByte(rest, '{')
+		if start < 0 {
+			if strings.IndexByte(rest, '}') >= 0 {
+				return Endpoint{}, fmt.Errorf("unbalanced braces in endpoint template '%s'", template)
+			}
+			endpoint.segments = append(endpoint.segments, endpointSegment{literal: rest})
+			break
+		}
+		if start > 0 {
+			endpoint.segments = append(endpoint.segments, endpointSegment{literal: rest[:start]})
+		}
+
+		end := matchingBrace(rest, start)
+		if end < 0 {
+			return Endpoint{}, fmt.Errorf("unbalanced braces in endpoint template '%s'", template)
+		}
+		segment, err := parseEndpointParam(rest[start+1 : end])
+		if err != nil {
+			return Endpoint{}, fmt.Errorf("invalid endpoint template '%s': %w", template, err)
+		}
+		for _, param := range endpoint.params {
+			if param == segment.param {
+				return Endpoint{}, fmt.Errorf("duplicate parameter '%s' in endpoint template '%s'", param, template)
+			}
+		}
+		endpoint.segments = append(endpoint.segments, segment)
+		endpoint.params = append(endpoint.params, segment.param)
+		rest = rest[end+1:]
+	}
+
+	return endpoint, nil
+}
+
+// MustNewEndpoint is like NewEndpoint, but it panics when the template is
+// invalid. It's intended for endpoints defined in package variables.
+func MustNewEndpoint(method, template string) Endpoint {
+	endpoint, err := NewEndpoint(method, template)
+	if err != nil {
+		panic(err)
+	}
+	return endpoint
+}
+
+// matchingBrace returns index of the brace closing the one at the start
+// index, braces in patterns like {id:[0-9]{3}} are counted
+func matchingBrace(template string, start int) int {
+	level := 0
+	for i := start; i < len(template); i++ {
+		switch template[i] {
+		case '{':
+			level++
+		case '}':
+			level--
+			if level == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+func parseEndpointParam(definition string) (endpointSegment, error) {
+	name, pattern, hasPattern := strings.Cut(definition, ":")
+	if name == "" {
+		return endpointSegment{}, fmt.Errorf("empty parameter name")
+	}
+	segment := endpointSegment{param: name}
+	if hasPattern {
+		compiled, err := regexp.Compile("^(?:" + pattern + ")$")
+		if err != nil {
+			return endpointSegment{}, fmt.Errorf("invalid pattern of parameter '%s': %w", name, err)
+		}
+		segment.pattern = compiled
+	}
+	return segment, nil
+}
+
+// Method returns HTTP method of the endpoint
+func (endpoint Endpoint) Method() string {
+	return endpoint.method
+}
+
+// Template returns path template of the endpoint without leading slash
+func (endpoint Endpoint) Template() string {
+	return endpoint.template
+}
+
+// Params returns names of the parameters in the order of the template
+func (endpoint Endpoint) Params() []string {
+	return append([]string(nil), endpoint.params...)
+}
+
+// Path returns the template prefixed by the API prefix, as expected by
+// gorilla/mux
+func (endpoint Endpoint) Path(apiPrefix string) string {
+	return apiPath(apiPrefix, endpoint.template)
+}
+
+// Register registers the handler for the endpoint in the router
+func (endpoint Endpoint) Register(router *mux.Router, apiPrefix string, handler http.HandlerFunc) *mux.Route {
+	return router.HandleFunc(endpoint.Path(apiPrefix), handler).Methods(endpoint.method)
+}
+
+// URL builds URL of the endpoint with the arguments substituted for the
+// parameters in the order of the template. The arguments are formatted by
+// fmt.Sprint and escaped. Error is returned when the number of arguments
+// doesn't match the number of parameters, when an argument is empty, ".",
+// "..", contains "/" or doesn't match the pattern of its parameter. The API
+// prefix can be either path or absolute URL.
+func (endpoint Endpoint) URL(apiPrefix string, args ...interface{}) (string, error) {
+	return endpoint.URLWithQuery(apiPrefix, nil, args...)
+}
+
+// URLWithQuery is like URL, but it adds the encoded query
+func (endpoint Endpoint) URLWithQuery(apiPrefix string, query url.Values, args ...interface{}) (string, error) {
+	if len(args) != len(endpoint.params) {
+		return "", fmt.Errorf("endpoint '%s' expects %d parameters, %d provided",
+			endpoint.template, len(endpoint.params), len(args))
+	}
+
+	values := make(map[string]interface{}, len(args))
+	for i, param := range endpoint.params {
+		values[param] = args[i]
+	}
+	return endpoint.build(apiPrefix, query, values)
+}
+
+// URLFromMap is like URL, but the arguments are provided by parameter
+// names. Error is returned when any parameter is missing or when an
+// unknown parameter is provided.
+func (endpoint Endpoint) URLFromMap(apiPrefix string, args map[string]interface{}) (string, error) {
+	for name := range args {
+		if !endpoint.hasParam(name) {
+			return "", fmt.Errorf("endpoint '%s' has no parameter '%s'", endpoint.template, name)
+		}
+	}
+	for _, param := range endpoint.params {
+		if _, found := args[param]; !found {
+			return "", fmt.Errorf("parameter '%s' of endpoint '%s' is not provided", param, endpoint.template)
+		}
+	}
+	return endpoint.build(apiPrefix, nil, args)
+}
+
+// NewRequest creates request to the endpoint, e.g. to be sent by Client.Do.
+// The base URL is the absolute URL of the API including its prefix.
+func (endpoint Endpoint) NewRequest(
+	ctx context.Context, baseURL string, query url.Values, body io.Reader, args ...interface{},
+) (*http.Request, error) {
+	endpointURL, err := endpoint.URLWithQuery(baseURL, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	return http.NewRequestWithContext(ctx, endpoint.method, endpointURL, body)
+}
+
+func (endpoint Endpoint) hasParam(name string) bool {
+	for _, param := range endpoint.params {
+		if param == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (endpoint Endpoint) build(apiPrefix string, query url.Values, args map[string]interface{}) (string, error) {
+	var path strings.Builder
+	for _, segment := range endpoint.segments {
+		if segment.param == "" {
+			path.WriteString(segment.literal)
+			continue
+		}
+		value := fmt.Sprint(args[segment.param])
+		if value == "" || value == "." || value == ".." {
+			// such segments would be removed or resolved by path cleaning
+			return "", fmt.Errorf("value '%s' of parameter '%s' isn't valid path segment", value, segment.param)
+		}
+		if strings.Contains(value, "/") {
+			return "", fmt.Errorf("value '%s' of parameter '%s' can't contain '/'", value, segment.param)
+		}
+		if segment.pattern != nil && !segment.pattern.MatchString(value) {
+			return "", fmt.Errorf("value '%s' of parameter '%s' doesn't match pattern %s",
+				value, segment.param, segment.pattern)
+		}
+		path.WriteString(url.PathEscape(value))
+	}
+
+	result := apiPath(apiPrefix, path.String())
+	if len(query) > 0 {
+		result += "?" + query.Encode()
+	}
+	return result, nil
+}
@@ -0,0 +1,181 @@
+// Copyright 2026 Red Hat, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httputils_test
+
+// Documentation in literate-programming-style is available at:
+// https://redhatinsights.github.io/insights-operator-utils/packages/http/endpoint_test.html
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+
+	httputils "github.com/RedHatInsights/insights-operator-utils/http"
+	"github.com/RedHatInsights/insights-operator-utils/tests/helpers"
+)
+
+var ruleEndpoint = httputils.MustNewEndpoint(
+	http.MethodGet, "/clusters/{cluster}/rules/{rule_id:[a-zA-Z_. |]+}/report",
+)
+
+func TestNewEndpoint(t *testing.T) {
+	assert.Equal(t, http.MethodGet, ruleEndpoint.Method())
+	assert.Equal(t, "clusters/{cluster}/rules/{rule_id:[a-zA-Z_. |]+}/report", ruleEndpoint.Template())
+	assert.Equal(t, []string{"cluster", "rule_id"}, ruleEndpoint.Params())
+
+	endpoint, err := httputils.NewEndpoint(http.MethodGet, "organizations/{org_id:[0-9]{1,10}}")
+	helpers.FailOnError(t, err)
+	assert.Equal(t, []string{"org_id"}, endpoint.Params())
+}
+
+func TestNewEndpointInvalidTemplate(t *testing.T) {
+	for _, template := range []string{
+		"clusters/{cluster",
+		"clusters/cluster}",
+		"clusters/{}",
+		"clusters/{:[0-9]+}",
+		"clusters/{cluster:[0-9}",
+		"clusters/{cluster}/{cluster}",
+	} {
+		_, err := httputils.NewEndpoint(http.MethodGet, template)
+		assert.Error(t, err, template)
+	}
+
+	assert.Panics(t, func() { httputils.MustNewEndpoint(http.MethodGet, "{") })
+}
+
+func TestEndpointURL(t *testing.T) {
+	endpointURL, err := ruleEndpoint.URL("/api/v1/", cluster1ID, "ccx_rules.node|NODE ERROR")
+	helpers.FailOnError(t, err)
+	assert.Equal(t, "/api/v1/clusters/"+cluster1ID+"/rules/ccx_rules.node%7CNODE%20ERROR/report", endpointURL)
+
+	endpointURL, err = ruleEndpoint.URLWithQuery("http://localhost:8080/api/v1",
+		url.Values{"lang": {"en & cs"}}, cluster1ID, "rule")
+	helpers.FailOnError(t, err)
+	assert.Equal(t, "http://localhost:8080/api/v1/clusters/"+cluster1ID+"/rules/rule/report?lang=en+%26+cs", endpointURL)
+
+	endpointURL, err = ruleEndpoint.URLFromMap("", map[string]interface{}{"cluster": "a?b", "rule_id": "rule"})
+	helpers.FailOnError(t, err)
+	assert.Equal(t, "/clusters/a%3Fb/rules/rule/report", endpointURL)
+}
+
+func TestEndpointURLInvalidArguments(t *testing.T) {
+	_, err := ruleEndpoint.URL("", cluster1ID)
+	assert.EqualError(t, err, "endpoint 'clusters/{cluster}/rules/{rule_id:[a-zA-Z_. |]+}/report' expects 2 parameters, 1 provided")
+
+	_, err = ruleEndpoint.URL("", cluster1ID, "rule", "extra")
+	assert.Error(t, err)
+
+	_, err = ruleEndpoint.URL("", cluster1ID, "rule-1")
+	assert.EqualError(t, err, "value 'rule-1' of parameter 'rule_id' doesn't match pattern ^(?:[a-zA-Z_. |]+)$")
+
+	_, err = ruleEndpoint.URLFromMap("", map[string]interface{}{"cluster": "a/b", "rule_id": "rule"})
+	assert.EqualError(t, err, "value 'a/b' of parameter 'cluster' can't contain '/'")
+
+	// empty and dot segments would be changed by path cleaning
+	for _, value := range []string{"", ".", ".."} {
+		_, err = ruleEndpoint.URL("", value, "rule")
+		assert.EqualError(t, err, "value '"+value+"' of parameter 'cluster' isn't valid path segment")
+	}
+
+	// even though the pattern matches
+	_, err = ruleEndpoint.URL("", cluster1ID, "..")
+	assert.EqualError(t, err, "value '..' of parameter 'rule_id' isn't valid path segment")
+
+	_, err = ruleEndpoint.URLFromMap("", map[string]interface{}{"cluster": cluster1ID})
+	assert.Error(t, err)
+
+	_, err = ruleEndpoint.URLFromMap("", map[string]interface{}{"cluster": cluster1ID, "rule_id": "rule", "org": 1})
+	assert.Error(t, err)
+}
+
+// TestEndpointRoundTrip checks that URLs built by the endpoint are matched by
+// the route registered for the same endpoint and that the handler gets the
+// original arguments
+func TestEndpointRoundTrip(t *testing.T) {
+	for _, args := range [][]interface{}{
+		{cluster1ID, "ccx_rules_ocp.external.rules.nodes_kubelet_version_check"},
+		{"cluster with spaces", "rule|ERROR KEY"},
+		{"ěščř", "rule"},
+		{"a?b#c%2F", "rule"},
+	} {
+		var vars map[string]string
+		router := mux.NewRouter()
+		ruleEndpoint.Register(router, "/api/v1/", func(writer http.ResponseWriter, request *http.Request) {
+			vars = mux.Vars(request)
+		})
+
+		endpointURL, err := ruleEndpoint.URL("/api/v1/", args...)
+		helpers.FailOnError(t, err)
+		request := httptest.NewRequest(ruleEndpoint.Method(), endpointURL, http.NoBody)
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+
+		assert.Equal(t, http.StatusOK, recorder.Code, endpointURL)
+		assert.Equal(t, map[string]string{"cluster": args[0].(string), "rule_id": args[1].(string)}, vars)
+	}
+}
+
+// TestEndpointRoundTripEscapedSlash shows why values with "/" are refused:
+// the router matches the decoded path, so the value is split
+func TestEndpointRoundTripEscapedSlash(t *testing.T) {
+	router := mux.NewRouter()
+	ruleEndpoint.Register(router, "", func(http.ResponseWriter, *http.Request) {})
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/clusters/a%2Fb/rules/rule/report", http.NoBody))
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+
+	_, err := ruleEndpoint.URL("", "a/b", "rule")
+	assert.Error(t, err)
+}
+
+func TestEndpointRoundTripMethodMismatch(t *testing.T) {
+	router := mux.NewRouter()
+	ruleEndpoint.Register(router, "", func(http.ResponseWriter, *http.Request) {})
+
+	endpointURL, err := ruleEndpoint.URL("", cluster1ID, "rule")
+	helpers.FailOnError(t, err)
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, endpointURL, http.NoBody))
+	assert.Equal(t, http.StatusMethodNotAllowed, recorder.Code)
+}
+
+func TestEndpointNewRequest(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		assert.Equal(t, http.MethodGet, request.Method)
+		assert.Equal(t, "/api/v1/clusters/"+cluster1ID+"/rules/rule/report", request.URL.Path)
+		assert.Equal(t, "en", request.URL.Query().Get("lang"))
+		writer.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	request, err := ruleEndpoint.NewRequest(context.Background(), server.URL+"/api/v1",
+		url.Values{"lang": {"en"}}, http.NoBody, cluster1ID, "rule")
+	helpers.FailOnError(t, err)
+
+	response, err := http.DefaultClient.Do(request)
+	helpers.FailOnError(t, err)
+	defer response.Body.Close()
+	assert.Equal(t, http.StatusNoContent, response.StatusCode)
+
+	_, err = ruleEndpoint.NewRequest(context.Background(), server.URL, nil, http.NoBody, cluster1ID)
+	assert.Error(t, err)
+}
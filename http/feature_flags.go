@@ -0,0 +1,96 @@
+// Copyright 2026 Red Hat, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httputils
+
+// Documentation in literate-programming-style is available at:
+// https://redhatinsights.github.io/insights-operator-utils/packages/http/feature_flags.html
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/rs/zerolog/log"
+
+	"github.com/RedHatInsights/insights-operator-utils/featureflags"
+	"github.com/RedHatInsights/insights-operator-utils/responses"
+	"github.com/RedHatInsights/insights-operator-utils/types"
+)
+
+// FeatureDisabledResponse selects the response to requests of features
+// disabled for the organization
+type FeatureDisabledResponse int
+
+const (
+	// FeatureDisabledNoContent responds with 204 No Content
+	FeatureDisabledNoContent FeatureDisabledResponse = iota
+	// FeatureDisabledNotFound responds with 404 Not Found as if the
+	// endpoint didn't exist
+	FeatureDisabledNotFound
+)
+
+// IsFeatureEnabled checks whether the feature is enabled for the organization
+// in the request identity stored by Authenticate middleware. Requests without
+// identity get only features enabled for all organizations. Errors of the
+// provider are logged and the feature is considered disabled.
+func IsFeatureEnabled(request *http.Request, provider featureflags.Provider, feature string) bool {
+	identity, _ := GetIdentity(request)
+
+	enabled, err := provider.IsEnabled(request.Context(), feature, identity.OrgID)
+	if err != nil {
+		GetLogger(request.Context()).Error().Err(err).Str("feature", feature).Msg("Unable to check feature flag")
+		return false
+	}
+	return enabled
+}
+
+// CheckFeatureEnabled is like IsFeatureEnabled, but when the feature is
+// disabled the response selected by disabledResponse is sent. The handler
+// needs to return when false is returned.
+func CheckFeatureEnabled(
+	writer http.ResponseWriter, request *http.Request,
+	provider featureflags.Provider, feature string, disabledResponse FeatureDisabledResponse,
+) bool {
+	if IsFeatureEnabled(request, provider, feature) {
+		return true
+	}
+
+	if disabledResponse == FeatureDisabledNotFound {
+		if err := responses.SendNotFound(writer, http.StatusText(http.StatusNotFound)); err != nil {
+			log.Error().Err(err).Msg("error writing response")
+		}
+		return false
+	}
+	types.HandleServerError(writer, &types.NoContentError{
+		ErrString: fmt.Sprintf("feature %s is disabled for the organization", feature),
+	})
+	return false
+}
+
+// FeatureFlag creates a middleware that lets requests through only when the
+// feature is enabled for the organization, see CheckFeatureEnabled. The
+// identity is read from the request context where it is stored by
+// Authenticate middleware, so FeatureFlag needs to be placed after it.
+func FeatureFlag(
+	provider featureflags.Provider, feature string, disabledResponse FeatureDisabledResponse,
+) mux.MiddlewareFunc {
+	return func(nextHandler http.Handler) http.Handler {
+		return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			if CheckFeatureEnabled(writer, request, provider, feature, disabledResponse) {
+				nextHandler.ServeHTTP(writer, request)
+			}
+		})
+	}
+}
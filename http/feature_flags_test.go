@@ -0,0 +1,103 @@
+// Copyright 2026 Red Hat, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httputils_test
+
+// Documentation in literate-programming-style is available at:
+// https://redhatinsights.github.io/insights-operator-utils/packages/http/feature_flags_test.html
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	ctypes "github.com/RedHatInsights/insights-results-types"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/RedHatInsights/insights-operator-utils/featureflags"
+	httputils "github.com/RedHatInsights/insights-operator-utils/http"
+)
+
+type failingFeatureProvider struct{}
+
+func (failingFeatureProvider) IsEnabled(context.Context, string, ctypes.OrgID) (bool, error) {
+	return false, errors.New("feature flags service is down")
+}
+
+var featureProvider = featureflags.NewStaticProvider(map[string]featureflags.FeatureConfiguration{
+	"new-report": {Orgs: []ctypes.OrgID{1}},
+})
+
+func serveFeature(
+	provider featureflags.Provider, disabledResponse httputils.FeatureDisabledResponse, request *http.Request,
+) *httptest.ResponseRecorder {
+	handler := httputils.FeatureFlag(provider, "new-report", disabledResponse)(
+		http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+			writer.WriteHeader(http.StatusOK)
+		}),
+	)
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func TestFeatureFlagEnabled(t *testing.T) {
+	recorder := serveFeature(featureProvider, httputils.FeatureDisabledNoContent, orgRequest(1))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+}
+
+func TestFeatureFlagDisabledNoContent(t *testing.T) {
+	recorder := serveFeature(featureProvider, httputils.FeatureDisabledNoContent, orgRequest(2))
+	assert.Equal(t, http.StatusNoContent, recorder.Code)
+	assert.Empty(t, recorder.Body.String())
+}
+
+func TestFeatureFlagDisabledNotFound(t *testing.T) {
+	recorder := serveFeature(featureProvider, httputils.FeatureDisabledNotFound, orgRequest(2))
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+	assert.JSONEq(t, `{"status":"Not Found"}`, recorder.Body.String())
+}
+
+func TestFeatureFlagWithoutIdentity(t *testing.T) {
+	request := httptest.NewRequest(http.MethodGet, "/report", http.NoBody)
+	recorder := serveFeature(featureProvider, httputils.FeatureDisabledNotFound, request)
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+}
+
+func TestFeatureFlagProviderError(t *testing.T) {
+	recorder := serveFeature(failingFeatureProvider{}, httputils.FeatureDisabledNoContent, orgRequest(1))
+	assert.Equal(t, http.StatusNoContent, recorder.Code)
+}
+
+func TestCheckFeatureEnabledInHandler(t *testing.T) {
+	handler := func(writer http.ResponseWriter, request *http.Request) {
+		if !httputils.CheckFeatureEnabled(writer, request, featureProvider, "new-report", httputils.FeatureDisabledNotFound) {
+			return
+		}
+		writer.WriteHeader(http.StatusAccepted)
+	}
+
+	recorder := httptest.NewRecorder()
+	handler(recorder, orgRequest(1))
+	assert.Equal(t, http.StatusAccepted, recorder.Code)
+
+	recorder = httptest.NewRecorder()
+	handler(recorder, orgRequest(3))
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+
+	assert.True(t, httputils.IsFeatureEnabled(orgRequest(1), featureProvider, "new-report"))
+	assert.False(t, httputils.IsFeatureEnabled(orgRequest(1), featureProvider, "unknown"))
+}
@@ -28,7 +28,9 @@ import (
 	"time"
 )
 
-// MakeURLToEndpoint creates URL to endpoint, use constants from file endpoints.go
+// MakeURLToEndpoint creates URL to endpoint, use constants from file endpoints.go.
+// Endpoint should be preferred in new code as it escapes and validates the
+// arguments.
 func MakeURLToEndpoint(apiPrefix, endpoint string, args ...interface{}) string {
 	endpoint = ReplaceParamsInEndpointAndTrimLeftSlash(endpoint, "%v")
 
@@ -94,8 +96,16 @@ func SetHTTPPrefix(originalURL string) string {
 	return originalURL
 }
 
-// SendRequest sends the given request, reads the body and handles related errors
+// SendRequest sends the given request, reads the body and handles related
+// errors. ID of the request stored in the request context by RequestID
+// middleware is forwarded in X-Request-Id header. Client should be preferred
+// in new code as it checks the status code, limits the body size and retries
+// failed requests.
 func SendRequest(req *http.Request, timeout time.Duration) ([]byte, error) {
+	if requestID := GetRequestID(req.Context()); requestID != "" && req.Header.Get(RequestIDHeader) == "" {
+		req.Header.Set(RequestIDHeader, requestID)
+	}
+
 	client := &http.Client{
 		Timeout: timeout,
 	}
@@ -23,53 +23,51 @@ import (
 	"time"
 
 	"github.com/gorilla/mux"
-	"github.com/prometheus/client_golang/prometheus"
 	"github.com/rs/zerolog/log"
 
 	"github.com/RedHatInsights/insights-operator-utils/metrics"
 )
 
-const endpointLabel = "endpoint"
-
-type loggingResponseWriter struct {
-	http.ResponseWriter
-	endpoint string
-}
-
-func (writer loggingResponseWriter) WriteHeader(statusCode int) {
-	writer.ResponseWriter.WriteHeader(statusCode)
-	metrics.APIResponseStatusCodes.With(
-		prometheus.Labels{
-			"status_code": fmt.Sprint(statusCode),
-			endpointLabel: writer.endpoint},
-	).Inc()
-}
-
 func logRequestHandler(writer http.ResponseWriter, request *http.Request, nextHandler http.Handler) {
 	log.Info().Msgf("Request received - URI: %s, Method: %s", request.RequestURI, request.Method)
 
-	route := mux.CurrentRoute(request)
-	endpoint, err := route.GetPathTemplate()
-	if err != nil {
-		log.Error().Err(err)
-		endpoint = ""
+	endpoint := ""
+	if route := mux.CurrentRoute(request); route != nil {
+		template, err := route.GetPathTemplate()
+		if err != nil {
+			log.Error().Err(err).Msg("Unable to get path template of the route")
+		}
+		endpoint = template
+	}
+
+	apiRequest := &metrics.APIRequest{
+		Endpoint: endpoint,
+		Method:   request.Method,
 	}
+	metrics.ObserveAPIRequestStart(apiRequest)
 
-	metrics.APIRequests.With(prometheus.Labels{endpointLabel: endpoint}).Inc()
+	entry, request := withRequestEntry(request)
+	recorder := &responseRecorder{ResponseWriter: writer}
 
 	startTime := time.Now()
-	nextHandler.ServeHTTP(&loggingResponseWriter{
-		ResponseWriter: writer,
-		endpoint:       endpoint,
-	}, request)
-	duration := time.Since(startTime)
+	defer func() {
+		apiRequest.Duration = time.Since(startTime)
+		apiRequest.StatusCode = recorder.StatusCode()
+		apiRequest.ResponseSize = recorder.size
+		if entry.orgID != 0 {
+			apiRequest.OrgID = fmt.Sprint(entry.orgID)
+		}
+		metrics.ObserveAPIRequestEnd(apiRequest)
+	}()
 
-	metrics.APIResponsesTime.With(
-		prometheus.Labels{endpointLabel: endpoint},
-	).Observe(duration.Seconds())
+	nextHandler.ServeHTTP(recorder, request)
 }
 
-// LogRequest - middleware for logging requests
+// LogRequest - middleware for logging requests. It records the API metrics
+// (number of requests, requests in flight, response time, size and status
+// codes) labelled by path template of the matched mux route. The metrics can
+// be configured by metrics.ConfigureAPIMetrics. Organization ID is known when
+// Authenticate middleware is used after LogRequest.
 func LogRequest(nextHandler http.Handler) http.Handler {
 	return http.HandlerFunc(
 		func(writer http.ResponseWriter, request *http.Request) {
@@ -23,6 +23,7 @@ import (
 	"fmt"
 	"net"
 	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
 
@@ -46,7 +47,9 @@ const (
 
 func TestLogRequest(t *testing.T) {
 	buf := new(bytes.Buffer)
+	originalLogger := log.Logger
 	log.Logger = zerolog.New(buf).With().Timestamp().Logger()
+	t.Cleanup(func() { log.Logger = originalLogger })
 
 	server := createTestServer(t, []Endpoint{
 		{
@@ -116,3 +119,104 @@ func assertCollectorEquals(t *testing.T, c prometheus.Collector, expected string
 	err := testutil.CollectAndCompare(c, strings.NewReader(expected))
 	helpers.FailOnError(t, err)
 }
+
+func TestLogRequestImplicitStatusAndSize(t *testing.T) {
+	metrics.ConfigureAPIMetrics(metrics.APIMetricsConfiguration{MethodLabel: true})
+	defer metrics.ConfigureAPIMetrics(metrics.APIMetricsConfiguration{})
+
+	router := mux.NewRouter()
+	router.Use(httputils.LogRequest)
+	router.HandleFunc("/implicit", func(writer http.ResponseWriter, request *http.Request) {
+		_, _ = writer.Write([]byte("12345"))
+	})
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/implicit", http.NoBody))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.APIResponseStatusCodes.With(prometheus.Labels{
+		"endpoint": "/implicit", "method": http.MethodGet, "status_code": "200",
+	})))
+	assert.Equal(t, 0.0, testutil.ToFloat64(metrics.APIRequestsInFlight.With(prometheus.Labels{
+		"endpoint": "/implicit", "method": http.MethodGet,
+	})))
+
+	expected := `
+	# HELP api_endpoints_response_size_bytes API endpoints response size
+	# TYPE api_endpoints_response_size_bytes histogram
+	api_endpoints_response_size_bytes_bucket{endpoint="/implicit",method="GET",le="100"} 1
+	api_endpoints_response_size_bytes_bucket{endpoint="/implicit",method="GET",le="1000"} 1
+	api_endpoints_response_size_bytes_bucket{endpoint="/implicit",method="GET",le="10000"} 1
+	api_endpoints_response_size_bytes_bucket{endpoint="/implicit",method="GET",le="100000"} 1
+	api_endpoints_response_size_bytes_bucket{endpoint="/implicit",method="GET",le="1e+06"} 1
+	api_endpoints_response_size_bytes_bucket{endpoint="/implicit",method="GET",le="1e+07"} 1
+	api_endpoints_response_size_bytes_bucket{endpoint="/implicit",method="GET",le="+Inf"} 1
+	api_endpoints_response_size_bytes_sum{endpoint="/implicit",method="GET"} 5
+	api_endpoints_response_size_bytes_count{endpoint="/implicit",method="GET"} 1
+	`
+	assertCollectorEquals(t, metrics.APIResponseSize, expected)
+}
+
+func TestLogRequestOrgIDLabel(t *testing.T) {
+	metrics.ConfigureAPIMetrics(metrics.APIMetricsConfiguration{OrgIDLabel: true})
+	defer metrics.ConfigureAPIMetrics(metrics.APIMetricsConfiguration{})
+
+	authenticate, err := httputils.Authenticate(httputils.AuthenticationConfiguration{})
+	helpers.FailOnError(t, err)
+
+	router := mux.NewRouter()
+	router.Use(httputils.LogRequest, authenticate)
+	router.HandleFunc("/report", func(writer http.ResponseWriter, request *http.Request) {
+		writer.WriteHeader(http.StatusNoContent)
+	})
+
+	request := httptest.NewRequest(http.MethodGet, "/report", http.NoBody)
+	request.Header.Set(httputils.XRHIdentityHeader, makeIdentityToken(t, testOrgID, "User"))
+	router.ServeHTTP(httptest.NewRecorder(), request)
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/report", http.NoBody))
+
+	expected := `
+	# HELP api_endpoints_status_codes API endpoints status codes
+	# TYPE api_endpoints_status_codes counter
+	api_endpoints_status_codes{endpoint="/report", org_id="42", status_code="204"} 1
+	api_endpoints_status_codes{endpoint="/report", org_id="unknown", status_code="401"} 1
+	`
+	assertCollectorEquals(t, metrics.APIResponseStatusCodes, expected)
+}
+
+func TestLogRequestKeepsFlusherAndHijacker(t *testing.T) {
+	var flushed, hijacked bool
+
+	router := mux.NewRouter()
+	router.Use(httputils.LogRequest)
+	router.HandleFunc("/stream", func(writer http.ResponseWriter, request *http.Request) {
+		_, _ = writer.Write([]byte("chunk"))
+		flusher, ok := writer.(http.Flusher)
+		flushed = ok
+		if ok {
+			flusher.Flush()
+		}
+	})
+	router.HandleFunc("/hijack", func(writer http.ResponseWriter, request *http.Request) {
+		conn, buffer, err := http.NewResponseController(writer).Hijack()
+		helpers.FailOnError(t, err)
+		hijacked = true
+		_, _ = buffer.WriteString("HTTP/1.1 204 No Content\r\n\r\n")
+		_ = buffer.Flush()
+		_ = conn.Close()
+	})
+
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	response, err := http.Get(server.URL + "/stream")
+	helpers.FailOnError(t, err)
+	_ = response.Body.Close()
+	assert.True(t, flushed)
+
+	response, err = http.Get(server.URL + "/hijack")
+	helpers.FailOnError(t, err)
+	_ = response.Body.Close()
+	assert.True(t, hijacked)
+	assert.Equal(t, http.StatusNoContent, response.StatusCode)
+}
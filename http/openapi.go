@@ -20,7 +20,9 @@ package httputils
 import (
 	"net/http"
 	"os"
+	"slices"
 	"strings"
+	"sync"
 
 	"github.com/getkin/kin-openapi/openapi3"
 	"github.com/rs/zerolog/log"
@@ -30,24 +32,46 @@ import (
 
 // FilterOutDebugMethods returns the same openapi spec, but without endpoints tagged as debug.
 func FilterOutDebugMethods(openAPIFileContent string) (string, error) {
+	return FilterOutTaggedMethods(openAPIFileContent, []string{"debug"})
+}
+
+// FilterOutTaggedMethods returns the same openapi spec, but without
+// endpoints tagged by any of the tags (e.g. debug, internal or experimental).
+// Tags are compared case insensitively.
+func FilterOutTaggedMethods(openAPIFileContent string, tags []string) (string, error) {
 	swagger, err := openapi3.NewLoader().LoadFromData([]byte(openAPIFileContent))
 	if err != nil {
 		return "", err
 	}
 
+	removeTaggedOperations(swagger, tags)
+
+	openAPIBytes, err := swagger.MarshalJSON()
+	return string(openAPIBytes), err
+}
+
+// removeTaggedOperations removes operations tagged by any of the tags, paths
+// without operations are removed too
+func removeTaggedOperations(swagger *openapi3.T, tags []string) {
+	if len(tags) == 0 {
+		return
+	}
+
 	newPaths := openapi3.NewPaths()
 
 	for path, pathItem := range swagger.Paths.Map() {
 		for method, operation := range pathItem.Operations() {
-			debugTagFound := false
+			excludedTagFound := false
 			for _, tag := range operation.Tags {
-				if strings.EqualFold(strings.TrimSpace(tag), "debug") {
-					debugTagFound = true
+				if slices.ContainsFunc(tags, func(excluded string) bool {
+					return strings.EqualFold(strings.TrimSpace(tag), excluded)
+				}) {
+					excludedTagFound = true
 					break
 				}
 			}
 
-			if debugTagFound {
+			if excludedTagFound {
 				pathItem.SetOperation(method, nil)
 			}
 		}
@@ -58,42 +82,57 @@ func FilterOutDebugMethods(openAPIFileContent string) (string, error) {
 	}
 
 	swagger.Paths = newPaths
-
-	openAPIBytes, err := swagger.MarshalJSON()
-	return string(openAPIBytes), err
 }
 
 // CreateOpenAPIHandler creates a handler for a server to send OpenAPI file.
 // Optionally, you can turn on or off debug to filter out debug endpoints.
 // Optionally, you can turn on caching by setting cacheFile to true,
-// then you will have to restart a server on each file change
+// then you will have to restart a server on each file change. Use
+// OpenAPIHandler to serve specification assembled from more files.
 func CreateOpenAPIHandler(filePath string, debug, cacheFile bool) func(writer http.ResponseWriter, request *http.Request) {
-	var fileContent []byte
+	var (
+		mutex       sync.Mutex
+		fileContent []byte
+	)
 
-	return func(writer http.ResponseWriter, request *http.Request) {
-		writer.Header().Set("Content-Type", "application/json")
+	readFileContent := func() ([]byte, error) {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		if cacheFile && len(fileContent) != 0 {
+			return fileContent, nil
+		}
+
+		// it's not supposed that we'll accept the path from a user
+		content, err := os.ReadFile(filePath) // #nosec G304  (CWE-22): Potential file inclusion via variable
+		if err != nil {
+			return nil, err
+		}
 
-		if !cacheFile || len(fileContent) == 0 {
-			var err error
-			// it's not supposed that we'll accept the path from a user
-			fileContent, err = os.ReadFile(filePath) // #nosec G304  (CWE-22): Potential file inclusion via variable
+		if !debug {
+			filteredFileContent, err := FilterOutDebugMethods(string(content))
 			if err != nil {
-				log.Error().Err(err).Msg("error reading openapi.json file")
-				types.HandleServerError(writer, err)
-				return
+				log.Error().Err(err).Msg("error filtering openapi.json file, using the original version")
+			} else {
+				content = []byte(filteredFileContent)
 			}
+		}
 
-			if !debug {
-				filteredFileContent, err := FilterOutDebugMethods(string(fileContent))
-				if err != nil {
-					log.Error().Err(err).Msg("error filtering openapi.json file, using the original version")
-				} else {
-					fileContent = []byte(filteredFileContent)
-				}
-			}
+		fileContent = content
+		return content, nil
+	}
+
+	return func(writer http.ResponseWriter, request *http.Request) {
+		writer.Header().Set("Content-Type", "application/json")
+
+		content, err := readFileContent()
+		if err != nil {
+			log.Error().Err(err).Msg("error reading openapi.json file")
+			types.HandleServerError(writer, err)
+			return
 		}
 
-		_, err := writer.Write(fileContent)
+		_, err = writer.Write(content)
 		if err != nil {
 			log.Error().Err(err).Msg("error writing openapi.json file")
 			types.HandleServerError(writer, err)
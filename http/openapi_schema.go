@@ -0,0 +1,142 @@
+// Copyright 2026 Red Hat, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httputils
+
+// Documentation in literate-programming-style is available at:
+// https://redhatinsights.github.io/insights-operator-utils/packages/http/openapi_schema.html
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+
+	"github.com/RedHatInsights/insights-operator-utils/responses"
+)
+
+// CheckResponseSchema checks that the schema of a Go type, generated by
+// responses.SchemaOf or responses.SchemaFor, matches the JSON schema of the
+// response in OpenAPI specification. Properties missing on either side,
+// different types and properties required by the specification which might
+// be omitted are reported. Parts of the specification using allOf, anyOf
+// or oneOf and properties of objects without any properties defined in the
+// specification are not compared.
+func CheckResponseSchema(
+	openAPIFileContent, method, path string, statusCode int, schema responses.Schema,
+) error {
+	spec, err := openapi3.NewLoader().LoadFromData([]byte(openAPIFileContent))
+	if err != nil {
+		return err
+	}
+
+	pathItem := spec.Paths.Find(path)
+	if pathItem == nil {
+		return fmt.Errorf("path %s is not defined in OpenAPI specification", path)
+	}
+	operation := pathItem.GetOperation(strings.ToUpper(method))
+	if operation == nil {
+		return fmt.Errorf("operation %s %s is not defined in OpenAPI specification", method, path)
+	}
+	response := operation.Responses.Status(statusCode)
+	if response == nil || response.Value == nil {
+		return fmt.Errorf("response %d of %s %s is not defined in OpenAPI specification", statusCode, method, path)
+	}
+	mediaType := response.Value.Content.Get(JSONContentType)
+	if mediaType == nil || mediaType.Schema == nil {
+		return fmt.Errorf("response %d of %s %s has no JSON schema", statusCode, method, path)
+	}
+
+	// the generated schema is converted to the same representation
+	encoded, err := json.Marshal(schema)
+	if err != nil {
+		return err
+	}
+	var generated openapi3.Schema
+	if err := json.Unmarshal(encoded, &generated); err != nil {
+		return err
+	}
+
+	differences := compareSchemas("$", &generated, mediaType.Schema.Value, nil)
+	if len(differences) > 0 {
+		return fmt.Errorf("schema of response %d of %s %s does not match: %s",
+			statusCode, method, path, strings.Join(differences, "; "))
+	}
+	return nil
+}
+
+func compareSchemas(location string, generated, spec *openapi3.Schema, differences []string) []string {
+	if generated == nil || spec == nil || generated.Type.IsEmpty() || spec.Type.IsEmpty() ||
+		len(spec.AllOf) > 0 || len(spec.AnyOf) > 0 || len(spec.OneOf) > 0 {
+		return differences
+	}
+
+	generatedType, specType := generated.Type.Slice()[0], spec.Type.Slice()[0]
+	if generatedType != specType && !(generatedType == "integer" && specType == "number") {
+		return append(differences, fmt.Sprintf("%s is %s in type, but %s in specification",
+			location, generatedType, specType))
+	}
+
+	switch generatedType {
+	case "array":
+		if generated.Items != nil && spec.Items != nil {
+			differences = compareSchemas(location+"[]", generated.Items.Value, spec.Items.Value, differences)
+		}
+	case "object":
+		// objects without properties in the specification are free-form
+		if len(spec.Properties) > 0 {
+			differences = compareProperties(location, generated, spec, differences)
+		}
+		if generated.AdditionalProperties.Schema != nil && spec.AdditionalProperties.Schema != nil {
+			differences = compareSchemas(location+"{}", generated.AdditionalProperties.Schema.Value,
+				spec.AdditionalProperties.Schema.Value, differences)
+		}
+	}
+	return differences
+}
+
+func compareProperties(location string, generated, spec *openapi3.Schema, differences []string) []string {
+	names := make([]string, 0, len(generated.Properties)+len(spec.Properties))
+	for name := range generated.Properties {
+		names = append(names, name)
+	}
+	for name := range spec.Properties {
+		if _, found := generated.Properties[name]; !found {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		property := location + "." + name
+		generatedProperty, inType := generated.Properties[name]
+		specProperty, inSpec := spec.Properties[name]
+
+		switch {
+		case !inSpec:
+			differences = append(differences, property+" is not in specification")
+		case !inType:
+			differences = append(differences, property+" is not in type")
+		default:
+			if slices.Contains(spec.Required, name) && !slices.Contains(generated.Required, name) {
+				differences = append(differences, property+" is required by specification, but may be omitted")
+			}
+			differences = compareSchemas(property, generatedProperty.Value, specProperty.Value, differences)
+		}
+	}
+	return differences
+}
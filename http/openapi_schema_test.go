@@ -0,0 +1,125 @@
+// Copyright 2026 Red Hat, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httputils_test
+
+// Documentation in literate-programming-style is available at:
+// https://redhatinsights.github.io/insights-operator-utils/packages/http/openapi_schema_test.html
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	httputils "github.com/RedHatInsights/insights-operator-utils/http"
+	"github.com/RedHatInsights/insights-operator-utils/responses"
+)
+
+const clusterListSpec = `
+{
+	"openapi": "3.0.0",
+	"info": {"title": "title", "version": "1.0.0"},
+	"paths": {
+		"/clusters": {
+			"get": {
+				"responses": {
+					"200": {
+						"description": "list of clusters",
+						"content": {"application/json": {"schema": {
+							"type": "object",
+							"required": ["status", "clusters"],
+							"properties": {
+								"status": {"type": "string"},
+								"clusters": {"type": "array", "items": {"$ref": "#/components/schemas/Cluster"}},
+								"meta": {"type": "object", "properties": {
+									"count": {"type": "integer"},
+									"total": {"type": "integer"},
+									"limit": {"type": "integer"},
+									"offset": {"type": "integer"}
+								}},
+								"links": {"type": "object"}
+							}
+						}}}
+					}
+				}
+			}
+		}
+	},
+	"components": {
+		"schemas": {
+			"Cluster": {
+				"type": "object",
+				"required": ["cluster", "hits"],
+				"properties": {
+					"cluster": {"type": "string"},
+					"hits": {"type": "number"},
+					"labels": {"type": "object", "additionalProperties": {"type": "string"}}
+				}
+			}
+		}
+	}
+}`
+
+type specCluster struct {
+	Cluster string            `json:"cluster"`
+	Hits    int               `json:"hits"`
+	Labels  map[string]string `json:"labels,omitempty"`
+}
+
+type driftedCluster struct {
+	Cluster int            `json:"cluster"`
+	Hits    int            `json:"hits,omitempty"`
+	Labels  map[string]int `json:"labels"`
+	Managed bool           `json:"managed"`
+}
+
+func TestCheckResponseSchema(t *testing.T) {
+	schema := responses.SchemaOf(responses.NewEnvelope("clusters", []specCluster{}))
+
+	err := httputils.CheckResponseSchema(clusterListSpec, http.MethodGet, "/clusters", http.StatusOK, schema)
+	assert.NoError(t, err)
+}
+
+func TestCheckResponseSchemaDrift(t *testing.T) {
+	schema := responses.SchemaOf(responses.NewEnvelope("clusters", []driftedCluster{}))
+
+	err := httputils.CheckResponseSchema(clusterListSpec, http.MethodGet, "/clusters", http.StatusOK, schema)
+	assert.EqualError(t, err, "schema of response 200 of GET /clusters does not match: "+
+		"$.clusters[].cluster is integer in type, but string in specification; "+
+		"$.clusters[].hits is required by specification, but may be omitted; "+
+		"$.clusters[].labels{} is integer in type, but string in specification; "+
+		"$.clusters[].managed is not in specification")
+
+	schema = responses.SchemaOf(responses.NewEnvelope("items", []specCluster{}))
+	err = httputils.CheckResponseSchema(clusterListSpec, http.MethodGet, "/clusters", http.StatusOK, schema)
+	assert.EqualError(t, err, "schema of response 200 of GET /clusters does not match: "+
+		"$.clusters is not in type; $.items is not in specification")
+}
+
+func TestCheckResponseSchemaUndefinedResponse(t *testing.T) {
+	schema := responses.SchemaFor[specCluster]()
+
+	err := httputils.CheckResponseSchema(clusterListSpec, http.MethodGet, "/rules", http.StatusOK, schema)
+	assert.EqualError(t, err, "path /rules is not defined in OpenAPI specification")
+
+	err = httputils.CheckResponseSchema(clusterListSpec, http.MethodPost, "/clusters", http.StatusOK, schema)
+	assert.EqualError(t, err, "operation POST /clusters is not defined in OpenAPI specification")
+
+	err = httputils.CheckResponseSchema(clusterListSpec, http.MethodGet, "/clusters", http.StatusNotFound, schema)
+	assert.EqualError(t, err, "response 404 of GET /clusters is not defined in OpenAPI specification")
+
+	err = httputils.CheckResponseSchema("not-json", http.MethodGet, "/clusters", http.StatusOK, schema)
+	assert.Error(t, err)
+}
@@ -0,0 +1,286 @@
+// Copyright 2026 Red Hat, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httputils
+
+// Documentation in literate-programming-style is available at:
+// https://redhatinsights.github.io/insights-operator-utils/packages/http/openapi_spec.html
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/rs/zerolog/log"
+
+	"github.com/RedHatInsights/insights-operator-utils/responses"
+	"github.com/RedHatInsights/insights-operator-utils/types"
+)
+
+// OpenAPIConfiguration describes how OpenAPI specification is assembled and
+// served by OpenAPIHandler
+type OpenAPIConfiguration struct {
+	// Files are JSON fragments of the specification. The first one is the
+	// base document, paths, components and tags of the others are added
+	// to it.
+	Files []string `mapstructure:"files" toml:"files"`
+	// ServerURL replaces servers listed in the specification when set
+	ServerURL string `mapstructure:"server_url" toml:"server_url"`
+	// ExcludedTags lists tags of operations removed from the specification,
+	// e.g. debug, internal or experimental
+	ExcludedTags []string `mapstructure:"excluded_tags" toml:"excluded_tags"`
+	// Cache keeps the assembled specification in memory until Invalidate
+	// is called, otherwise files are read for every request
+	Cache bool `mapstructure:"cache" toml:"cache"`
+}
+
+// OpenAPIHandler serves OpenAPI specification assembled from fragments. The
+// response has ETag header and If-None-Match is honored. It's safe for
+// concurrent use.
+type OpenAPIHandler struct {
+	config  OpenAPIConfiguration
+	mutex   sync.RWMutex
+	content []byte
+	etag    string
+}
+
+// NewOpenAPIHandler creates handler serving specification described by the
+// configuration
+func NewOpenAPIHandler(config OpenAPIConfiguration) *OpenAPIHandler {
+	return &OpenAPIHandler{config: config}
+}
+
+// Invalidate drops cached specification, so it is assembled again on the
+// next request
+func (handler *OpenAPIHandler) Invalidate() {
+	handler.mutex.Lock()
+	defer handler.mutex.Unlock()
+
+	handler.content = nil
+	handler.etag = ""
+}
+
+// ServeHTTP sends the specification or 304 Not Modified if the client has
+// the current version
+func (handler *OpenAPIHandler) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	content, etag, err := handler.load()
+	if err != nil {
+		log.Error().Err(err).Msg("error assembling OpenAPI specification")
+		types.HandleServerError(writer, err)
+		return
+	}
+
+	validators := responses.Validators{ETag: etag}
+	if validators.NotModified(request) {
+		responses.SendNotModified(writer, validators)
+		return
+	}
+	validators.SetHeaders(writer)
+
+	writer.Header().Set(contentTypeHeader, JSONContentType)
+	if _, err := writer.Write(content); err != nil {
+		log.Error().Err(err).Msg("error writing OpenAPI specification")
+	}
+}
+
+// load returns the specification and its ETag, cached values are used when
+// caching is enabled
+func (handler *OpenAPIHandler) load() ([]byte, string, error) {
+	if handler.config.Cache {
+		handler.mutex.RLock()
+		content, etag := handler.content, handler.etag
+		handler.mutex.RUnlock()
+		if content != nil {
+			return content, etag, nil
+		}
+
+		handler.mutex.Lock()
+		defer handler.mutex.Unlock()
+		// other request might have assembled it in the meantime
+		if handler.content != nil {
+			return handler.content, handler.etag, nil
+		}
+	}
+
+	content, err := handler.assemble()
+	if err != nil {
+		return nil, "", err
+	}
+
+	etag := responses.StrongETag(content)
+
+	if handler.config.Cache {
+		handler.content, handler.etag = content, etag
+	}
+	return content, etag, nil
+}
+
+func (handler *OpenAPIHandler) assemble() ([]byte, error) {
+	if len(handler.config.Files) == 0 {
+		return nil, fmt.Errorf("no OpenAPI specification files are configured")
+	}
+
+	fragments := make([]string, 0, len(handler.config.Files))
+	for _, filePath := range handler.config.Files {
+		// it's not supposed that we'll accept the path from a user
+		content, err := os.ReadFile(filePath) // #nosec G304  (CWE-22): Potential file inclusion via variable
+		if err != nil {
+			return nil, err
+		}
+		fragments = append(fragments, string(content))
+	}
+
+	spec, err := ComposeOpenAPISpec(fragments...)
+	if err != nil {
+		return nil, err
+	}
+
+	removeTaggedOperations(spec, handler.config.ExcludedTags)
+
+	if handler.config.ServerURL != "" {
+		spec.Servers = openapi3.Servers{{URL: handler.config.ServerURL}}
+	}
+
+	return spec.MarshalJSON()
+}
+
+// NewVersionedOpenAPIHandler creates handler serving the specification
+// selected by API prefix of the request path, e.g. "/api/v1/" and
+// "/api/v2/". The longest matching prefix wins, 404 is sent when no prefix
+// matches.
+func NewVersionedOpenAPIHandler(versions map[string]OpenAPIConfiguration) http.Handler {
+	prefixes := make([]string, 0, len(versions))
+	handlers := make(map[string]*OpenAPIHandler, len(versions))
+	for prefix, config := range versions {
+		prefixes = append(prefixes, prefix)
+		handlers[prefix] = NewOpenAPIHandler(config)
+	}
+	sort.Slice(prefixes, func(i, j int) bool {
+		return len(prefixes[i]) > len(prefixes[j])
+	})
+
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		for _, prefix := range prefixes {
+			if strings.HasPrefix(request.URL.Path, prefix) {
+				handlers[prefix].ServeHTTP(writer, request)
+				return
+			}
+		}
+
+		err := responses.SendNotFound(writer, "OpenAPI specification for "+request.URL.Path+" not found")
+		if err != nil {
+			log.Error().Err(err).Msg("error writing response")
+		}
+	})
+}
+
+// ComposeOpenAPISpec assembles OpenAPI specification from JSON fragments.
+// The first fragment is the base document, paths, components and tags of
+// the other fragments are added to it. The same path defined in more
+// fragments and different components with the same name are reported as
+// errors. References between fragments are resolved in the result.
+func ComposeOpenAPISpec(fragments ...string) (*openapi3.T, error) {
+	if len(fragments) == 0 {
+		return nil, fmt.Errorf("no OpenAPI specification fragments")
+	}
+
+	var base map[string]interface{}
+	if err := json.Unmarshal([]byte(fragments[0]), &base); err != nil {
+		return nil, fmt.Errorf("fragment 1: %w", err)
+	}
+
+	for i, fragment := range fragments[1:] {
+		var document map[string]interface{}
+		if err := json.Unmarshal([]byte(fragment), &document); err != nil {
+			return nil, fmt.Errorf("fragment %d: %w", i+2, err)
+		}
+		if err := mergeOpenAPIFragment(base, document); err != nil {
+			return nil, fmt.Errorf("fragment %d: %w", i+2, err)
+		}
+	}
+
+	merged, err := json.Marshal(base)
+	if err != nil {
+		return nil, err
+	}
+	return openapi3.NewLoader().LoadFromData(merged)
+}
+
+func mergeOpenAPIFragment(base, fragment map[string]interface{}) error {
+	if paths, ok := fragment["paths"].(map[string]interface{}); ok {
+		basePaths := childObject(base, "paths")
+		for path, item := range paths {
+			if _, found := basePaths[path]; found {
+				return fmt.Errorf("path %s is already defined", path)
+			}
+			basePaths[path] = item
+		}
+	}
+
+	if components, ok := fragment["components"].(map[string]interface{}); ok {
+		baseComponents := childObject(base, "components")
+		for section, items := range components {
+			items, ok := items.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			baseItems := childObject(baseComponents, section)
+			for name, item := range items {
+				if existing, found := baseItems[name]; found && !reflect.DeepEqual(existing, item) {
+					return fmt.Errorf("component %s/%s is already defined differently", section, name)
+				}
+				baseItems[name] = item
+			}
+		}
+	}
+
+	if tags, ok := fragment["tags"].([]interface{}); ok {
+		baseTags, _ := base["tags"].([]interface{})
+		for _, tag := range tags {
+			if !slices.ContainsFunc(baseTags, func(baseTag interface{}) bool {
+				return tagName(baseTag) == tagName(tag)
+			}) {
+				baseTags = append(baseTags, tag)
+			}
+		}
+		base["tags"] = baseTags
+	}
+
+	return nil
+}
+
+// childObject returns JSON object stored under the key, a new one is stored
+// when there is none
+func childObject(parent map[string]interface{}, key string) map[string]interface{} {
+	child, ok := parent[key].(map[string]interface{})
+	if !ok {
+		child = map[string]interface{}{}
+		parent[key] = child
+	}
+	return child
+}
+
+func tagName(tag interface{}) interface{} {
+	if tag, ok := tag.(map[string]interface{}); ok {
+		return tag["name"]
+	}
+	return nil
+}
@@ -0,0 +1,252 @@
+// Copyright 2026 Red Hat, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httputils_test
+
+// Documentation in literate-programming-style is available at:
+// https://redhatinsights.github.io/insights-operator-utils/packages/http/openapi_spec_test.html
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	httputils "github.com/RedHatInsights/insights-operator-utils/http"
+	"github.com/RedHatInsights/insights-operator-utils/tests/helpers"
+)
+
+const (
+	baseFragment = `{
+		"openapi": "3.0.0",
+		"info": {"title": "title", "version": "1.0.0"},
+		"servers": [{"url": "https://console.redhat.com/api/v1"}],
+		"tags": [{"name": "clusters"}],
+		"paths": {
+			"/clusters": {
+				"get": {
+					"tags": ["clusters"],
+					"responses": {"200": {"description": "OK", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Clusters"}}}}}
+				}
+			}
+		},
+		"components": {"schemas": {"Clusters": {"type": "array", "items": {"type": "string"}}}}
+	}`
+
+	rulesFragment = `{
+		"tags": [{"name": "clusters"}, {"name": "rules"}],
+		"paths": {
+			"/rules": {
+				"get": {
+					"tags": ["rules"],
+					"responses": {"200": {"description": "OK", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Clusters"}}}}}
+				}
+			},
+			"/rules/internal": {
+				"get": {"tags": ["Internal"], "responses": {"200": {"description": "OK"}}}
+			},
+			"/rules/experimental": {
+				"get": {"tags": ["experimental"], "responses": {"200": {"description": "OK"}}}
+			}
+		},
+		"components": {"schemas": {"Clusters": {"type": "array", "items": {"type": "string"}}}}
+	}`
+)
+
+func writeFragments(t *testing.T, fragments ...string) []string {
+	directory := t.TempDir()
+	files := make([]string, 0, len(fragments))
+	for i, fragment := range fragments {
+		file := filepath.Join(directory, fmt.Sprintf("fragment%d.json", i))
+		helpers.FailOnError(t, os.WriteFile(file, []byte(fragment), 0o600))
+		files = append(files, file)
+	}
+	return files
+}
+
+func serveSpec(handler http.Handler, target, ifNoneMatch string) *httptest.ResponseRecorder {
+	request := httptest.NewRequest(http.MethodGet, target, http.NoBody)
+	if ifNoneMatch != "" {
+		request.Header.Set("If-None-Match", ifNoneMatch)
+	}
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func decodeSpec(t *testing.T, recorder *httptest.ResponseRecorder) map[string]interface{} {
+	var spec map[string]interface{}
+	helpers.FailOnError(t, json.Unmarshal(recorder.Body.Bytes(), &spec))
+	return spec
+}
+
+func TestComposeOpenAPISpec(t *testing.T) {
+	spec, err := httputils.ComposeOpenAPISpec(baseFragment, rulesFragment)
+	helpers.FailOnError(t, err)
+
+	assert.NotNil(t, spec.Paths.Value("/clusters"))
+	assert.NotNil(t, spec.Paths.Value("/rules"))
+	assert.Len(t, spec.Tags, 2)
+
+	// reference between fragments is resolved
+	schema := spec.Paths.Value("/rules").Get.Responses.Status(http.StatusOK).Value.Content.Get("application/json").Schema
+	assert.NotNil(t, schema.Value)
+}
+
+func TestComposeOpenAPISpecConflicts(t *testing.T) {
+	_, err := httputils.ComposeOpenAPISpec()
+	assert.Error(t, err)
+
+	_, err = httputils.ComposeOpenAPISpec(baseFragment, `{"paths": {"/clusters": {}}}`)
+	assert.EqualError(t, err, "fragment 2: path /clusters is already defined")
+
+	_, err = httputils.ComposeOpenAPISpec(baseFragment, `{"components": {"schemas": {"Clusters": {"type": "string"}}}}`)
+	assert.EqualError(t, err, "fragment 2: component schemas/Clusters is already defined differently")
+
+	_, err = httputils.ComposeOpenAPISpec(baseFragment, "not-json")
+	assert.ErrorContains(t, err, "fragment 2")
+
+	_, err = httputils.ComposeOpenAPISpec(`{"openapi": "3.0.0", "info": {"title": "t", "version": "1"}, "paths": {"/x": {"get": {
+		"responses": {"200": {"description": "OK", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Missing"}}}}}}}}}`)
+	assert.Error(t, err)
+}
+
+func TestFilterOutTaggedMethods(t *testing.T) {
+	spec, err := httputils.ComposeOpenAPISpec(baseFragment, rulesFragment)
+	helpers.FailOnError(t, err)
+	content, err := spec.MarshalJSON()
+	helpers.FailOnError(t, err)
+
+	filtered, err := httputils.FilterOutTaggedMethods(string(content), []string{"internal", "experimental"})
+	helpers.FailOnError(t, err)
+
+	var result struct {
+		Paths map[string]interface{} `json:"paths"`
+	}
+	helpers.FailOnError(t, json.Unmarshal([]byte(filtered), &result))
+	assert.Len(t, result.Paths, 2)
+	assert.Contains(t, result.Paths, "/clusters")
+	assert.Contains(t, result.Paths, "/rules")
+}
+
+func TestOpenAPIHandler(t *testing.T) {
+	handler := httputils.NewOpenAPIHandler(httputils.OpenAPIConfiguration{
+		Files:        writeFragments(t, baseFragment, rulesFragment),
+		ServerURL:    "/api/insights/v1",
+		ExcludedTags: []string{"internal", "experimental"},
+	})
+
+	recorder := serveSpec(handler, "/api/v1/openapi.json", "")
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+
+	spec := decodeSpec(t, recorder)
+	assert.Equal(t, []interface{}{map[string]interface{}{"url": "/api/insights/v1"}}, spec["servers"])
+	assert.Len(t, spec["paths"], 2)
+}
+
+func TestOpenAPIHandlerETag(t *testing.T) {
+	handler := httputils.NewOpenAPIHandler(httputils.OpenAPIConfiguration{
+		Files: writeFragments(t, baseFragment),
+		Cache: true,
+	})
+
+	recorder := serveSpec(handler, "/openapi.json", "")
+	etag := recorder.Header().Get("ETag")
+	assert.NotEmpty(t, etag)
+
+	for _, ifNoneMatch := range []string{etag, "W/" + etag, `"other", ` + etag, "*"} {
+		recorder = serveSpec(handler, "/openapi.json", ifNoneMatch)
+		assert.Equal(t, http.StatusNotModified, recorder.Code, ifNoneMatch)
+		assert.Empty(t, recorder.Body.String())
+		assert.Equal(t, etag, recorder.Header().Get("ETag"))
+	}
+
+	recorder = serveSpec(handler, "/openapi.json", `"other"`)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+}
+
+func TestOpenAPIHandlerCache(t *testing.T) {
+	files := writeFragments(t, baseFragment)
+	cached := httputils.NewOpenAPIHandler(httputils.OpenAPIConfiguration{Files: files, Cache: true})
+	uncached := httputils.NewOpenAPIHandler(httputils.OpenAPIConfiguration{Files: files})
+
+	etag := serveSpec(cached, "/openapi.json", "").Header().Get("ETag")
+	assert.Equal(t, etag, serveSpec(uncached, "/openapi.json", "").Header().Get("ETag"))
+
+	helpers.FailOnError(t, os.WriteFile(files[0], []byte(
+		`{"openapi": "3.0.0", "info": {"title": "changed", "version": "2.0.0"}, "paths": {}}`), 0o600))
+
+	assert.Equal(t, etag, serveSpec(cached, "/openapi.json", "").Header().Get("ETag"))
+	assert.NotEqual(t, etag, serveSpec(uncached, "/openapi.json", "").Header().Get("ETag"))
+
+	cached.Invalidate()
+	assert.NotEqual(t, etag, serveSpec(cached, "/openapi.json", "").Header().Get("ETag"))
+}
+
+func TestOpenAPIHandlerConcurrentRequests(t *testing.T) {
+	handler := httputils.NewOpenAPIHandler(httputils.OpenAPIConfiguration{
+		Files: writeFragments(t, baseFragment, rulesFragment),
+		Cache: true,
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if i%5 == 0 {
+				handler.Invalidate()
+			}
+			assert.Equal(t, http.StatusOK, serveSpec(handler, "/openapi.json", "").Code)
+		}()
+	}
+	wg.Wait()
+}
+
+func TestOpenAPIHandlerErrors(t *testing.T) {
+	recorder := serveSpec(httputils.NewOpenAPIHandler(httputils.OpenAPIConfiguration{}), "/openapi.json", "")
+	assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+
+	recorder = serveSpec(httputils.NewOpenAPIHandler(httputils.OpenAPIConfiguration{
+		Files: []string{filepath.Join(t.TempDir(), "missing.json")},
+	}), "/openapi.json", "")
+	assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+}
+
+func TestVersionedOpenAPIHandler(t *testing.T) {
+	v1Files := writeFragments(t, baseFragment)
+	v2Files := writeFragments(t, baseFragment, rulesFragment)
+
+	handler := httputils.NewVersionedOpenAPIHandler(map[string]httputils.OpenAPIConfiguration{
+		"/api/v1/": {Files: v1Files, ServerURL: "/api/v1"},
+		"/api/v2/": {Files: v2Files, ServerURL: "/api/v2"},
+	})
+
+	spec := decodeSpec(t, serveSpec(handler, "/api/v1/openapi.json", ""))
+	assert.Len(t, spec["paths"], 1)
+	assert.Equal(t, []interface{}{map[string]interface{}{"url": "/api/v1"}}, spec["servers"])
+
+	spec = decodeSpec(t, serveSpec(handler, "/api/v2/openapi.json", ""))
+	assert.Len(t, spec["paths"], 4)
+	assert.Equal(t, []interface{}{map[string]interface{}{"url": "/api/v2"}}, spec["servers"])
+
+	assert.Equal(t, http.StatusNotFound, serveSpec(handler, "/api/v3/openapi.json", "").Code)
+}
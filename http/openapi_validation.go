@@ -0,0 +1,227 @@
+// Copyright 2026 Red Hat, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httputils
+
+// Documentation in literate-programming-style is available at:
+// https://redhatinsights.github.io/insights-operator-utils/packages/http/openapi_validation.html
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+	"github.com/rs/zerolog/log"
+
+	"github.com/RedHatInsights/insights-operator-utils/types"
+)
+
+// ErrOperationNotInSpec is returned by OpenAPIValidator when the request
+// does not match any operation from the OpenAPI specification
+var ErrOperationNotInSpec = errors.New("operation is not defined in OpenAPI specification")
+
+// OpenAPIValidationConfiguration configures OpenAPIValidator
+type OpenAPIValidationConfiguration struct {
+	// APIPrefix is prepended to paths from the specification, the servers
+	// listed in the specification are ignored
+	APIPrefix string `mapstructure:"api_prefix" toml:"api_prefix"`
+	// ValidateResponses turns on validation of responses. It's meant for
+	// tests, responses not matching the specification are replaced by 500.
+	ValidateResponses bool `mapstructure:"validate_responses" toml:"validate_responses"`
+}
+
+// OpenAPIValidator validates requests and responses against OpenAPI
+// specification
+type OpenAPIValidator struct {
+	router            routers.Router
+	validateResponses bool
+}
+
+// NewOpenAPIValidator creates a validator for the given OpenAPI
+// specification, the specification itself is validated too
+func NewOpenAPIValidator(openAPIFileContent string, config OpenAPIValidationConfiguration) (*OpenAPIValidator, error) {
+	loader := openapi3.NewLoader()
+	spec, err := loader.LoadFromData([]byte(openAPIFileContent))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := spec.Validate(loader.Context); err != nil {
+		return nil, fmt.Errorf("invalid OpenAPI specification: %w", err)
+	}
+
+	spec.Servers = openapi3.Servers{{URL: strings.TrimSuffix(config.APIPrefix, "/")}}
+
+	router, err := gorillamux.NewRouter(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	return &OpenAPIValidator{
+		router:            router,
+		validateResponses: config.ValidateResponses,
+	}, nil
+}
+
+// ValidateRequest checks path parameters, query parameters and body of the
+// request. ErrOperationNotInSpec is returned for requests not described by
+// the specification and types.ValidationErrors for invalid requests. The
+// request body stays readable.
+func (validator *OpenAPIValidator) ValidateRequest(request *http.Request) error {
+	input, err := validator.requestInput(request)
+	if err != nil {
+		return err
+	}
+
+	if err := openapi3filter.ValidateRequest(request.Context(), input); err != nil {
+		return &types.ValidationErrors{Errors: openAPIViolations(err, nil)}
+	}
+	return nil
+}
+
+// ValidateResponse checks status code, headers and body of the response to
+// the request. Errors are the same as in ValidateRequest.
+func (validator *OpenAPIValidator) ValidateResponse(
+	request *http.Request, statusCode int, header http.Header, body []byte,
+) error {
+	input, err := validator.requestInput(request)
+	if err != nil {
+		return err
+	}
+
+	responseInput := &openapi3filter.ResponseValidationInput{
+		RequestValidationInput: input,
+		Status:                 statusCode,
+		Header:                 header,
+		Body:                   io.NopCloser(bytes.NewReader(body)),
+		Options:                input.Options,
+	}
+	if err := openapi3filter.ValidateResponse(request.Context(), responseInput); err != nil {
+		return &types.ValidationErrors{Errors: openAPIViolations(err, nil)}
+	}
+	return nil
+}
+
+// Middleware validates incoming requests, invalid requests are rejected
+// with 400 problem details listing all violations. Requests not described
+// by the specification are passed to the next handler unchanged.
+func (validator *OpenAPIValidator) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		err := validator.ValidateRequest(request)
+		if errors.Is(err, ErrOperationNotInSpec) {
+			next.ServeHTTP(writer, request)
+			return
+		}
+		if err != nil {
+			types.HandleServerProblem(writer, request, err)
+			return
+		}
+
+		if !validator.validateResponses {
+			next.ServeHTTP(writer, request)
+			return
+		}
+
+		response := newBufferedResponse()
+		next.ServeHTTP(response, request)
+
+		err = validator.ValidateResponse(request, response.StatusCode(), response.Header(), response.body.Bytes())
+		if err != nil {
+			// the violations are logged, the client gets only 500
+			types.HandleServerProblem(writer, request, fmt.Errorf("response does not match OpenAPI specification: %w", err))
+			return
+		}
+
+		if err := response.sendTo(writer); err != nil {
+			log.Error().Err(err).Msg("Unable to write response")
+		}
+	})
+}
+
+func (validator *OpenAPIValidator) requestInput(request *http.Request) (*openapi3filter.RequestValidationInput, error) {
+	route, pathParams, err := validator.router.FindRoute(request)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s %s", ErrOperationNotInSpec, request.Method, request.URL.Path)
+	}
+
+	return &openapi3filter.RequestValidationInput{
+		Request:    request,
+		PathParams: pathParams,
+		Route:      route,
+		Options: &openapi3filter.Options{
+			MultiError:            true,
+			IncludeResponseStatus: true,
+			// authentication is handled by Authenticate middleware
+			AuthenticationFunc: openapi3filter.NoopAuthenticationFunc,
+		},
+	}, nil
+}
+
+// openAPIViolations converts errors returned by openapi3filter to
+// validation errors, values are not included as they might be sensitive
+func openAPIViolations(err error, violations []*types.ValidationError) []*types.ValidationError {
+	switch err := err.(type) {
+	case openapi3.MultiError:
+		for _, item := range err {
+			violations = openAPIViolations(item, violations)
+		}
+		return violations
+	case *openapi3filter.RequestError:
+		name := "body"
+		if err.Parameter != nil {
+			name = err.Parameter.Name
+		}
+		return appendSchemaViolations(violations, name, err.Reason, err.Err)
+	case *openapi3filter.ResponseError:
+		return appendSchemaViolations(violations, "response", err.Reason, err.Err)
+	default:
+		return append(violations, &types.ValidationError{ParamName: "request", ErrString: err.Error()})
+	}
+}
+
+func appendSchemaViolations(
+	violations []*types.ValidationError, name, reason string, err error,
+) []*types.ValidationError {
+	var multiErr openapi3.MultiError
+	if errors.As(err, &multiErr) {
+		for _, item := range multiErr {
+			violations = appendSchemaViolations(violations, name, reason, item)
+		}
+		return violations
+	}
+
+	var schemaErr *openapi3.SchemaError
+	if errors.As(err, &schemaErr) {
+		if pointer := schemaErr.JSONPointer(); len(pointer) > 0 {
+			name += "/" + strings.Join(pointer, "/")
+		}
+		return append(violations, &types.ValidationError{ParamName: name, ErrString: schemaErr.Reason})
+	}
+
+	switch {
+	case err == nil:
+	case reason == "":
+		reason = err.Error()
+	default:
+		reason += ": " + err.Error()
+	}
+	return append(violations, &types.ValidationError{ParamName: name, ErrString: reason})
+}
@@ -0,0 +1,215 @@
+// Copyright 2026 Red Hat, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httputils_test
+
+// Documentation in literate-programming-style is available at:
+// https://redhatinsights.github.io/insights-operator-utils/packages/http/openapi_validation_test.html
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+
+	httputils "github.com/RedHatInsights/insights-operator-utils/http"
+	"github.com/RedHatInsights/insights-operator-utils/responses"
+	"github.com/RedHatInsights/insights-operator-utils/tests/helpers"
+	"github.com/RedHatInsights/insights-operator-utils/types"
+)
+
+const validationAPIPrefix = "/api/v1"
+
+const validationSpec = `
+{
+	"openapi": "3.0.0",
+	"info": {"title": "title", "version": "1.0.0"},
+	"servers": [{"url": "https://console.redhat.com/api/v1"}],
+	"paths": {
+		"/organizations/{org_id}/clusters": {
+			"get": {
+				"parameters": [
+					{"name": "org_id", "in": "path", "required": true, "schema": {"type": "integer", "minimum": 1}},
+					{"name": "limit", "in": "query", "schema": {"type": "integer", "minimum": 1, "maximum": 100}}
+				],
+				"responses": {
+					"200": {
+						"description": "list of clusters",
+						"content": {"application/json": {"schema": {
+							"type": "object",
+							"required": ["status", "clusters"],
+							"properties": {
+								"status": {"type": "string"},
+								"clusters": {"type": "array", "items": {"type": "string"}}
+							}
+						}}}
+					}
+				}
+			}
+		},
+		"/clusters": {
+			"post": {
+				"requestBody": {
+					"required": true,
+					"content": {"application/json": {"schema": {
+						"type": "object",
+						"required": ["clusters"],
+						"properties": {
+							"clusters": {"type": "array", "minItems": 1, "items": {"type": "string"}}
+						}
+					}}}
+				},
+				"responses": {"200": {"description": "OK"}}
+			}
+		}
+	}
+}`
+
+func newValidationRouter(t *testing.T, validateResponses bool, response map[string]interface{}) *mux.Router {
+	validator, err := httputils.NewOpenAPIValidator(validationSpec, httputils.OpenAPIValidationConfiguration{
+		APIPrefix:         validationAPIPrefix + "/",
+		ValidateResponses: validateResponses,
+	})
+	helpers.FailOnError(t, err)
+
+	handler := func(writer http.ResponseWriter, _ *http.Request) {
+		helpers.FailOnError(t, responses.SendOK(writer, response))
+	}
+
+	router := mux.NewRouter()
+	router.Use(validator.Middleware)
+	router.HandleFunc(validationAPIPrefix+"/organizations/{org_id}/clusters", handler).Methods(http.MethodGet)
+	router.HandleFunc(validationAPIPrefix+"/clusters", handler).Methods(http.MethodPost)
+	router.HandleFunc(validationAPIPrefix+"/undocumented", handler).Methods(http.MethodGet)
+	return router
+}
+
+func serveValidation(router http.Handler, method, target, body string) (*httptest.ResponseRecorder, types.ProblemDetails) {
+	request := httptest.NewRequest(method, target, strings.NewReader(body))
+	if body != "" {
+		request.Header.Set("Content-Type", "application/json")
+	}
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+
+	var problem types.ProblemDetails
+	_ = json.Unmarshal(recorder.Body.Bytes(), &problem)
+	return recorder, problem
+}
+
+func TestNewOpenAPIValidatorInvalidSpec(t *testing.T) {
+	_, err := httputils.NewOpenAPIValidator("definitely-not-json", httputils.OpenAPIValidationConfiguration{})
+	assert.Error(t, err)
+
+	_, err = httputils.NewOpenAPIValidator(`{"openapi": "3.0.0", "paths": {}}`, httputils.OpenAPIValidationConfiguration{})
+	assert.ErrorContains(t, err, "invalid OpenAPI specification")
+}
+
+func TestOpenAPIValidatorValidRequest(t *testing.T) {
+	router := newValidationRouter(t, false, map[string]interface{}{"clusters": []string{}})
+
+	recorder, _ := serveValidation(router, http.MethodGet, validationAPIPrefix+"/organizations/1/clusters?limit=10", "")
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	recorder, _ = serveValidation(router, http.MethodPost, validationAPIPrefix+"/clusters", `{"clusters": ["c1"]}`)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+}
+
+func TestOpenAPIValidatorInvalidParameters(t *testing.T) {
+	router := newValidationRouter(t, false, nil)
+
+	recorder, problem := serveValidation(router, http.MethodGet, validationAPIPrefix+"/organizations/0/clusters?limit=1000", "")
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	assert.Equal(t, responses.ProblemJSON, recorder.Header().Get("Content-Type"))
+	assert.Equal(t, types.ProblemTypeValidation, problem.Type)
+	assert.Equal(t, []types.InvalidParam{
+		{Name: "org_id", Reason: "number must be at least 1"},
+		{Name: "limit", Reason: "number must be at most 100"},
+	}, problem.InvalidParams)
+}
+
+func TestOpenAPIValidatorInvalidBody(t *testing.T) {
+	router := newValidationRouter(t, false, nil)
+
+	recorder, problem := serveValidation(router, http.MethodPost, validationAPIPrefix+"/clusters", `{"clusters": []}`)
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	assert.Equal(t, []types.InvalidParam{
+		{Name: "body/clusters", Reason: "minimum number of items is 1"},
+	}, problem.InvalidParams)
+
+	recorder, problem = serveValidation(router, http.MethodPost, validationAPIPrefix+"/clusters", "")
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	assert.Len(t, problem.InvalidParams, 1)
+	assert.Equal(t, "body", problem.InvalidParams[0].Name)
+}
+
+func TestOpenAPIValidatorUndocumentedOperation(t *testing.T) {
+	router := newValidationRouter(t, true, map[string]interface{}{"anything": true})
+
+	recorder, _ := serveValidation(router, http.MethodGet, validationAPIPrefix+"/undocumented", "")
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	validator, err := httputils.NewOpenAPIValidator(validationSpec, httputils.OpenAPIValidationConfiguration{
+		APIPrefix: validationAPIPrefix,
+	})
+	helpers.FailOnError(t, err)
+	err = validator.ValidateRequest(httptest.NewRequest(http.MethodGet, validationAPIPrefix+"/undocumented", http.NoBody))
+	assert.True(t, errors.Is(err, httputils.ErrOperationNotInSpec))
+}
+
+func TestOpenAPIValidatorResponses(t *testing.T) {
+	target := validationAPIPrefix + "/organizations/1/clusters"
+
+	router := newValidationRouter(t, true, map[string]interface{}{"status": "ok", "clusters": []string{"c1"}})
+	recorder, _ := serveValidation(router, http.MethodGet, target, "")
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.JSONEq(t, `{"status": "ok", "clusters": ["c1"]}`, recorder.Body.String())
+
+	router = newValidationRouter(t, true, map[string]interface{}{"status": "ok", "clusters": "c1"})
+	recorder, problem := serveValidation(router, http.MethodGet, target, "")
+	assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+	assert.Equal(t, types.ProblemTypeInternalError, problem.Type)
+
+	// without response validation the response is sent unchanged
+	router = newValidationRouter(t, false, map[string]interface{}{"status": "ok", "clusters": "c1"})
+	recorder, _ = serveValidation(router, http.MethodGet, target, "")
+	assert.Equal(t, http.StatusOK, recorder.Code)
+}
+
+func TestOpenAPIValidatorValidateResponse(t *testing.T) {
+	validator, err := httputils.NewOpenAPIValidator(validationSpec, httputils.OpenAPIValidationConfiguration{
+		APIPrefix: validationAPIPrefix,
+	})
+	helpers.FailOnError(t, err)
+
+	request := httptest.NewRequest(http.MethodGet, validationAPIPrefix+"/organizations/1/clusters", http.NoBody)
+	header := http.Header{"Content-Type": {"application/json"}}
+
+	err = validator.ValidateResponse(request, http.StatusOK, header, []byte(`{"status": "ok", "clusters": []}`))
+	assert.NoError(t, err)
+
+	err = validator.ValidateResponse(request, http.StatusOK, header, []byte(`{"status": "ok"}`))
+	var validationErrs *types.ValidationErrors
+	assert.True(t, errors.As(err, &validationErrs))
+	assert.Equal(t, "response/clusters", validationErrs.Errors[0].ParamName)
+	assert.Equal(t, `property "clusters" is missing`, validationErrs.Errors[0].ErrString)
+
+	err = validator.ValidateResponse(request, http.StatusNotFound, header, nil)
+	assert.Error(t, err)
+}
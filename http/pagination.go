@@ -0,0 +1,300 @@
+// Copyright 2026 Red Hat, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httputils
+
+// Documentation in literate-programming-style is available at:
+// https://redhatinsights.github.io/insights-operator-utils/packages/http/pagination.html
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/RedHatInsights/insights-operator-utils/responses"
+	"github.com/RedHatInsights/insights-operator-utils/types"
+)
+
+// Names of query parameters controlling list endpoints
+const (
+	LimitParam  = "limit"
+	OffsetParam = "offset"
+	CursorParam = "cursor"
+	SortParam   = "sort"
+	FilterParam = "filter"
+)
+
+// Default values used for unset fields of ListOptions
+const (
+	DefaultListLimit    = 20
+	DefaultListMaxLimit = 100
+)
+
+// ListOptions describes list controls accepted by an endpoint
+type ListOptions struct {
+	DefaultLimit int
+	MaxLimit     int
+	// SortFields contains names of fields the list can be sorted by
+	SortFields []string
+	// DefaultSort is used when sort parameter is not provided, the syntax
+	// is the same as for the parameter, e.g. "-last_checked,name"
+	DefaultSort string
+	// FilterFields contains names of fields the list can be filtered by
+	FilterFields []string
+}
+
+// SortOrder represents one field of sort parameter
+type SortOrder struct {
+	Field      string
+	Descending bool
+}
+
+// ListParams contains parsed list controls. Either Offset or Cursor is set.
+type ListParams struct {
+	Limit  int
+	Offset int
+	// Cursor is the opaque cursor provided by client, it can be decoded by
+	// DecodeCursor
+	Cursor  string
+	Sort    []SortOrder
+	Filters map[string]string
+}
+
+// ParseListParams parses limit, offset, cursor, sort and filter query
+// parameters. Filters are expected in filter[field]=value form. ValidationError
+// is returned for invalid values, unknown fields and values out of bounds.
+func ParseListParams(request *http.Request, options ListOptions) (ListParams, error) {
+	if options.DefaultLimit <= 0 {
+		options.DefaultLimit = DefaultListLimit
+	}
+	if options.MaxLimit <= 0 {
+		options.MaxLimit = DefaultListMaxLimit
+	}
+
+	query := request.URL.Query()
+	params := ListParams{Filters: make(map[string]string)}
+
+	var err error
+	params.Limit, err = parseBoundedInt(query, LimitParam, options.DefaultLimit, 1, options.MaxLimit)
+	if err != nil {
+		return params, err
+	}
+
+	params.Offset, err = parseBoundedInt(query, OffsetParam, 0, 0, -1)
+	if err != nil {
+		return params, err
+	}
+
+	params.Cursor = query.Get(CursorParam)
+	if params.Cursor != "" && query.Has(OffsetParam) {
+		return params, &types.ValidationError{
+			ParamName:  CursorParam,
+			ParamValue: params.Cursor,
+			ErrString:  "cursor can't be combined with offset",
+		}
+	}
+
+	sortValue := options.DefaultSort
+	if query.Has(SortParam) {
+		sortValue = query.Get(SortParam)
+	}
+	params.Sort, err = parseSort(sortValue, options.SortFields)
+	if err != nil {
+		return params, err
+	}
+
+	for key, values := range query {
+		field, isFilter := filterField(key)
+		if !isFilter {
+			continue
+		}
+		if !slices.Contains(options.FilterFields, field) {
+			return params, &types.ValidationError{
+				ParamName:  key,
+				ParamValue: values[0],
+				ErrString:  fmt.Sprintf("filtering by '%s' is not supported", field),
+			}
+		}
+		params.Filters[field] = values[0]
+	}
+
+	return params, nil
+}
+
+// ReadListParams retrieves list controls from request
+// if it's not possible, it writes http error to the writer and returns false
+func ReadListParams(writer http.ResponseWriter, request *http.Request, options ListOptions) (ListParams, bool) {
+	params, err := ParseListParams(request, options)
+	if err != nil {
+		types.HandleServerError(writer, err)
+		return params, false
+	}
+	return params, true
+}
+
+// parseBoundedInt parses integer query parameter, negative maximum means no
+// upper bound
+func parseBoundedInt(query url.Values, name string, defaultValue, minimum, maximum int) (int, error) {
+	if !query.Has(name) {
+		return defaultValue, nil
+	}
+
+	value := query.Get(name)
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, &types.ValidationError{ParamName: name, ParamValue: value, ErrString: "integer expected"}
+	}
+	if parsed < minimum || (maximum >= 0 && parsed > maximum) {
+		errString := fmt.Sprintf("value must be at least %d", minimum)
+		if maximum >= 0 {
+			errString = fmt.Sprintf("value must be between %d and %d", minimum, maximum)
+		}
+		return 0, &types.ValidationError{ParamName: name, ParamValue: value, ErrString: errString}
+	}
+	return parsed, nil
+}
+
+func parseSort(value string, allowedFields []string) ([]SortOrder, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	var orders []SortOrder
+	for _, item := range strings.Split(value, ",") {
+		order := SortOrder{Field: strings.TrimSpace(item)}
+		if field, descending := strings.CutPrefix(order.Field, "-"); descending {
+			order.Field = field
+			order.Descending = true
+		}
+		if !slices.Contains(allowedFields, order.Field) {
+			return nil, &types.ValidationError{
+				ParamName:  SortParam,
+				ParamValue: value,
+				ErrString:  fmt.Sprintf("sorting by '%s' is not supported", order.Field),
+			}
+		}
+		orders = append(orders, order)
+	}
+	return orders, nil
+}
+
+// filterField returns name of the field from filter[field] query parameter
+func filterField(key string) (string, bool) {
+	inner, found := strings.CutPrefix(key, FilterParam+"[")
+	if !found {
+		return "", false
+	}
+	return strings.CutSuffix(inner, "]")
+}
+
+// EncodeCursor encodes the value (e.g. sort key of the last item) as an
+// opaque cursor
+func EncodeCursor(value interface{}) (string, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// DecodeCursor decodes cursor created by EncodeCursor into the target,
+// ValidationError is returned for malformed cursors
+func DecodeCursor(cursor string, target interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err == nil {
+		err = json.Unmarshal(data, target)
+	}
+	if err != nil {
+		return &types.ValidationError{
+			ParamName:  CursorParam,
+			ParamValue: cursor,
+			ErrString:  "malformed cursor",
+		}
+	}
+	return nil
+}
+
+// Page represents one page of a list returned by an endpoint
+type Page struct {
+	Data interface{}
+	// Count is the number of items in the page
+	Count int
+	// Total is the number of all items, negative value means it's unknown
+	Total int
+	// NextCursor is used in the link to the next page when set
+	NextCursor string
+}
+
+// PageMeta is the "meta" part of the list response
+type PageMeta = responses.Meta
+
+// PageLinks is the "links" part of the list response
+type PageLinks = responses.Links
+
+// BuildPageResponse builds response with status "ok", the page data under
+// dataName key, and "meta" and "links" describing the page. Links keep all
+// query parameters of the request except the pagination ones. When the total
+// count is unknown, the link to the next page is provided for full pages.
+func BuildPageResponse(request *http.Request, params ListParams, dataName string, page Page) map[string]interface{} {
+	meta := PageMeta{
+		Count:  page.Count,
+		Limit:  params.Limit,
+		Offset: params.Offset,
+	}
+	if page.Total >= 0 {
+		total := page.Total
+		meta.Total = &total
+	}
+
+	links := PageLinks{First: pageLink(request, params.Limit, 0, "")}
+	switch {
+	case page.NextCursor != "":
+		links.Next = pageLink(request, params.Limit, 0, page.NextCursor)
+	case params.Cursor == "" && hasNextPage(params, page):
+		links.Next = pageLink(request, params.Limit, params.Offset+params.Limit, "")
+	}
+	if params.Cursor == "" && params.Offset > 0 {
+		links.Prev = pageLink(request, params.Limit, max(params.Offset-params.Limit, 0), "")
+	}
+
+	response := responses.BuildOkResponseWithData(dataName, page.Data)
+	response["meta"] = meta
+	response["links"] = links
+	return response
+}
+
+func hasNextPage(params ListParams, page Page) bool {
+	if page.Total >= 0 {
+		return params.Offset+page.Count < page.Total
+	}
+	return page.Count >= params.Limit
+}
+
+func pageLink(request *http.Request, limit, offset int, cursor string) string {
+	query := request.URL.Query()
+	query.Set(LimitParam, strconv.Itoa(limit))
+	query.Del(OffsetParam)
+	query.Del(CursorParam)
+	if cursor != "" {
+		query.Set(CursorParam, cursor)
+	} else if offset > 0 {
+		query.Set(OffsetParam, strconv.Itoa(offset))
+	}
+	return request.URL.Path + "?" + query.Encode()
+}
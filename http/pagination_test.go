@@ -0,0 +1,205 @@
+// Copyright 2026 Red Hat, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httputils_test
+
+// Documentation in literate-programming-style is available at:
+// https://redhatinsights.github.io/insights-operator-utils/packages/http/pagination_test.html
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	httputils "github.com/RedHatInsights/insights-operator-utils/http"
+	"github.com/RedHatInsights/insights-operator-utils/tests/helpers"
+	"github.com/RedHatInsights/insights-operator-utils/types"
+)
+
+var listOptions = httputils.ListOptions{
+	DefaultLimit: 10,
+	MaxLimit:     50,
+	SortFields:   []string{"name", "last_checked"},
+	DefaultSort:  "-last_checked",
+	FilterFields: []string{"status"},
+}
+
+func parseList(t *testing.T, target string) (httputils.ListParams, error) {
+	request := httptest.NewRequest(http.MethodGet, target, http.NoBody)
+	return httputils.ParseListParams(request, listOptions)
+}
+
+func TestParseListParamsDefaults(t *testing.T) {
+	params, err := parseList(t, "/clusters")
+	helpers.FailOnError(t, err)
+
+	assert.Equal(t, 10, params.Limit)
+	assert.Equal(t, 0, params.Offset)
+	assert.Empty(t, params.Cursor)
+	assert.Equal(t, []httputils.SortOrder{{Field: "last_checked", Descending: true}}, params.Sort)
+	assert.Empty(t, params.Filters)
+}
+
+func TestParseListParams(t *testing.T) {
+	params, err := parseList(t, "/clusters?limit=50&offset=100&sort=name,-last_checked&filter[status]=active&other=1")
+	helpers.FailOnError(t, err)
+
+	assert.Equal(t, 50, params.Limit)
+	assert.Equal(t, 100, params.Offset)
+	assert.Equal(t, []httputils.SortOrder{
+		{Field: "name"},
+		{Field: "last_checked", Descending: true},
+	}, params.Sort)
+	assert.Equal(t, map[string]string{"status": "active"}, params.Filters)
+}
+
+func TestParseListParamsValidation(t *testing.T) {
+	testCases := []struct {
+		query             string
+		expectedParamName string
+	}{
+		{"limit=abc", "limit"},
+		{"limit=0", "limit"},
+		{"limit=51", "limit"},
+		{"offset=-1", "offset"},
+		{"offset=1&cursor=abc", "cursor"},
+		{"sort=password", "sort"},
+		{"sort=name,", "sort"},
+		{"filter[owner]=me", "filter[owner]"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.query, func(t *testing.T) {
+			_, err := parseList(t, "/clusters?"+tc.query)
+
+			var validationErr *types.ValidationError
+			assert.True(t, errors.As(err, &validationErr))
+			assert.Equal(t, tc.expectedParamName, validationErr.ParamName)
+		})
+	}
+}
+
+func TestReadListParams(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodGet, "/clusters?limit=1000", http.NoBody)
+
+	_, ok := httputils.ReadListParams(recorder, request, listOptions)
+	assert.False(t, ok)
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+
+	recorder = httptest.NewRecorder()
+	request = httptest.NewRequest(http.MethodGet, "/clusters?limit=5", http.NoBody)
+	params, ok := httputils.ReadListParams(recorder, request, listOptions)
+	assert.True(t, ok)
+	assert.Equal(t, 5, params.Limit)
+}
+
+func TestCursorRoundTrip(t *testing.T) {
+	type position struct {
+		LastChecked string `json:"last_checked"`
+		ID          int    `json:"id"`
+	}
+
+	cursor, err := httputils.EncodeCursor(position{LastChecked: "2020-01-01", ID: 42})
+	helpers.FailOnError(t, err)
+	assert.NotContains(t, cursor, "=")
+
+	var decoded position
+	helpers.FailOnError(t, httputils.DecodeCursor(cursor, &decoded))
+	assert.Equal(t, position{LastChecked: "2020-01-01", ID: 42}, decoded)
+
+	err = httputils.DecodeCursor("!!!", &decoded)
+	var validationErr *types.ValidationError
+	assert.True(t, errors.As(err, &validationErr))
+	assert.Equal(t, "cursor", validationErr.ParamName)
+}
+
+// buildPage builds page response and converts it to JSON and back, so it can
+// be compared with expected JSON
+func buildPage(t *testing.T, target string, page httputils.Page) map[string]interface{} {
+	request := httptest.NewRequest(http.MethodGet, target, http.NoBody)
+	params, err := httputils.ParseListParams(request, listOptions)
+	helpers.FailOnError(t, err)
+
+	data, err := json.Marshal(httputils.BuildPageResponse(request, params, "clusters", page))
+	helpers.FailOnError(t, err)
+
+	var response map[string]interface{}
+	helpers.FailOnError(t, json.Unmarshal(data, &response))
+	return response
+}
+
+func TestBuildPageResponseWithTotal(t *testing.T) {
+	response := buildPage(t, "/clusters?limit=2&offset=2&sort=name", httputils.Page{
+		Data:  []string{"c", "d"},
+		Count: 2,
+		Total: 5,
+	})
+
+	assert.Equal(t, "ok", response["status"])
+	assert.Equal(t, []interface{}{"c", "d"}, response["clusters"])
+	assert.Equal(t, map[string]interface{}{
+		"count": 2.0, "total": 5.0, "limit": 2.0, "offset": 2.0,
+	}, response["meta"])
+	assert.Equal(t, map[string]interface{}{
+		"first": "/clusters?limit=2&sort=name",
+		"next":  "/clusters?limit=2&offset=4&sort=name",
+		"prev":  "/clusters?limit=2&sort=name",
+	}, response["links"])
+}
+
+func TestBuildPageResponseLastPage(t *testing.T) {
+	response := buildPage(t, "/clusters?limit=2&offset=4", httputils.Page{
+		Data:  []string{"e"},
+		Count: 1,
+		Total: 5,
+	})
+
+	assert.Equal(t, map[string]interface{}{
+		"first": "/clusters?limit=2",
+		"prev":  "/clusters?limit=2&offset=2",
+	}, response["links"])
+}
+
+func TestBuildPageResponseUnknownTotal(t *testing.T) {
+	response := buildPage(t, "/clusters?limit=2", httputils.Page{
+		Data:  []string{"a", "b"},
+		Count: 2,
+		Total: -1,
+	})
+
+	assert.NotContains(t, response["meta"], "total")
+	assert.Equal(t, map[string]interface{}{
+		"first": "/clusters?limit=2",
+		"next":  "/clusters?limit=2&offset=2",
+	}, response["links"])
+}
+
+func TestBuildPageResponseWithCursor(t *testing.T) {
+	response := buildPage(t, "/clusters?limit=2&cursor=abc", httputils.Page{
+		Data:       []string{"c", "d"},
+		Count:      2,
+		Total:      -1,
+		NextCursor: "def",
+	})
+
+	assert.Equal(t, map[string]interface{}{
+		"first": "/clusters?limit=2",
+		"next":  "/clusters?cursor=def&limit=2",
+	}, response["links"])
+}
@@ -0,0 +1,397 @@
+// Copyright 2026 Red Hat, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httputils
+
+// Documentation in literate-programming-style is available at:
+// https://redhatinsights.github.io/insights-operator-utils/packages/http/rate_limit.html
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	redisV9 "github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog/log"
+
+	"github.com/RedHatInsights/insights-operator-utils/metrics"
+	"github.com/RedHatInsights/insights-operator-utils/redis"
+	"github.com/RedHatInsights/insights-operator-utils/responses"
+	"github.com/RedHatInsights/insights-operator-utils/types"
+)
+
+// Supported values of RateLimitConfiguration.Backend
+const (
+	// MemoryRateLimitBackend keeps token buckets in memory of the process
+	MemoryRateLimitBackend = "memory"
+	// RedisRateLimitBackend keeps sliding windows in Redis, so the limit is
+	// shared by all instances of the service
+	RedisRateLimitBackend = "redis"
+)
+
+// Classes of keys the requests are limited by
+const (
+	OrgRateLimitKeyClass = "org"
+	IPRateLimitKeyClass  = "ip"
+)
+
+// Default values used for unset fields of RateLimitConfiguration
+const (
+	DefaultRateLimitRequests  = 100
+	DefaultRateLimitPeriod    = time.Minute
+	DefaultRateLimitKeyPrefix = "rate_limit:"
+)
+
+// RateLimitConfiguration represents configuration of RateLimit middleware
+type RateLimitConfiguration struct {
+	// Backend is either "memory" (default) or "redis"
+	Backend string `mapstructure:"backend" toml:"backend"`
+	// Requests is the number of requests allowed per Period
+	Requests int           `mapstructure:"requests" toml:"requests"`
+	Period   time.Duration `mapstructure:"period" toml:"period"`
+	// Burst is the capacity of token buckets of memory backend, Requests
+	// is used when not set
+	Burst int `mapstructure:"burst" toml:"burst"`
+	// KeyPrefix is prepended to keys stored in Redis
+	KeyPrefix string `mapstructure:"key_prefix" toml:"key_prefix"`
+	// ClientIPHeader is the header with client address set by a proxy,
+	// e.g. X-Forwarded-For. Remote address of the connection is used when
+	// not set.
+	ClientIPHeader string `mapstructure:"client_ip_header" toml:"client_ip_header"`
+	// TrustedProxies is the number of proxies in front of the service that
+	// append addresses to ClientIPHeader. Addresses on the left can be sent
+	// by the client, so the address appended by the outermost trusted proxy
+	// is used, i.e. TrustedProxies-th address from the right. The rightmost
+	// address is used when not set.
+	TrustedProxies int `mapstructure:"trusted_proxies" toml:"trusted_proxies"`
+	// ExemptPaths contains paths that are not limited. Path ending with
+	// "*" matches all paths with the given prefix.
+	ExemptPaths []string `mapstructure:"exempt_paths" toml:"exempt_paths"`
+}
+
+func (configuration *RateLimitConfiguration) setDefaults() {
+	if configuration.Backend == "" {
+		configuration.Backend = MemoryRateLimitBackend
+	}
+	if configuration.Requests <= 0 {
+		configuration.Requests = DefaultRateLimitRequests
+	}
+	if configuration.Period <= 0 {
+		configuration.Period = DefaultRateLimitPeriod
+	}
+	if configuration.KeyPrefix == "" {
+		configuration.KeyPrefix = DefaultRateLimitKeyPrefix
+	}
+	if configuration.TrustedProxies <= 0 {
+		configuration.TrustedProxies = 1
+	}
+}
+
+// RateLimitResult is the decision of rate limiter about one request
+type RateLimitResult struct {
+	Allowed bool
+	responses.RateLimit
+	// RetryAfter is set for requests that are not allowed
+	RetryAfter time.Duration
+}
+
+// RateLimiter decides whether a request identified by the key is allowed
+type RateLimiter interface {
+	Allow(ctx context.Context, key string) (RateLimitResult, error)
+}
+
+// NewRateLimiter creates rate limiter with the backend selected by the
+// configuration. Redis client is needed by the redis backend only.
+func NewRateLimiter(configuration RateLimitConfiguration, redisClient *redis.Client) (RateLimiter, error) {
+	configuration.setDefaults()
+
+	switch configuration.Backend {
+	case MemoryRateLimitBackend:
+		return NewTokenBucketRateLimiter(configuration.Requests, configuration.Period, configuration.Burst), nil
+	case RedisRateLimitBackend:
+		if redisClient == nil || redisClient.Connection == nil {
+			return nil, errors.New("Redis client is needed by redis rate limit backend")
+		}
+		return NewRedisRateLimiter(
+			redisClient, configuration.Requests, configuration.Period, configuration.KeyPrefix,
+		), nil
+	default:
+		return nil, fmt.Errorf("unsupported rate limit backend '%s'", configuration.Backend)
+	}
+}
+
+// RateLimit creates a middleware that limits requests of every organization
+// or, for requests without identity, of every client IP address. The
+// identity is read from the request context where it is stored by
+// Authenticate middleware, so RateLimit needs to be placed after it.
+// RateLimit-Limit, RateLimit-Remaining and RateLimit-Reset headers are sent
+// with all responses and requests over the limit are refused with 429 Too
+// Many Requests and Retry-After header. Requests are let through when the
+// limiter fails.
+func RateLimit(configuration RateLimitConfiguration, limiter RateLimiter) mux.MiddlewareFunc {
+	configuration.setDefaults()
+
+	return func(nextHandler http.Handler) http.Handler {
+		return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			if isExemptPath(request.URL.Path, configuration.ExemptPaths) {
+				nextHandler.ServeHTTP(writer, request)
+				return
+			}
+
+			key, class := rateLimitKey(request, configuration)
+			result, err := limiter.Allow(request.Context(), key)
+			if err != nil {
+				log.Error().Err(err).Str("key", key).Msg("Unable to check rate limit")
+				metrics.RateLimiterErrors.WithLabelValues(configuration.Backend).Inc()
+				nextHandler.ServeHTTP(writer, request)
+				return
+			}
+
+			result.RateLimit.SetHeaders(writer)
+			if !result.Allowed {
+				metrics.RateLimitDecisions.WithLabelValues(class, "limited").Inc()
+				types.HandleServerError(writer, &types.TooManyRequestsError{Key: key, RetryAfter: result.RetryAfter})
+				return
+			}
+
+			metrics.RateLimitDecisions.WithLabelValues(class, "allowed").Inc()
+			nextHandler.ServeHTTP(writer, request)
+		})
+	}
+}
+
+// rateLimitKey returns the key the request is limited by and its class
+func rateLimitKey(request *http.Request, configuration RateLimitConfiguration) (key, class string) {
+	if identity, found := GetIdentity(request); found && identity.OrgID != 0 {
+		return fmt.Sprintf("%s:%d", OrgRateLimitKeyClass, identity.OrgID), OrgRateLimitKeyClass
+	}
+
+	address := ""
+	if configuration.ClientIPHeader != "" {
+		address = forwardedAddress(request, configuration.ClientIPHeader, configuration.TrustedProxies)
+	}
+	if address == "" {
+		address = request.RemoteAddr
+		if host, _, err := net.SplitHostPort(address); err == nil {
+			address = host
+		}
+	}
+	return IPRateLimitKeyClass + ":" + address, IPRateLimitKeyClass
+}
+
+// forwardedAddress returns the address appended to the header by the
+// outermost of trusted proxies. When the header contains fewer addresses,
+// all of them were appended by the trusted proxies, so the first one is
+// used.
+func forwardedAddress(request *http.Request, header string, trustedProxies int) string {
+	var addresses []string
+	for _, value := range request.Header.Values(header) {
+		for address := range strings.SplitSeq(value, ",") {
+			addresses = append(addresses, strings.TrimSpace(address))
+		}
+	}
+	if len(addresses) == 0 {
+		return ""
+	}
+	return addresses[max(len(addresses)-trustedProxies, 0)]
+}
+
+// TokenBucketRateLimiter is in-memory RateLimiter. Every key has a bucket
+// of tokens that is refilled continuously and every request takes one token.
+// Buckets that would be full are removed periodically.
+type TokenBucketRateLimiter struct {
+	capacity float64
+	// rate is the number of tokens added per second
+	rate   float64
+	period time.Duration
+
+	mutex       sync.Mutex
+	buckets     map[string]*tokenBucket
+	lastCleanup time.Time
+}
+
+type tokenBucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// NewTokenBucketRateLimiter creates in-memory limiter allowing the number of
+// requests per period, with bursts up to burst requests. Requests is used
+// as burst when it's not set.
+func NewTokenBucketRateLimiter(requests int, period time.Duration, burst int) *TokenBucketRateLimiter {
+	if requests <= 0 {
+		requests = DefaultRateLimitRequests
+	}
+	if period <= 0 {
+		period = DefaultRateLimitPeriod
+	}
+	if burst <= 0 {
+		burst = requests
+	}
+	return &TokenBucketRateLimiter{
+		capacity:    float64(burst),
+		rate:        float64(requests) / period.Seconds(),
+		period:      period,
+		buckets:     make(map[string]*tokenBucket),
+		lastCleanup: time.Now(),
+	}
+}
+
+// Allow takes one token from the bucket of the key, it never fails
+func (limiter *TokenBucketRateLimiter) Allow(_ context.Context, key string) (RateLimitResult, error) {
+	now := time.Now()
+
+	limiter.mutex.Lock()
+	defer limiter.mutex.Unlock()
+
+	limiter.cleanup(now)
+
+	bucket, found := limiter.buckets[key]
+	if !found {
+		bucket = &tokenBucket{tokens: limiter.capacity, updated: now}
+		limiter.buckets[key] = bucket
+	}
+	bucket.tokens = limiter.tokens(bucket, now)
+	bucket.updated = now
+
+	result := RateLimitResult{RateLimit: responses.RateLimit{Limit: int(limiter.capacity)}}
+	if bucket.tokens >= 1 {
+		bucket.tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = limiter.refillTime(1 - bucket.tokens)
+	}
+	result.Remaining = int(bucket.tokens)
+	result.Reset = limiter.refillTime(limiter.capacity - bucket.tokens)
+	return result, nil
+}
+
+// tokens returns number of tokens in the bucket at the given time
+func (limiter *TokenBucketRateLimiter) tokens(bucket *tokenBucket, now time.Time) float64 {
+	return min(limiter.capacity, bucket.tokens+now.Sub(bucket.updated).Seconds()*limiter.rate)
+}
+
+// refillTime returns time needed to add the number of tokens to a bucket
+func (limiter *TokenBucketRateLimiter) refillTime(tokens float64) time.Duration {
+	return time.Duration(tokens / limiter.rate * float64(time.Second))
+}
+
+// cleanup removes full buckets once per period
+func (limiter *TokenBucketRateLimiter) cleanup(now time.Time) {
+	if now.Sub(limiter.lastCleanup) < limiter.period {
+		return
+	}
+	for key, bucket := range limiter.buckets {
+		if limiter.tokens(bucket, now) >= limiter.capacity {
+			delete(limiter.buckets, key)
+		}
+	}
+	limiter.lastCleanup = now
+}
+
+// slidingWindowScript removes requests older than the window, adds the
+// current request when the limit is not reached and returns whether the
+// request was added, number of requests in the window and timestamp of the
+// oldest one
+var slidingWindowScript = redisV9.NewScript(`
+local key = KEYS[1]
+local now = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local limit = tonumber(ARGV[3])
+
+redis.call("ZREMRANGEBYSCORE", key, "-inf", now - window)
+local count = redis.call("ZCARD", key)
+local allowed = 0
+if count < limit then
+	redis.call("ZADD", key, now, ARGV[4])
+	count = count + 1
+	allowed = 1
+end
+redis.call("PEXPIRE", key, window)
+
+local oldest = redis.call("ZRANGE", key, 0, 0, "WITHSCORES")
+local oldestTimestamp = now
+if oldest[2] then
+	oldestTimestamp = tonumber(oldest[2])
+end
+return {allowed, count, oldestTimestamp}
+`)
+
+// RedisRateLimiter is RateLimiter sharing the limits between instances of
+// the service. Requests of every key are counted in a sliding window stored
+// as sorted set in Redis.
+type RedisRateLimiter struct {
+	client    *redis.Client
+	requests  int
+	period    time.Duration
+	keyPrefix string
+}
+
+// NewRedisRateLimiter creates limiter allowing the number of requests per
+// period, keys stored in Redis are prefixed by keyPrefix
+func NewRedisRateLimiter(client *redis.Client, requests int, period time.Duration, keyPrefix string) *RedisRateLimiter {
+	if requests <= 0 {
+		requests = DefaultRateLimitRequests
+	}
+	if period <= 0 {
+		period = DefaultRateLimitPeriod
+	}
+	return &RedisRateLimiter{
+		client:    client,
+		requests:  requests,
+		period:    period,
+		keyPrefix: keyPrefix,
+	}
+}
+
+// Allow records the request in the sliding window of the key if the limit is
+// not reached yet
+func (limiter *RedisRateLimiter) Allow(ctx context.Context, key string) (RateLimitResult, error) {
+	now := time.Now().UnixMilli()
+	window := limiter.period.Milliseconds()
+
+	values, err := slidingWindowScript.Run(
+		ctx, limiter.client.Connection, []string{limiter.keyPrefix + key},
+		now, window, limiter.requests, uuid.NewString(),
+	).Int64Slice()
+	if err != nil {
+		return RateLimitResult{}, err
+	}
+	if len(values) != 3 {
+		return RateLimitResult{}, fmt.Errorf("unexpected response of rate limit script: %v", values)
+	}
+
+	allowed, count, oldest := values[0] == 1, int(values[1]), values[2]
+	reset := time.Duration(oldest+window-now) * time.Millisecond
+
+	result := RateLimitResult{
+		Allowed: allowed,
+		RateLimit: responses.RateLimit{
+			Limit:     limiter.requests,
+			Remaining: limiter.requests - count,
+			Reset:     reset,
+		},
+	}
+	if !allowed {
+		result.RetryAfter = reset
+	}
+	return result, nil
+}
@@ -0,0 +1,255 @@
+// Copyright 2026 Red Hat, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httputils_test
+
+// Documentation in literate-programming-style is available at:
+// https://redhatinsights.github.io/insights-operator-utils/packages/http/rate_limit_test.html
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	ctypes "github.com/RedHatInsights/insights-results-types"
+	"github.com/go-redis/redismock/v9"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+
+	httputils "github.com/RedHatInsights/insights-operator-utils/http"
+	"github.com/RedHatInsights/insights-operator-utils/metrics"
+	"github.com/RedHatInsights/insights-operator-utils/redis"
+	"github.com/RedHatInsights/insights-operator-utils/responses"
+	"github.com/RedHatInsights/insights-operator-utils/tests/helpers"
+)
+
+type failingRateLimiter struct{}
+
+func (failingRateLimiter) Allow(context.Context, string) (httputils.RateLimitResult, error) {
+	return httputils.RateLimitResult{}, errors.New("backend is down")
+}
+
+func serveRateLimited(
+	configuration httputils.RateLimitConfiguration, limiter httputils.RateLimiter, request *http.Request,
+) *httptest.ResponseRecorder {
+	handler := httputils.RateLimit(configuration, limiter)(http.HandlerFunc(
+		func(writer http.ResponseWriter, _ *http.Request) {
+			writer.WriteHeader(http.StatusOK)
+		}))
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func orgRequest(orgID ctypes.OrgID) *http.Request {
+	request := httptest.NewRequest(http.MethodGet, "/report", http.NoBody)
+	ctx := context.WithValue(request.Context(), ctypes.ContextKeyUser, ctypes.Identity{OrgID: orgID})
+	return request.WithContext(ctx)
+}
+
+func TestTokenBucketRateLimiter(t *testing.T) {
+	limiter := httputils.NewTokenBucketRateLimiter(3, time.Minute, 0)
+
+	for i := 0; i < 3; i++ {
+		result, err := limiter.Allow(context.Background(), "org:1")
+		helpers.FailOnError(t, err)
+		assert.True(t, result.Allowed)
+		assert.Equal(t, 3, result.Limit)
+		assert.Equal(t, 2-i, result.Remaining)
+	}
+
+	result, err := limiter.Allow(context.Background(), "org:1")
+	helpers.FailOnError(t, err)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, 0, result.Remaining)
+	assert.InDelta(t, 20*time.Second, result.RetryAfter, float64(time.Second))
+	assert.InDelta(t, time.Minute, result.Reset, float64(time.Second))
+
+	// other keys have their own buckets
+	result, err = limiter.Allow(context.Background(), "org:2")
+	helpers.FailOnError(t, err)
+	assert.True(t, result.Allowed)
+}
+
+func TestTokenBucketRateLimiterRefill(t *testing.T) {
+	limiter := httputils.NewTokenBucketRateLimiter(100, 100*time.Millisecond, 1)
+
+	result, _ := limiter.Allow(context.Background(), "ip:192.0.2.1")
+	assert.True(t, result.Allowed)
+	result, _ = limiter.Allow(context.Background(), "ip:192.0.2.1")
+	assert.False(t, result.Allowed)
+
+	time.Sleep(5 * time.Millisecond)
+	result, _ = limiter.Allow(context.Background(), "ip:192.0.2.1")
+	assert.True(t, result.Allowed)
+}
+
+func TestRateLimitMiddleware(t *testing.T) {
+	configuration := httputils.RateLimitConfiguration{Requests: 1, Period: time.Minute}
+	limiter := httputils.NewTokenBucketRateLimiter(1, time.Minute, 0)
+	limited := metrics.RateLimitDecisions.WithLabelValues(httputils.OrgRateLimitKeyClass, "limited")
+	limitedBefore := testutil.ToFloat64(limited)
+
+	recorder := serveRateLimited(configuration, limiter, orgRequest(42))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "1", recorder.Header().Get(responses.RateLimitLimitHeader))
+	assert.Equal(t, "0", recorder.Header().Get(responses.RateLimitRemainingHeader))
+	assert.Equal(t, "60", recorder.Header().Get(responses.RateLimitResetHeader))
+
+	recorder = serveRateLimited(configuration, limiter, orgRequest(42))
+	assert.Equal(t, http.StatusTooManyRequests, recorder.Code)
+	assert.Equal(t, "60", recorder.Header().Get(responses.RetryAfterHeader))
+	assert.JSONEq(t, `{"status":"rate limit exceeded for org:42"}`, recorder.Body.String())
+	assert.Equal(t, limitedBefore+1, testutil.ToFloat64(limited))
+
+	// requests of other organizations are not limited
+	recorder = serveRateLimited(configuration, limiter, orgRequest(43))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+}
+
+func TestRateLimitMiddlewareClientIP(t *testing.T) {
+	configuration := httputils.RateLimitConfiguration{ClientIPHeader: "X-Forwarded-For"}
+	limiter := httputils.NewTokenBucketRateLimiter(1, time.Minute, 0)
+
+	// address appended by the proxy is used, the first one can be spoofed
+	request := httptest.NewRequest(http.MethodGet, "/report", http.NoBody)
+	request.Header.Set("X-Forwarded-For", "203.0.113.9, 198.51.100.7")
+	assert.Equal(t, http.StatusOK, serveRateLimited(configuration, limiter, request).Code)
+
+	request.Header.Set("X-Forwarded-For", "203.0.113.10, 198.51.100.7")
+	recorder := serveRateLimited(configuration, limiter, request)
+	assert.Equal(t, http.StatusTooManyRequests, recorder.Code)
+	assert.JSONEq(t, `{"status":"rate limit exceeded for ip:198.51.100.7"}`, recorder.Body.String())
+
+	// remote address is used when the header is missing
+	request = httptest.NewRequest(http.MethodGet, "/report", http.NoBody)
+	assert.Equal(t, http.StatusOK, serveRateLimited(configuration, limiter, request).Code)
+	recorder = serveRateLimited(configuration, limiter, request)
+	assert.JSONEq(t, `{"status":"rate limit exceeded for ip:192.0.2.1"}`, recorder.Body.String())
+}
+
+func TestRateLimitMiddlewareTrustedProxies(t *testing.T) {
+	configuration := httputils.RateLimitConfiguration{ClientIPHeader: "X-Forwarded-For", TrustedProxies: 2}
+	limiter := httputils.NewTokenBucketRateLimiter(1, time.Minute, 0)
+
+	request := httptest.NewRequest(http.MethodGet, "/report", http.NoBody)
+	request.Header.Add("X-Forwarded-For", "203.0.113.9, 198.51.100.7")
+	request.Header.Add("X-Forwarded-For", "10.0.0.1")
+	assert.Equal(t, http.StatusOK, serveRateLimited(configuration, limiter, request).Code)
+	recorder := serveRateLimited(configuration, limiter, request)
+	assert.JSONEq(t, `{"status":"rate limit exceeded for ip:198.51.100.7"}`, recorder.Body.String())
+
+	// all addresses were appended by trusted proxies
+	request = httptest.NewRequest(http.MethodGet, "/report", http.NoBody)
+	request.Header.Set("X-Forwarded-For", "198.51.100.8")
+	assert.Equal(t, http.StatusOK, serveRateLimited(configuration, limiter, request).Code)
+	recorder = serveRateLimited(configuration, limiter, request)
+	assert.JSONEq(t, `{"status":"rate limit exceeded for ip:198.51.100.8"}`, recorder.Body.String())
+}
+
+func TestRateLimitMiddlewareExemptPaths(t *testing.T) {
+	configuration := httputils.RateLimitConfiguration{ExemptPaths: []string{"/metrics"}}
+	limiter := httputils.NewTokenBucketRateLimiter(1, time.Minute, 0)
+
+	for i := 0; i < 3; i++ {
+		request := httptest.NewRequest(http.MethodGet, "/metrics", http.NoBody)
+		recorder := serveRateLimited(configuration, limiter, request)
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.Empty(t, recorder.Header().Get(responses.RateLimitLimitHeader))
+	}
+}
+
+func TestRateLimitMiddlewareLimiterError(t *testing.T) {
+	configuration := httputils.RateLimitConfiguration{Backend: httputils.RedisRateLimitBackend}
+	errorsCounter := metrics.RateLimiterErrors.WithLabelValues(httputils.RedisRateLimitBackend)
+	errorsBefore := testutil.ToFloat64(errorsCounter)
+
+	recorder := serveRateLimited(configuration, failingRateLimiter{}, orgRequest(42))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, errorsBefore+1, testutil.ToFloat64(errorsCounter))
+}
+
+func mockRedisRateLimiter() (*httputils.RedisRateLimiter, redismock.ClientMock) {
+	client, mock := redismock.NewClientMock()
+	limiter := httputils.NewRedisRateLimiter(&redis.Client{Connection: client}, 5, time.Minute, "test:")
+	return limiter, mock
+}
+
+// expectRateLimitScript expects run of the sliding window script for the key,
+// timestamps and request IDs are not known in advance, so only the command
+// and the key are checked
+func expectRateLimitScript(mock redismock.ClientMock, key string) *redismock.ExpectedCmd {
+	return mock.CustomMatch(func(expected, actual []interface{}) error {
+		if len(actual) < 4 || actual[0] != expected[0] || actual[3] != expected[3] {
+			return errors.New("unexpected command")
+		}
+		return nil
+	}).ExpectEvalSha("", []string{key}, "now", "window", "limit", "id")
+}
+
+func TestRedisRateLimiter(t *testing.T) {
+	limiter, mock := mockRedisRateLimiter()
+	now := time.Now().UnixMilli()
+
+	expectRateLimitScript(mock, "test:org:1").SetVal([]interface{}{int64(1), int64(2), now - 30000})
+	result, err := limiter.Allow(context.Background(), "org:1")
+	helpers.FailOnError(t, err)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 5, result.Limit)
+	assert.Equal(t, 3, result.Remaining)
+	assert.InDelta(t, 30*time.Second, result.Reset, float64(time.Second))
+	assert.Zero(t, result.RetryAfter)
+
+	expectRateLimitScript(mock, "test:org:1").SetVal([]interface{}{int64(0), int64(5), now - 50000})
+	result, err = limiter.Allow(context.Background(), "org:1")
+	helpers.FailOnError(t, err)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, 0, result.Remaining)
+	assert.InDelta(t, 10*time.Second, result.RetryAfter, float64(time.Second))
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRedisRateLimiterErrors(t *testing.T) {
+	limiter, mock := mockRedisRateLimiter()
+
+	expectRateLimitScript(mock, "test:org:1").SetErr(errors.New("connection refused"))
+	_, err := limiter.Allow(context.Background(), "org:1")
+	assert.EqualError(t, err, "connection refused")
+
+	expectRateLimitScript(mock, "test:org:1").SetVal([]interface{}{int64(1)})
+	_, err = limiter.Allow(context.Background(), "org:1")
+	assert.EqualError(t, err, "unexpected response of rate limit script: [1]")
+}
+
+func TestNewRateLimiter(t *testing.T) {
+	limiter, err := httputils.NewRateLimiter(httputils.RateLimitConfiguration{}, nil)
+	helpers.FailOnError(t, err)
+	assert.IsType(t, &httputils.TokenBucketRateLimiter{}, limiter)
+
+	client, _ := redismock.NewClientMock()
+	limiter, err = httputils.NewRateLimiter(
+		httputils.RateLimitConfiguration{Backend: httputils.RedisRateLimitBackend}, &redis.Client{Connection: client})
+	helpers.FailOnError(t, err)
+	assert.IsType(t, &httputils.RedisRateLimiter{}, limiter)
+
+	_, err = httputils.NewRateLimiter(httputils.RateLimitConfiguration{Backend: httputils.RedisRateLimitBackend}, nil)
+	assert.EqualError(t, err, "Redis client is needed by redis rate limit backend")
+
+	_, err = httputils.NewRateLimiter(httputils.RateLimitConfiguration{Backend: "etcd"}, nil)
+	assert.EqualError(t, err, "unsupported rate limit backend 'etcd'")
+}
@@ -0,0 +1,115 @@
+// Copyright 2026 Red Hat, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httputils
+
+// Documentation in literate-programming-style is available at:
+// https://redhatinsights.github.io/insights-operator-utils/packages/http/request_id.html
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+
+	"github.com/RedHatInsights/insights-operator-utils/types"
+)
+
+// RequestIDHeader is the header carrying ID of the request
+const RequestIDHeader = types.RequestIDHeader
+
+const (
+	// ContextKeyRequestID is a key of request ID stored in request context
+	ContextKeyRequestID = ContextKey("request_id")
+
+	requestIDField = "request_id"
+)
+
+// RequestIDValidator matches request IDs accepted from clients, other IDs are
+// replaced by a generated one to prevent log injection
+var RequestIDValidator = regexp.MustCompile(`^[a-zA-Z0-9._-]{1,128}$`)
+
+// RequestID is a middleware that reads ID of the request from X-Request-Id
+// header or generates a new one. The ID is stored in request context together
+// with a logger that adds the ID to all messages, and it is sent back in
+// X-Request-Id response header.
+func RequestID(nextHandler http.Handler) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		requestID := request.Header.Get(RequestIDHeader)
+		if !RequestIDValidator.MatchString(requestID) {
+			requestID = uuid.NewString()
+		}
+
+		writer.Header().Set(RequestIDHeader, requestID)
+
+		logger := log.With().Str(requestIDField, requestID).Logger()
+		ctx := context.WithValue(request.Context(), ContextKeyRequestID, requestID)
+		ctx = logger.WithContext(ctx)
+
+		nextHandler.ServeHTTP(writer, request.WithContext(ctx))
+	})
+}
+
+// GetRequestID returns ID of the request stored in the context by RequestID
+// middleware or an empty string
+func GetRequestID(ctx context.Context) string {
+	requestID, _ := ctx.Value(ContextKeyRequestID).(string)
+	return requestID
+}
+
+// GetLogger returns the request scoped logger stored in the context by
+// RequestID middleware. The global logger is returned when there is none.
+func GetLogger(ctx context.Context) *zerolog.Logger {
+	logger := zerolog.Ctx(ctx)
+	if logger.GetLevel() == zerolog.Disabled {
+		return &log.Logger
+	}
+	return logger
+}
+
+// AccessLog is a middleware that logs one message per request with method,
+// path, status code, response size, duration and organization ID. The
+// organization ID is filled in by Authenticate middleware, so AccessLog
+// needs to be placed before it, and after RequestID middleware to include
+// the request ID.
+func AccessLog(nextHandler http.Handler) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		entry, request := withRequestEntry(request)
+		recorder := &responseRecorder{ResponseWriter: writer}
+
+		startTime := time.Now()
+		nextHandler.ServeHTTP(recorder, request)
+		duration := time.Since(startTime)
+
+		logger := GetLogger(request.Context())
+		event := logger.Info()
+		if recorder.StatusCode() >= http.StatusInternalServerError {
+			event = logger.Error()
+		}
+		if entry.orgID != 0 {
+			event = event.Uint32("org_id", uint32(entry.orgID))
+		}
+		event.
+			Str("method", request.Method).
+			Str("path", request.URL.Path).
+			Int("status", recorder.StatusCode()).
+			Int("bytes", recorder.size).
+			Dur("duration", duration).
+			Msg("Request handled")
+	})
+}
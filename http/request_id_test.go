@@ -0,0 +1,178 @@
+// Copyright 2026 Red Hat, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httputils_test
+
+// Documentation in literate-programming-style is available at:
+// https://redhatinsights.github.io/insights-operator-utils/packages/http/request_id_test.html
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+
+	httputils "github.com/RedHatInsights/insights-operator-utils/http"
+	"github.com/RedHatInsights/insights-operator-utils/tests/helpers"
+)
+
+// captureLog redirects the global logger to a buffer for the rest of the test
+func captureLog(t *testing.T) *bytes.Buffer {
+	buffer := new(bytes.Buffer)
+	original := log.Logger
+	log.Logger = zerolog.New(buffer)
+	t.Cleanup(func() { log.Logger = original })
+	return buffer
+}
+
+// logLines parses all JSON messages from the buffer
+func logLines(t *testing.T, buffer *bytes.Buffer) []map[string]interface{} {
+	var lines []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buffer.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var parsed map[string]interface{}
+		helpers.FailOnError(t, json.Unmarshal([]byte(line), &parsed))
+		lines = append(lines, parsed)
+	}
+	return lines
+}
+
+func TestRequestIDFromHeader(t *testing.T) {
+	var requestID string
+	handler := httputils.RequestID(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		requestID = httputils.GetRequestID(request.Context())
+	}))
+
+	request := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+	request.Header.Set(httputils.RequestIDHeader, "request-42")
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+
+	assert.Equal(t, "request-42", requestID)
+	assert.Equal(t, "request-42", recorder.Header().Get(httputils.RequestIDHeader))
+}
+
+func TestRequestIDGenerated(t *testing.T) {
+	for _, header := range []string{"", "invalid\nid", strings.Repeat("a", 129)} {
+		var requestID string
+		handler := httputils.RequestID(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			requestID = httputils.GetRequestID(request.Context())
+		}))
+
+		request := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+		request.Header.Set(httputils.RequestIDHeader, header)
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+
+		assert.Len(t, requestID, 36)
+		assert.Equal(t, requestID, recorder.Header().Get(httputils.RequestIDHeader))
+	}
+}
+
+func TestRequestIDLogger(t *testing.T) {
+	buffer := captureLog(t)
+
+	handler := httputils.RequestID(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		httputils.GetLogger(request.Context()).Info().Msg("handling")
+	}))
+	request := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+	request.Header.Set(httputils.RequestIDHeader, "request-42")
+	handler.ServeHTTP(httptest.NewRecorder(), request)
+
+	lines := logLines(t, buffer)
+	assert.Len(t, lines, 1)
+	assert.Equal(t, "request-42", lines[0]["request_id"])
+}
+
+func TestGetLoggerWithoutRequestID(t *testing.T) {
+	request := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+	assert.Equal(t, &log.Logger, httputils.GetLogger(request.Context()))
+	assert.Empty(t, httputils.GetRequestID(request.Context()))
+}
+
+func TestAccessLog(t *testing.T) {
+	buffer := captureLog(t)
+
+	authenticate, err := httputils.Authenticate(httputils.AuthenticationConfiguration{})
+	helpers.FailOnError(t, err)
+
+	handler := httputils.RequestID(httputils.AccessLog(authenticate(
+		http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			_, _ = writer.Write([]byte("report"))
+		}),
+	)))
+
+	request := httptest.NewRequest(http.MethodGet, "/api/v1/report", http.NoBody)
+	request.Header.Set(httputils.RequestIDHeader, "request-42")
+	request.Header.Set(httputils.XRHIdentityHeader, makeIdentityToken(t, testOrgID, "User"))
+	handler.ServeHTTP(httptest.NewRecorder(), request)
+
+	lines := logLines(t, buffer)
+	assert.Len(t, lines, 1)
+	assert.Equal(t, "Request handled", lines[0]["message"])
+	assert.Equal(t, "info", lines[0]["level"])
+	assert.Equal(t, "request-42", lines[0]["request_id"])
+	assert.Equal(t, "GET", lines[0]["method"])
+	assert.Equal(t, "/api/v1/report", lines[0]["path"])
+	assert.Equal(t, float64(http.StatusOK), lines[0]["status"])
+	assert.Equal(t, float64(len("report")), lines[0]["bytes"])
+	assert.Equal(t, float64(testOrgID), lines[0]["org_id"])
+	assert.Contains(t, lines[0], "duration")
+}
+
+func TestAccessLogServerError(t *testing.T) {
+	buffer := captureLog(t)
+
+	handler := httputils.AccessLog(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.WriteHeader(http.StatusServiceUnavailable)
+		writer.WriteHeader(http.StatusOK)
+	}))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/", http.NoBody))
+
+	lines := logLines(t, buffer)
+	assert.Len(t, lines, 1)
+	assert.Equal(t, "error", lines[0]["level"])
+	assert.Equal(t, float64(http.StatusServiceUnavailable), lines[0]["status"])
+	assert.NotContains(t, lines[0], "org_id")
+}
+
+func TestSendRequestForwardsRequestID(t *testing.T) {
+	var forwarded string
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		forwarded = request.Header.Get(httputils.RequestIDHeader)
+	}))
+	defer server.Close()
+
+	handler := httputils.RequestID(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		outgoing, err := http.NewRequestWithContext(request.Context(), http.MethodGet, server.URL, http.NoBody)
+		helpers.FailOnError(t, err)
+		_, err = httputils.SendRequest(outgoing, time.Second)
+		helpers.FailOnError(t, err)
+	}))
+
+	request := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+	request.Header.Set(httputils.RequestIDHeader, "request-42")
+	handler.ServeHTTP(httptest.NewRecorder(), request)
+
+	assert.Equal(t, "request-42", forwarded)
+}
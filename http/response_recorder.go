@@ -0,0 +1,166 @@
+// Copyright 2026 Red Hat, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httputils
+
+// Documentation in literate-programming-style is available at:
+// https://redhatinsights.github.io/insights-operator-utils/packages/http/response_recorder.html
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"net"
+	"net/http"
+
+	ctypes "github.com/RedHatInsights/insights-results-types"
+)
+
+const contextKeyRequestEntry = ContextKey("request_entry")
+
+// requestEntry collects data about the request that are known only to the
+// inner handlers, it is shared by AccessLog and LogRequest middlewares
+type requestEntry struct {
+	orgID ctypes.OrgID
+}
+
+// withRequestEntry returns the entry stored in the request context, a new
+// entry is stored if there is none
+func withRequestEntry(request *http.Request) (*requestEntry, *http.Request) {
+	if entry, ok := request.Context().Value(contextKeyRequestEntry).(*requestEntry); ok {
+		return entry, request
+	}
+	entry := &requestEntry{}
+	ctx := context.WithValue(request.Context(), contextKeyRequestEntry, entry)
+	return entry, request.WithContext(ctx)
+}
+
+// setRequestOrgID records organization ID of the request, if the entry is
+// stored in the context
+func setRequestOrgID(ctx context.Context, orgID ctypes.OrgID) {
+	if entry, ok := ctx.Value(contextKeyRequestEntry).(*requestEntry); ok {
+		entry.orgID = orgID
+	}
+}
+
+// responseRecorder remembers status code and size of the response. It keeps
+// the http.Flusher and http.Hijacker interfaces of the original writer
+// usable.
+type responseRecorder struct {
+	http.ResponseWriter
+	statusCode int
+	size       int
+}
+
+func (recorder *responseRecorder) WriteHeader(statusCode int) {
+	if recorder.statusCode == 0 {
+		recorder.statusCode = statusCode
+	}
+	recorder.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (recorder *responseRecorder) Write(data []byte) (int, error) {
+	if recorder.statusCode == 0 {
+		recorder.statusCode = http.StatusOK
+	}
+	size, err := recorder.ResponseWriter.Write(data)
+	recorder.size += size
+	return size, err
+}
+
+// Flush sends buffered data to the client if the original writer supports
+// it
+func (recorder *responseRecorder) Flush() {
+	if flusher, ok := recorder.ResponseWriter.(http.Flusher); ok {
+		if recorder.statusCode == 0 {
+			recorder.statusCode = http.StatusOK
+		}
+		flusher.Flush()
+	}
+}
+
+// Hijack lets the caller take over the connection if the original writer
+// supports it
+func (recorder *responseRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := recorder.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, http.ErrNotSupported
+	}
+	if recorder.statusCode == 0 {
+		recorder.statusCode = http.StatusSwitchingProtocols
+	}
+	return hijacker.Hijack()
+}
+
+// Unwrap returns the original writer, it is used by http.ResponseController
+func (recorder *responseRecorder) Unwrap() http.ResponseWriter {
+	return recorder.ResponseWriter
+}
+
+// StatusCode returns status code of the response, 200 is returned when the
+// handler didn't write anything
+func (recorder *responseRecorder) StatusCode() int {
+	if recorder.statusCode == 0 {
+		return http.StatusOK
+	}
+	return recorder.statusCode
+}
+
+// bufferedResponse keeps the whole response in memory, so it can be checked
+// or changed before it is sent to the client
+type bufferedResponse struct {
+	header     http.Header
+	statusCode int
+	body       bytes.Buffer
+}
+
+func newBufferedResponse() *bufferedResponse {
+	return &bufferedResponse{header: http.Header{}}
+}
+
+func (response *bufferedResponse) Header() http.Header {
+	return response.header
+}
+
+func (response *bufferedResponse) WriteHeader(statusCode int) {
+	if response.statusCode == 0 {
+		response.statusCode = statusCode
+	}
+}
+
+func (response *bufferedResponse) Write(data []byte) (int, error) {
+	if response.statusCode == 0 {
+		response.statusCode = http.StatusOK
+	}
+	return response.body.Write(data)
+}
+
+// StatusCode returns status code of the response, 200 is returned when the
+// handler didn't write anything
+func (response *bufferedResponse) StatusCode() int {
+	if response.statusCode == 0 {
+		return http.StatusOK
+	}
+	return response.statusCode
+}
+
+// sendTo writes the buffered response to the writer
+func (response *bufferedResponse) sendTo(writer http.ResponseWriter) error {
+	for key, values := range response.header {
+		writer.Header()[key] = values
+	}
+	writer.WriteHeader(response.StatusCode())
+	_, err := writer.Write(response.body.Bytes())
+	return err
+}
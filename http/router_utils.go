@@ -21,6 +21,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"regexp"
 	"strconv"
@@ -229,28 +230,23 @@ func ReadClusterNames(writer http.ResponseWriter, request *http.Request) ([]ctyp
 	return clusterNamesConverted, true
 }
 
-// parseAndValidateOrgID parses and validates a single organization ID string.
-func parseAndValidateOrgID(writer http.ResponseWriter, orgStr string) (ctypes.OrgID, bool) {
+// ValidateOrgID checks that the organization ID is an unsigned 32-bit
+// integer. Converted organization ID is returned if everything is okay,
+// otherwise an error is returned.
+func ValidateOrgID(orgStr string) (ctypes.OrgID, error) {
 	v, err := strconv.ParseUint(orgStr, 10, 64)
-	if err != nil {
-		handleOrgIDParsingError(writer, orgStr, "integer array expected")
-		return 0, false
-	}
-	orgInt, err := types.Uint64ToUint32(v)
-	if err != nil {
-		handleOrgIDParsingError(writer, orgStr, "integer array expected")
-		return 0, false
+	if err == nil {
+		var orgInt uint32
+		orgInt, err = types.Uint64ToUint32(v)
+		if err == nil {
+			return ctypes.OrgID(orgInt), nil
+		}
 	}
-	return ctypes.OrgID(orgInt), true
-}
-
-// handleOrgIDParsingError handles the error for parsing organization IDs.
-func handleOrgIDParsingError(writer http.ResponseWriter, orgStr, errString string) {
-	types.HandleServerError(writer, &types.RouterParsingError{
+	return 0, &types.RouterParsingError{
 		ParamName:  "organizations",
 		ParamValue: orgStr,
-		ErrString:  errString,
-	})
+		ErrString:  "integer array expected",
+	}
 }
 
 // ReadOrganizationIDs does the same as `readOrganizationID`, except for multiple organizations.
@@ -263,8 +259,9 @@ func ReadOrganizationIDs(writer http.ResponseWriter, request *http.Request) ([]c
 
 	organizationsConverted := make([]ctypes.OrgID, 0)
 	for _, orgStr := range SplitRequestParamArray(organizationsParam) {
-		orgID, ok := parseAndValidateOrgID(writer, orgStr)
-		if !ok {
+		orgID, err := ValidateOrgID(orgStr)
+		if err != nil {
+			types.HandleServerError(writer, err)
 			return []ctypes.OrgID{}, false
 		}
 		organizationsConverted = append(organizationsConverted, orgID)
@@ -352,21 +349,36 @@ func ReadClusterListFromPath(writer http.ResponseWriter, request *http.Request)
 	return clusterList, true
 }
 
+// MaxClusterListBodySize is the maximum size of request body read by
+// ReadClusterListFromBody, it's generous enough for large lists of clusters
+const MaxClusterListBodySize int64 = 10 << 20
+
 // ReadClusterListFromBody retrieves list of clusters from request's body
-// if it's not possible, it writes http error to the writer and returns false
+// if it's not possible, it writes http error to the writer and returns false.
+// Content type of the body is not checked and its size is limited by
+// MaxClusterListBodySize, ReadJSONBody can be used for stricter validation.
 func ReadClusterListFromBody(writer http.ResponseWriter, request *http.Request) ([]string, bool) {
 	var clusterList ctypes.ClusterListInRequest
 
-	// check if there's any body provided in the request sent by client
-	if request.ContentLength <= 0 {
+	// check if there's any body provided in the request sent by client,
+	// chunked bodies have unknown length
+	if request.Body == nil || request.Body == http.NoBody {
 		err := &types.NoBodyError{}
 		types.HandleServerError(writer, err)
 		return []string{}, false
 	}
 
 	// try to read cluster list from request parameter
+	request.Body = http.MaxBytesReader(writer, request.Body, MaxClusterListBodySize)
 	err := json.NewDecoder(request.Body).Decode(&clusterList)
 	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		switch {
+		case errors.As(err, &maxBytesErr):
+			err = &types.PayloadTooLargeError{Limit: MaxClusterListBodySize}
+		case errors.Is(err, io.EOF):
+			err = &types.NoBodyError{}
+		}
 		types.HandleServerError(writer, err)
 		return []string{}, false
 	}
@@ -570,6 +570,40 @@ func TestReadClusterListFromBodyCorrectJSON(t *testing.T) {
 	assert.ElementsMatch(t, list, []string{cluster1ID, cluster2ID})
 }
 
+// TestReadClusterListFromBodyAnyContentType function checks that the list of
+// clusters is read regardless of the content type, e.g. from form sent by
+// curl -d, and that chunked bodies of unknown length are accepted.
+func TestReadClusterListFromBodyAnyContentType(t *testing.T) {
+	body := fmt.Sprintf(`{"clusters": ["%v", "%v"]}`, cluster1ID, cluster2ID)
+
+	request, err := http.NewRequest(http.MethodPost, "", io.NopCloser(strings.NewReader(body)))
+	helpers.FailOnError(t, err)
+	assert.Equal(t, int64(0), request.ContentLength)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	list, successful := httputils.ReadClusterListFromBody(httptest.NewRecorder(), request)
+	assert.True(t, successful)
+	assert.Equal(t, []string{cluster1ID, cluster2ID}, list)
+}
+
+// TestReadClusterListFromBodyTooLarge function checks that bodies larger
+// than MaxClusterListBodySize are refused with 413 Payload Too Large.
+func TestReadClusterListFromBodyTooLarge(t *testing.T) {
+	clusters := make([]string, httputils.MaxClusterListBodySize/int64(len(cluster1ID))+1)
+	for i := range clusters {
+		clusters[i] = cluster1ID
+	}
+	body := fmt.Sprintf(`{"clusters": ["%v"]}`, strings.Join(clusters, `","`))
+
+	request, err := http.NewRequest(http.MethodPost, "", io.NopCloser(strings.NewReader(body)))
+	helpers.FailOnError(t, err)
+
+	recorder := httptest.NewRecorder()
+	_, successful := httputils.ReadClusterListFromBody(recorder, request)
+	assert.False(t, successful)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, recorder.Code)
+}
+
 // TestReadClusterListFromBodyWrongJSON function checks if reading list of
 // clusters from request body with improper format is processed correctly by
 // function ReadClusterListFromBody.
@@ -0,0 +1,91 @@
+// Copyright 2026 Red Hat, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httputils
+
+// Documentation in literate-programming-style is available at:
+// https://redhatinsights.github.io/insights-operator-utils/packages/http/security_headers.html
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// Names of headers set by SecurityHeaders middleware
+const (
+	StrictTransportSecurityHeader = "Strict-Transport-Security"
+	ContentTypeOptionsHeader      = "X-Content-Type-Options"
+	FrameOptionsHeader            = "X-Frame-Options"
+	ContentSecurityPolicyHeader   = "Content-Security-Policy"
+)
+
+// Default values used for unset fields of SecurityHeadersConfiguration. The
+// policy is suitable for JSON APIs that are never rendered by browsers.
+const (
+	DefaultHSTSMaxAge            = 365 * 24 * time.Hour
+	DefaultFrameOptions          = "DENY"
+	DefaultContentSecurityPolicy = "default-src 'none'; frame-ancestors 'none'"
+)
+
+// SecurityHeadersConfiguration represents configuration of SecurityHeaders
+// middleware
+type SecurityHeadersConfiguration struct {
+	// DisableHSTS turns off Strict-Transport-Security header, e.g. for
+	// services not reachable over HTTPS
+	DisableHSTS           bool          `mapstructure:"disable_hsts" toml:"disable_hsts"`
+	HSTSMaxAge            time.Duration `mapstructure:"hsts_max_age" toml:"hsts_max_age"`
+	HSTSIncludeSubdomains bool          `mapstructure:"hsts_include_subdomains" toml:"hsts_include_subdomains"`
+	// FrameOptions is the value of X-Frame-Options, "DENY" or
+	// "SAMEORIGIN"
+	FrameOptions          string `mapstructure:"frame_options" toml:"frame_options"`
+	ContentSecurityPolicy string `mapstructure:"content_security_policy" toml:"content_security_policy"`
+}
+
+// SecurityHeaders creates a middleware setting Strict-Transport-Security,
+// X-Content-Type-Options, X-Frame-Options and Content-Security-Policy
+// headers of all responses. Handlers can still override the values, e.g. to
+// relax the policy for a page rendered by browsers.
+func SecurityHeaders(configuration SecurityHeadersConfiguration) mux.MiddlewareFunc {
+	if configuration.HSTSMaxAge <= 0 {
+		configuration.HSTSMaxAge = DefaultHSTSMaxAge
+	}
+	if configuration.FrameOptions == "" {
+		configuration.FrameOptions = DefaultFrameOptions
+	}
+	if configuration.ContentSecurityPolicy == "" {
+		configuration.ContentSecurityPolicy = DefaultContentSecurityPolicy
+	}
+
+	hsts := fmt.Sprintf("max-age=%d", int64(configuration.HSTSMaxAge.Seconds()))
+	if configuration.HSTSIncludeSubdomains {
+		hsts += "; includeSubDomains"
+	}
+
+	return func(nextHandler http.Handler) http.Handler {
+		return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			header := writer.Header()
+			if !configuration.DisableHSTS {
+				header.Set(StrictTransportSecurityHeader, hsts)
+			}
+			header.Set(ContentTypeOptionsHeader, "nosniff")
+			header.Set(FrameOptionsHeader, configuration.FrameOptions)
+			header.Set(ContentSecurityPolicyHeader, configuration.ContentSecurityPolicy)
+
+			nextHandler.ServeHTTP(writer, request)
+		})
+	}
+}
@@ -0,0 +1,71 @@
+// Copyright 2026 Red Hat, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httputils_test
+
+// Documentation in literate-programming-style is available at:
+// https://redhatinsights.github.io/insights-operator-utils/packages/http/security_headers_test.html
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	httputils "github.com/RedHatInsights/insights-operator-utils/http"
+)
+
+func serveWithSecurityHeaders(configuration httputils.SecurityHeadersConfiguration, handler http.HandlerFunc) http.Header {
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodGet, "/api/v1/report", http.NoBody)
+	httputils.SecurityHeaders(configuration)(handler).ServeHTTP(recorder, request)
+	return recorder.Header()
+}
+
+func TestSecurityHeadersDefaults(t *testing.T) {
+	header := serveWithSecurityHeaders(httputils.SecurityHeadersConfiguration{},
+		func(http.ResponseWriter, *http.Request) {})
+
+	assert.Equal(t, "max-age=31536000", header.Get(httputils.StrictTransportSecurityHeader))
+	assert.Equal(t, "nosniff", header.Get(httputils.ContentTypeOptionsHeader))
+	assert.Equal(t, "DENY", header.Get(httputils.FrameOptionsHeader))
+	assert.Equal(t, "default-src 'none'; frame-ancestors 'none'", header.Get(httputils.ContentSecurityPolicyHeader))
+}
+
+func TestSecurityHeadersConfiguration(t *testing.T) {
+	header := serveWithSecurityHeaders(httputils.SecurityHeadersConfiguration{
+		HSTSMaxAge:            24 * time.Hour,
+		HSTSIncludeSubdomains: true,
+		FrameOptions:          "SAMEORIGIN",
+		ContentSecurityPolicy: "default-src 'self'",
+	}, func(http.ResponseWriter, *http.Request) {})
+
+	assert.Equal(t, "max-age=86400; includeSubDomains", header.Get(httputils.StrictTransportSecurityHeader))
+	assert.Equal(t, "SAMEORIGIN", header.Get(httputils.FrameOptionsHeader))
+	assert.Equal(t, "default-src 'self'", header.Get(httputils.ContentSecurityPolicyHeader))
+
+	header = serveWithSecurityHeaders(httputils.SecurityHeadersConfiguration{DisableHSTS: true},
+		func(http.ResponseWriter, *http.Request) {})
+	assert.Empty(t, header.Get(httputils.StrictTransportSecurityHeader))
+}
+
+func TestSecurityHeadersOverriddenByHandler(t *testing.T) {
+	header := serveWithSecurityHeaders(httputils.SecurityHeadersConfiguration{},
+		func(writer http.ResponseWriter, _ *http.Request) {
+			writer.Header().Set(httputils.ContentSecurityPolicyHeader, "default-src 'self'")
+		})
+	assert.Equal(t, "default-src 'self'", header.Get(httputils.ContentSecurityPolicyHeader))
+}
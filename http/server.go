@@ -0,0 +1,303 @@
+// Copyright 2026 Red Hat, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httputils
+
+// Documentation in literate-programming-style is available at:
+// https://redhatinsights.github.io/insights-operator-utils/packages/http/server.html
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/rs/zerolog/log"
+
+	"github.com/RedHatInsights/insights-operator-utils/responses"
+	"github.com/RedHatInsights/insights-operator-utils/types"
+)
+
+// Names of standard endpoints registered by NewServer under the API prefix
+const (
+	OpenAPIEndpoint = "openapi.json"
+	MetricsEndpoint = "metrics"
+	HealthEndpoint  = "health"
+)
+
+// Default values used for unset fields of ServerConfiguration
+const (
+	DefaultDrainTimeout      = 30 * time.Second
+	DefaultReadHeaderTimeout = 3 * time.Second
+)
+
+// ServerConfiguration represents configuration of HTTP server, it's defined
+// in types package so the configuration can be used without this package
+type ServerConfiguration = types.ServerConfiguration
+
+// HealthCheck checks a dependency of the service, e.g. connection to
+// database
+type HealthCheck func(ctx context.Context) error
+
+type namedHealthCheck struct {
+	name  string
+	check HealthCheck
+}
+
+// Server is HTTP server with the routes and middlewares shared by all
+// services: RequestID, AccessLog and LogRequest middlewares, OpenAPI
+// specification, Prometheus metrics and health endpoints. It shuts down
+// gracefully: health endpoint reports the shutdown for ReadinessDelay while
+// requests are still accepted, then requests in flight are given
+// DrainTimeout to finish.
+type Server struct {
+	// Router can be used to register endpoints and middlewares
+	Router *mux.Router
+
+	configuration ServerConfiguration
+	httpServer    *http.Server
+	metricsServer *http.Server
+
+	healthChecksMutex sync.RWMutex
+	healthChecks      []namedHealthCheck
+
+	inFlight     atomic.Int64
+	shuttingDown atomic.Bool
+}
+
+// StandardMiddlewares returns the middlewares used by NewServer: RequestID,
+// AccessLog and LogRequest
+func StandardMiddlewares() []mux.MiddlewareFunc {
+	return []mux.MiddlewareFunc{RequestID, AccessLog, LogRequest}
+}
+
+// NewServer creates server with the standard routes and middlewares. The
+// metrics are served by a separate server when MetricsAddress differs from
+// Address.
+func NewServer(configuration ServerConfiguration) *Server {
+	return NewServerWithMiddlewares(configuration, StandardMiddlewares()...)
+}
+
+// NewServerWithMiddlewares is like NewServer, but the given middlewares are
+// used instead of the standard ones
+func NewServerWithMiddlewares(configuration ServerConfiguration, middlewares ...mux.MiddlewareFunc) *Server {
+	if configuration.DrainTimeout <= 0 {
+		configuration.DrainTimeout = DefaultDrainTimeout
+	}
+	if configuration.MetricsPath == "" {
+		configuration.MetricsPath = apiPath(configuration.APIPrefix, MetricsEndpoint)
+	}
+
+	server := &Server{
+		Router:        mux.NewRouter().StrictSlash(true),
+		configuration: configuration,
+	}
+	server.httpServer = &http.Server{
+		Addr:              configuration.Address,
+		Handler:           server.Router,
+		ReadHeaderTimeout: DefaultReadHeaderTimeout,
+	}
+
+	server.Router.Use(middlewares...)
+	server.Router.Use(server.trackInFlight)
+
+	if configuration.MetricsAddress != "" && configuration.MetricsAddress != configuration.Address {
+		metricsRouter := mux.NewRouter()
+		metricsRouter.Handle(configuration.MetricsPath, promhttp.Handler()).Methods(http.MethodGet)
+		server.metricsServer = &http.Server{
+			Addr:              configuration.MetricsAddress,
+			Handler:           metricsRouter,
+			ReadHeaderTimeout: DefaultReadHeaderTimeout,
+		}
+	} else {
+		server.Router.Handle(configuration.MetricsPath, promhttp.Handler()).Methods(http.MethodGet)
+	}
+
+	if configuration.APISpecFile != "" {
+		server.Router.HandleFunc(
+			apiPath(configuration.APIPrefix, OpenAPIEndpoint),
+			CreateOpenAPIHandler(configuration.APISpecFile, configuration.Debug, true),
+		).Methods(http.MethodGet)
+	}
+	server.Router.HandleFunc(apiPath(configuration.APIPrefix, HealthEndpoint), server.health).Methods(http.MethodGet)
+
+	return server
+}
+
+// apiPath joins the API prefix and the endpoint name
+func apiPath(apiPrefix, endpoint string) string {
+	return strings.TrimSuffix(apiPrefix, "/") + "/" + endpoint
+}
+
+// Handler returns the handler of API requests
+func (server *Server) Handler() http.Handler {
+	return server.Router
+}
+
+// HTTPServer returns the underlying server of API requests
+func (server *Server) HTTPServer() *http.Server {
+	return server.httpServer
+}
+
+// AddEndpoint registers the handler for the endpoint under the API prefix,
+// the path is joined like in Handle. GET method is used when no methods are
+// provided.
+func (server *Server) AddEndpoint(endpoint string, handler http.HandlerFunc, methods ...string) *mux.Route {
+	if len(methods) == 0 {
+		methods = []string{http.MethodGet}
+	}
+	return server.Router.HandleFunc(
+		apiPath(server.configuration.APIPrefix, strings.TrimLeft(endpoint, "/")), handler,
+	).Methods(methods...)
+}
+
+// Handle registers the handler for the typed endpoint under the API prefix
+func (server *Server) Handle(endpoint Endpoint, handler http.HandlerFunc) *mux.Route {
+	return endpoint.Register(server.Router, server.configuration.APIPrefix, handler)
+}
+
+// AddHealthCheck registers a check run by the health endpoint
+func (server *Server) AddHealthCheck(name string, check HealthCheck) {
+	server.healthChecksMutex.Lock()
+	defer server.healthChecksMutex.Unlock()
+
+	server.healthChecks = append(server.healthChecks, namedHealthCheck{name: name, check: check})
+}
+
+// InFlight returns the number of API requests being handled
+func (server *Server) InFlight() int64 {
+	return server.inFlight.Load()
+}
+
+func (server *Server) trackInFlight(nextHandler http.Handler) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		server.inFlight.Add(1)
+		defer server.inFlight.Add(-1)
+		nextHandler.ServeHTTP(writer, request)
+	})
+}
+
+// health responds with 503 Service Unavailable when the server is shutting
+// down or when any of the health checks fails
+func (server *Server) health(writer http.ResponseWriter, request *http.Request) {
+	var err error
+	if server.shuttingDown.Load() {
+		err = responses.SendServiceUnavailable(writer, "server is shutting down")
+	} else if checkErr := server.checkHealth(request.Context()); checkErr != nil {
+		log.Error().Err(checkErr).Msg("Health check failed")
+		err = responses.SendServiceUnavailable(writer, checkErr.Error())
+	} else {
+		err = responses.SendOK(writer, responses.BuildOkResponse())
+	}
+	if err != nil {
+		log.Error().Err(err).Msg("Unable to send health check response")
+	}
+}
+
+func (server *Server) checkHealth(ctx context.Context) error {
+	server.healthChecksMutex.RLock()
+	defer server.healthChecksMutex.RUnlock()
+
+	for _, healthCheck := range server.healthChecks {
+		if err := healthCheck.check(ctx); err != nil {
+			return fmt.Errorf("%s: %w", healthCheck.name, err)
+		}
+	}
+	return nil
+}
+
+// Run starts the server and blocks until the context is canceled, SIGTERM
+// or SIGINT is received or the server fails. The server is shut down
+// gracefully in all cases.
+func (server *Server) Run(ctx context.Context) error {
+	listener, err := net.Listen("tcp", server.configuration.Address)
+	if err != nil {
+		return err
+	}
+	return server.Serve(ctx, listener)
+}
+
+// Serve is like Run, but API requests are accepted on the provided listener
+func (server *Server) Serve(ctx context.Context, listener net.Listener) error {
+	ctx, stop := signal.NotifyContext(ctx, syscall.SIGTERM, os.Interrupt)
+	defer stop()
+
+	serverErrors := make(chan error, 2)
+	go func() {
+		log.Info().Str("address", listener.Addr().String()).Msg("Starting HTTP server")
+		serverErrors <- server.httpServer.Serve(listener)
+	}()
+	if server.metricsServer != nil {
+		go func() {
+			log.Info().Str("address", server.metricsServer.Addr).Msg("Starting metrics HTTP server")
+			serverErrors <- server.metricsServer.ListenAndServe()
+		}()
+	}
+
+	var runErr error
+	select {
+	case <-ctx.Done():
+		log.Info().Msg("Shutting down HTTP server")
+	case runErr = <-serverErrors:
+		log.Error().Err(runErr).Msg("HTTP server failed")
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(
+		context.Background(), server.configuration.ReadinessDelay+server.configuration.DrainTimeout,
+	)
+	defer cancel()
+	return errors.Join(runErr, server.Shutdown(shutdownCtx))
+}
+
+// Shutdown makes health endpoint report the shutdown, waits for
+// ReadinessDelay, then stops accepting new connections and waits until
+// requests in flight are finished. Connections are closed when the context
+// expires before that.
+func (server *Server) Shutdown(ctx context.Context) error {
+	server.shuttingDown.Store(true)
+
+	if server.configuration.ReadinessDelay > 0 {
+		log.Info().Dur("readiness_delay", server.configuration.ReadinessDelay).
+			Msg("Waiting for load balancers to notice the shutdown")
+		timer := time.NewTimer(server.configuration.ReadinessDelay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+		}
+	}
+
+	var result error
+	for _, httpServer := range []*http.Server{server.httpServer, server.metricsServer} {
+		if httpServer == nil {
+			continue
+		}
+		if err := httpServer.Shutdown(ctx); err != nil {
+			log.Warn().Err(err).Int64("in_flight", server.InFlight()).
+				Msg("Requests in flight didn't finish in time, closing connections")
+			result = errors.Join(result, err, httpServer.Close())
+		}
+	}
+	return result
+}
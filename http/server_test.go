@@ -0,0 +1,241 @@
+// Copyright 2026 Red Hat, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httputils_test
+
+// Documentation in literate-programming-style is available at:
+// https://redhatinsights.github.io/insights-operator-utils/packages/http/server_test.html
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+
+	httputils "github.com/RedHatInsights/insights-operator-utils/http"
+	"github.com/RedHatInsights/insights-operator-utils/tests/helpers"
+)
+
+const serverAPIPrefix = "/api/v1/"
+
+func serve(server *httputils.Server, method, path string) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	server.Handler().ServeHTTP(recorder, httptest.NewRequest(method, path, http.NoBody))
+	return recorder
+}
+
+func TestServerStandardRoutes(t *testing.T) {
+	specFile := filepath.Join(t.TempDir(), "openapi.json")
+	helpers.FailOnError(t, os.WriteFile(specFile, []byte(baseFragment), 0o600))
+
+	server := httputils.NewServer(httputils.ServerConfiguration{
+		APIPrefix:   serverAPIPrefix,
+		APISpecFile: specFile,
+	})
+
+	recorder := serve(server, http.MethodGet, "/api/v1/health")
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.JSONEq(t, `{"status":"ok"}`, recorder.Body.String())
+	assert.NotEmpty(t, recorder.Header().Get(httputils.RequestIDHeader))
+
+	recorder = serve(server, http.MethodGet, "/api/v1/metrics")
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "api_endpoints_requests")
+
+	recorder = serve(server, http.MethodGet, "/api/v1/openapi.json")
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), `"openapi"`)
+}
+
+func TestServerSeparateMetricsAddress(t *testing.T) {
+	server := httputils.NewServer(httputils.ServerConfiguration{
+		Address:        ":8080",
+		MetricsAddress: ":9000",
+		APIPrefix:      serverAPIPrefix,
+	})
+
+	assert.Equal(t, http.StatusNotFound, serve(server, http.MethodGet, "/api/v1/metrics").Code)
+	assert.Equal(t, http.StatusNotFound, serve(server, http.MethodGet, "/api/v1/openapi.json").Code)
+}
+
+func TestServerHandleEndpoint(t *testing.T) {
+	server := httputils.NewServer(httputils.ServerConfiguration{APIPrefix: serverAPIPrefix})
+	endpoint := httputils.MustNewEndpoint(http.MethodDelete, "clusters/{cluster}")
+	server.Handle(endpoint, func(writer http.ResponseWriter, request *http.Request) {
+		assert.Equal(t, "cluster 1", mux.Vars(request)["cluster"])
+		writer.WriteHeader(http.StatusNoContent)
+	})
+
+	endpointURL, err := endpoint.URL(serverAPIPrefix, "cluster 1")
+	helpers.FailOnError(t, err)
+	assert.Equal(t, http.StatusNoContent, serve(server, http.MethodDelete, endpointURL).Code)
+	assert.Equal(t, http.StatusMethodNotAllowed, serve(server, http.MethodGet, endpointURL).Code)
+}
+
+func TestServerHealthChecks(t *testing.T) {
+	server := httputils.NewServer(httputils.ServerConfiguration{APIPrefix: serverAPIPrefix})
+	server.AddHealthCheck("cache", func(context.Context) error { return nil })
+	server.AddHealthCheck("database", func(context.Context) error { return errors.New("connection refused") })
+
+	recorder := serve(server, http.MethodGet, "/api/v1/health")
+	assert.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+	assert.JSONEq(t, `{"status":"database: connection refused"}`, recorder.Body.String())
+}
+
+func TestServerAddEndpoint(t *testing.T) {
+	server := httputils.NewServer(httputils.ServerConfiguration{APIPrefix: serverAPIPrefix})
+	server.AddEndpoint("clusters", func(writer http.ResponseWriter, _ *http.Request) {
+		writer.WriteHeader(http.StatusAccepted)
+	}, http.MethodPost)
+
+	assert.Equal(t, http.StatusAccepted, serve(server, http.MethodPost, "/api/v1/clusters").Code)
+	assert.Equal(t, http.StatusMethodNotAllowed, serve(server, http.MethodGet, "/api/v1/clusters").Code)
+
+	// paths are joined like the ones of typed endpoints
+	server = httputils.NewServer(httputils.ServerConfiguration{APIPrefix: "/api/v1"})
+	server.AddEndpoint("/report", func(http.ResponseWriter, *http.Request) {})
+	assert.Equal(t, http.StatusOK, serve(server, http.MethodGet, "/api/v1/report").Code)
+}
+
+// startServer serves requests by the server until the returned function is
+// called, the function returns result of Serve
+func startServer(t *testing.T, server *httputils.Server) (string, func() error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	helpers.FailOnError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	result := make(chan error, 1)
+	go func() {
+		result <- server.Serve(ctx, listener)
+	}()
+
+	return "http://" + listener.Addr().String(), func() error {
+		cancel()
+		return <-result
+	}
+}
+
+// blockingEndpoint registers endpoint that doesn't respond until release is
+// closed
+func blockingEndpoint(server *httputils.Server, release chan struct{}) {
+	server.AddEndpoint("slow", func(writer http.ResponseWriter, _ *http.Request) {
+		<-release
+		writer.WriteHeader(http.StatusOK)
+	})
+}
+
+func waitForInFlight(t *testing.T, server *httputils.Server) {
+	assert.Eventually(t, func() bool { return server.InFlight() == 1 }, time.Second, time.Millisecond)
+}
+
+func TestServerGracefulShutdown(t *testing.T) {
+	server := httputils.NewServer(httputils.ServerConfiguration{APIPrefix: serverAPIPrefix})
+	release := make(chan struct{})
+	blockingEndpoint(server, release)
+
+	url, stop := startServer(t, server)
+
+	responses := make(chan *http.Response, 1)
+	go func() {
+		response, err := http.Get(url + "/api/v1/slow")
+		assert.NoError(t, err)
+		responses <- response
+	}()
+	waitForInFlight(t, server)
+
+	stopped := make(chan error, 1)
+	go func() { stopped <- stop() }()
+
+	// health check reports the shutdown, so no new requests are routed to
+	// the instance
+	assert.Eventually(t, func() bool {
+		return serve(server, http.MethodGet, "/api/v1/health").Code == http.StatusServiceUnavailable
+	}, time.Second, time.Millisecond)
+
+	close(release)
+	response := <-responses
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	helpers.FailOnError(t, response.Body.Close())
+	assert.NoError(t, <-stopped)
+	assert.Zero(t, server.InFlight())
+}
+
+func TestServerDrainTimeout(t *testing.T) {
+	server := httputils.NewServer(httputils.ServerConfiguration{
+		APIPrefix:    serverAPIPrefix,
+		DrainTimeout: 10 * time.Millisecond,
+	})
+	release := make(chan struct{})
+	blockingEndpoint(server, release)
+
+	url, stop := startServer(t, server)
+	go func() {
+		response, err := http.Get(url + "/api/v1/slow")
+		if err == nil {
+			_ = response.Body.Close()
+		}
+	}()
+	waitForInFlight(t, server)
+
+	assert.ErrorIs(t, stop(), context.DeadlineExceeded)
+
+	// the handler must not outlive the test
+	close(release)
+	assert.Eventually(t, func() bool { return server.InFlight() == 0 }, time.Second, time.Millisecond)
+}
+
+func TestServerReadinessDelay(t *testing.T) {
+	server := httputils.NewServer(httputils.ServerConfiguration{
+		APIPrefix:      serverAPIPrefix,
+		ReadinessDelay: time.Second,
+	})
+	server.AddEndpoint("report", func(writer http.ResponseWriter, _ *http.Request) {
+		writer.WriteHeader(http.StatusOK)
+	})
+
+	url, stop := startServer(t, server)
+	stopped := make(chan error, 1)
+	go func() { stopped <- stop() }()
+
+	assert.Eventually(t, func() bool {
+		response, err := http.Get(url + "/api/v1/health")
+		if err != nil {
+			return false
+		}
+		_ = response.Body.Close()
+		return response.StatusCode == http.StatusServiceUnavailable
+	}, time.Second, time.Millisecond)
+
+	// requests are still served while the health endpoint reports the
+	// shutdown
+	response, err := http.Get(url + "/api/v1/report")
+	helpers.FailOnError(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	helpers.FailOnError(t, response.Body.Close())
+
+	assert.NoError(t, <-stopped)
+}
+
+func TestServerRunError(t *testing.T) {
+	server := httputils.NewServer(httputils.ServerConfiguration{Address: "invalid:address:"})
+	assert.Error(t, server.Run(context.Background()))
+}
@@ -0,0 +1,304 @@
+/*
+Copyright © 2026 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kafka
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/IBM/sarama"
+	ctypes "github.com/RedHatInsights/insights-results-types"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rs/zerolog/log"
+	"github.com/santhosh-tekuri/jsonschema/v6"
+
+	"github.com/RedHatInsights/insights-operator-utils/metrics"
+)
+
+// Reasons of decoding failures, used as a value of "reason" label in the
+// kafka_message_decode_failures metric
+const (
+	ReasonInvalidJSON     = "invalid_json"
+	ReasonSchemaViolation = "schema_violation"
+	ReasonInvalidEnvelope = "invalid_envelope"
+	ReasonInvalidPayload  = "invalid_payload"
+)
+
+const (
+	schemaResourceName = "message-schema.json"
+	reasonLabel        = "reason"
+)
+
+// DecodingMode specifies how strictly the incoming messages are checked
+type DecodingMode int
+
+const (
+	// StrictMode rejects messages violating the schema, messages with
+	// missing or malformed envelope fields and messages with payload fields
+	// unknown to the target structure
+	StrictMode DecodingMode = iota
+	// LenientMode only rejects messages that are not a valid JSON or that
+	// can't be unmarshalled into the target structure. Other problems are
+	// logged and counted in metrics
+	LenientMode
+)
+
+// EnvelopeFields contains names of JSON attributes used to fill in the
+// Envelope structure. Empty name means that the attribute is not expected.
+type EnvelopeFields struct {
+	OrgID       string
+	ClusterName string
+	Timestamp   string
+	RequestID   string
+}
+
+var (
+	// RuleResultsFields describes messages with rule results produced by
+	// the data pipeline
+	RuleResultsFields = EnvelopeFields{
+		OrgID:       "OrgID",
+		ClusterName: "ClusterName",
+		Timestamp:   "LastChecked",
+		RequestID:   "RequestId",
+	}
+
+	// ArchiveNotificationFields describes notifications about new Insights
+	// Operator archives produced by the ingress service
+	ArchiveNotificationFields = EnvelopeFields{
+		OrgID:       "org_id",
+		ClusterName: "cluster_id",
+		Timestamp:   "timestamp",
+		RequestID:   "request_id",
+	}
+)
+
+// Envelope represents the common part of all messages consumed from Kafka
+type Envelope struct {
+	OrgID       ctypes.OrgID
+	ClusterName ctypes.ClusterName
+	Timestamp   ctypes.Timestamp
+	RequestID   ctypes.RequestID
+	// Payload contains the whole message as it was consumed
+	Payload json.RawMessage
+}
+
+// DecodeError is returned when a message can't be decoded or validated
+type DecodeError struct {
+	Reason string
+	Err    error
+}
+
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("unable to decode message (%s): %v", e.Reason, e.Err)
+}
+
+// Unwrap returns the underlying error
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// MessageCodec is an interface for all decoders of Kafka messages. The
+// target parameter can be nil if only the envelope is needed.
+type MessageCodec interface {
+	Decode(data []byte, target interface{}) (*Envelope, error)
+}
+
+// JSONCodec decodes JSON messages, optionally validating them against
+// a JSON Schema
+type JSONCodec struct {
+	Fields EnvelopeFields
+	Mode   DecodingMode
+	schema *jsonschema.Schema
+}
+
+// NewJSONCodec creates a codec for given envelope fields and mode. The schema
+// parameter contains JSON Schema document, an empty string disables the
+// schema validation.
+func NewJSONCodec(schema string, fields EnvelopeFields, mode DecodingMode) (*JSONCodec, error) {
+	codec := &JSONCodec{
+		Fields: fields,
+		Mode:   mode,
+	}
+
+	if schema == "" {
+		return codec, nil
+	}
+
+	schemaDoc, err := jsonschema.UnmarshalJSON(strings.NewReader(schema))
+	if err != nil {
+		return nil, err
+	}
+
+	compiler := jsonschema.NewCompiler()
+	if err := compiler.AddResource(schemaResourceName, schemaDoc); err != nil {
+		return nil, err
+	}
+
+	codec.schema, err = compiler.Compile(schemaResourceName)
+	if err != nil {
+		return nil, err
+	}
+
+	return codec, nil
+}
+
+// Decode validates the message, extracts its envelope and, if target is not
+// nil, unmarshals the whole message into target
+func (codec *JSONCodec) Decode(data []byte, target interface{}) (*Envelope, error) {
+	var attributes map[string]json.RawMessage
+	if err := json.Unmarshal(data, &attributes); err != nil {
+		return nil, decodeFailure(ReasonInvalidJSON, err)
+	}
+
+	if codec.schema != nil {
+		if err := codec.validate(data); err != nil {
+			if err := codec.handleProblem(ReasonSchemaViolation, err); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	envelope, err := codec.Fields.extract(attributes)
+	if err != nil {
+		if err := codec.handleProblem(ReasonInvalidEnvelope, err); err != nil {
+			return nil, err
+		}
+	}
+	envelope.Payload = data
+
+	if target != nil {
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		if codec.Mode == StrictMode {
+			decoder.DisallowUnknownFields()
+		}
+		if err := decoder.Decode(target); err != nil {
+			return nil, decodeFailure(ReasonInvalidPayload, err)
+		}
+	}
+
+	return envelope, nil
+}
+
+// DecodeMessage decodes the value of message consumed by Sarama
+func DecodeMessage(codec MessageCodec, msg *sarama.ConsumerMessage, target interface{}) (*Envelope, error) {
+	envelope, err := codec.Decode(msg.Value, target)
+	if err != nil {
+		log.Error().
+			Err(err).
+			Str("topic", msg.Topic).
+			Int32("partition", msg.Partition).
+			Int64("offset", msg.Offset).
+			Msg("Unable to decode message")
+	}
+	return envelope, err
+}
+
+func (codec *JSONCodec) validate(data []byte) error {
+	instance, err := jsonschema.UnmarshalJSON(bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	return codec.schema.Validate(instance)
+}
+
+// handleProblem returns an error in strict mode, otherwise it just logs the
+// problem. The problem is counted in metrics in both modes.
+func (codec *JSONCodec) handleProblem(reason string, err error) error {
+	if codec.Mode == StrictMode {
+		return decodeFailure(reason, err)
+	}
+
+	metrics.KafkaDecodeFailures.With(prometheus.Labels{reasonLabel: reason}).Inc()
+	log.Warn().Err(err).Str(reasonLabel, reason).Msg("Accepting message in lenient mode")
+	return nil
+}
+
+func decodeFailure(reason string, err error) error {
+	metrics.KafkaDecodeFailures.With(prometheus.Labels{reasonLabel: reason}).Inc()
+	return &DecodeError{Reason: reason, Err: err}
+}
+
+// extract fills in the envelope from message attributes. The envelope is
+// returned even when some of the fields are missing or malformed.
+func (fields EnvelopeFields) extract(attributes map[string]json.RawMessage) (*Envelope, error) {
+	envelope := &Envelope{}
+	var problems []string
+
+	if fields.OrgID != "" {
+		orgID, err := parseOrgID(attributes[fields.OrgID])
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", fields.OrgID, err))
+		}
+		envelope.OrgID = orgID
+	}
+
+	stringFields := []struct {
+		name string
+		dest *string
+	}{
+		{fields.ClusterName, (*string)(&envelope.ClusterName)},
+		{fields.Timestamp, (*string)(&envelope.Timestamp)},
+		{fields.RequestID, (*string)(&envelope.RequestID)},
+	}
+	for _, field := range stringFields {
+		if field.name == "" {
+			continue
+		}
+		if err := parseString(attributes[field.name], field.dest); err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", field.name, err))
+		}
+	}
+
+	if len(problems) > 0 {
+		return envelope, fmt.Errorf("malformed envelope: %s", strings.Join(problems, ", "))
+	}
+	return envelope, nil
+}
+
+// parseOrgID accepts organization ID encoded both as a number and as a string
+func parseOrgID(value json.RawMessage) (ctypes.OrgID, error) {
+	if value == nil {
+		return 0, fmt.Errorf("attribute is missing")
+	}
+
+	var asString string
+	if err := json.Unmarshal(value, &asString); err == nil {
+		value = json.RawMessage(asString)
+	}
+
+	orgID, err := strconv.ParseUint(string(value), 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("unsigned integer expected")
+	}
+	if orgID == 0 {
+		return 0, fmt.Errorf("positive value expected")
+	}
+	return ctypes.OrgID(orgID), nil
+}
+
+func parseString(value json.RawMessage, dest *string) error {
+	if value == nil {
+		return fmt.Errorf("attribute is missing")
+	}
+	if err := json.Unmarshal(value, dest); err != nil {
+		return fmt.Errorf("string expected")
+	}
+	return nil
+}
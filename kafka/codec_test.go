@@ -0,0 +1,199 @@
+/*
+Copyright © 2026 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kafka_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/IBM/sarama"
+	ctypes "github.com/RedHatInsights/insights-results-types"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/RedHatInsights/insights-operator-utils/kafka"
+	"github.com/RedHatInsights/insights-operator-utils/metrics"
+	"github.com/RedHatInsights/insights-operator-utils/tests/helpers"
+)
+
+const (
+	ruleResultsSchema = `{
+		"type": "object",
+		"required": ["OrgID", "ClusterName", "Report"],
+		"properties": {
+			"OrgID": {"type": "integer"},
+			"ClusterName": {"type": "string"},
+			"Report": {"type": "object"}
+		}
+	}`
+
+	validRuleResults = `{
+		"OrgID": 42,
+		"ClusterName": "5d5892d3-1f74-4ccf-91af-548dfc9767aa",
+		"LastChecked": "2020-01-23T16:15:59.478901889Z",
+		"RequestId": "a-request-id",
+		"Report": {}
+	}`
+
+	ruleResultsWithoutReport = `{
+		"OrgID": 42,
+		"ClusterName": "5d5892d3-1f74-4ccf-91af-548dfc9767aa",
+		"LastChecked": "2020-01-23T16:15:59.478901889Z",
+		"RequestId": "a-request-id"
+	}`
+
+	archiveNotification = `{
+		"org_id": "42",
+		"cluster_id": "5d5892d3-1f74-4ccf-91af-548dfc9767aa",
+		"timestamp": "2020-01-23T16:15:59.478901889Z",
+		"request_id": "a-request-id",
+		"url": "https://s3.example.com/archive.tar.gz"
+	}`
+)
+
+type ruleResults struct {
+	OrgID       ctypes.OrgID       `json:"OrgID"`
+	ClusterName ctypes.ClusterName `json:"ClusterName"`
+	LastChecked ctypes.Timestamp   `json:"LastChecked"`
+	RequestID   ctypes.RequestID   `json:"RequestId"`
+	Report      map[string]interface{}
+}
+
+func decodeFailures(reason string) float64 {
+	return testutil.ToFloat64(metrics.KafkaDecodeFailures.WithLabelValues(reason))
+}
+
+func TestJSONCodecDecodeRuleResults(t *testing.T) {
+	codec, err := kafka.NewJSONCodec(ruleResultsSchema, kafka.RuleResultsFields, kafka.StrictMode)
+	helpers.FailOnError(t, err)
+
+	var target ruleResults
+	envelope, err := codec.Decode([]byte(validRuleResults), &target)
+	helpers.FailOnError(t, err)
+
+	expected := kafka.Envelope{
+		OrgID:       42,
+		ClusterName: "5d5892d3-1f74-4ccf-91af-548dfc9767aa",
+		Timestamp:   "2020-01-23T16:15:59.478901889Z",
+		RequestID:   "a-request-id",
+		Payload:     []byte(validRuleResults),
+	}
+	assert.Equal(t, expected, *envelope)
+	assert.Equal(t, ctypes.OrgID(42), target.OrgID)
+	assert.NotNil(t, target.Report)
+}
+
+func TestJSONCodecDecodeArchiveNotification(t *testing.T) {
+	codec, err := kafka.NewJSONCodec("", kafka.ArchiveNotificationFields, kafka.LenientMode)
+	helpers.FailOnError(t, err)
+
+	envelope, err := codec.Decode([]byte(archiveNotification), nil)
+	helpers.FailOnError(t, err)
+
+	assert.Equal(t, ctypes.OrgID(42), envelope.OrgID)
+	assert.Equal(t, ctypes.ClusterName("5d5892d3-1f74-4ccf-91af-548dfc9767aa"), envelope.ClusterName)
+	assert.Equal(t, ctypes.Timestamp("2020-01-23T16:15:59.478901889Z"), envelope.Timestamp)
+	assert.Equal(t, ctypes.RequestID("a-request-id"), envelope.RequestID)
+}
+
+func TestJSONCodecInvalidSchema(t *testing.T) {
+	_, err := kafka.NewJSONCodec("not a schema", kafka.RuleResultsFields, kafka.StrictMode)
+	assert.Error(t, err)
+
+	_, err = kafka.NewJSONCodec(`{"type": 42}`, kafka.RuleResultsFields, kafka.StrictMode)
+	assert.Error(t, err)
+}
+
+func TestJSONCodecDecodeFailures(t *testing.T) {
+	testCases := []struct {
+		name           string
+		mode           kafka.DecodingMode
+		message        string
+		target         interface{}
+		expectedReason string
+	}{
+		{"invalid JSON", kafka.LenientMode, "{", nil, kafka.ReasonInvalidJSON},
+		{"not an object", kafka.StrictMode, "[]", nil, kafka.ReasonInvalidJSON},
+		{"schema violation", kafka.StrictMode, ruleResultsWithoutReport, nil, kafka.ReasonSchemaViolation},
+		{
+			"missing envelope field", kafka.StrictMode,
+			`{"OrgID": 42, "ClusterName": "cluster", "Report": {}}`, nil, kafka.ReasonInvalidEnvelope,
+		},
+		{
+			"malformed org ID", kafka.StrictMode,
+			`{"OrgID": "abc", "ClusterName": "c", "LastChecked": "t", "RequestId": "r", "Report": {}}`,
+			nil, kafka.ReasonSchemaViolation,
+		},
+		{
+			"unknown field", kafka.StrictMode,
+			`{"OrgID": 42, "ClusterName": "c", "LastChecked": "t", "RequestId": "r", "Report": {}, "Extra": 1}`,
+			&ruleResults{}, kafka.ReasonInvalidPayload,
+		},
+		{
+			"wrong payload type", kafka.LenientMode,
+			`{"OrgID": 42, "ClusterName": "c", "Report": "report"}`,
+			&ruleResults{}, kafka.ReasonInvalidPayload,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			codec, err := kafka.NewJSONCodec(ruleResultsSchema, kafka.RuleResultsFields, tc.mode)
+			helpers.FailOnError(t, err)
+
+			before := decodeFailures(tc.expectedReason)
+			envelope, err := codec.Decode([]byte(tc.message), tc.target)
+			assert.Nil(t, envelope)
+
+			var decodeErr *kafka.DecodeError
+			assert.True(t, errors.As(err, &decodeErr))
+			assert.Equal(t, tc.expectedReason, decodeErr.Reason)
+			assert.Equal(t, before+1, decodeFailures(tc.expectedReason))
+		})
+	}
+}
+
+func TestJSONCodecLenientMode(t *testing.T) {
+	codec, err := kafka.NewJSONCodec(ruleResultsSchema, kafka.RuleResultsFields, kafka.LenientMode)
+	helpers.FailOnError(t, err)
+
+	beforeSchema := decodeFailures(kafka.ReasonSchemaViolation)
+	beforeEnvelope := decodeFailures(kafka.ReasonInvalidEnvelope)
+
+	message := `{"OrgID": 42, "ClusterName": "c", "Extra": 1}`
+	var target ruleResults
+	envelope, err := codec.Decode([]byte(message), &target)
+	helpers.FailOnError(t, err)
+
+	assert.Equal(t, ctypes.OrgID(42), envelope.OrgID)
+	assert.Equal(t, ctypes.ClusterName("c"), envelope.ClusterName)
+	assert.Empty(t, envelope.RequestID)
+	assert.Equal(t, beforeSchema+1, decodeFailures(kafka.ReasonSchemaViolation))
+	assert.Equal(t, beforeEnvelope+1, decodeFailures(kafka.ReasonInvalidEnvelope))
+}
+
+func TestDecodeMessage(t *testing.T) {
+	codec, err := kafka.NewJSONCodec("", kafka.RuleResultsFields, kafka.StrictMode)
+	helpers.FailOnError(t, err)
+
+	envelope, err := kafka.DecodeMessage(codec, &sarama.ConsumerMessage{Value: []byte(validRuleResults)}, nil)
+	helpers.FailOnError(t, err)
+	assert.Equal(t, ctypes.RequestID("a-request-id"), envelope.RequestID)
+
+	_, err = kafka.DecodeMessage(codec, &sarama.ConsumerMessage{Value: []byte("{")}, nil)
+	assert.Error(t, err)
+}
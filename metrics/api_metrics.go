@@ -0,0 +1,153 @@
+/*
+Copyright © 2026 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+// Documentation in literate-programming-style is available at:
+// https://redhatinsights.github.io/insights-operator-utils/packages/metrics/api_metrics.html
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Values of org_id label used when the organization is not known or when the
+// limit of distinct organizations is reached
+const (
+	UnknownOrgID = "unknown"
+	OtherOrgID   = "other"
+)
+
+// DefaultMaxOrgIDs is the number of distinct organizations tracked in org_id
+// label when APIMetricsConfiguration.MaxOrgIDs is not set
+const DefaultMaxOrgIDs = 100
+
+// APIMetricsConfiguration represents configuration of API metrics
+type APIMetricsConfiguration struct {
+	Namespace string `mapstructure:"namespace" toml:"namespace"`
+	// ResponseTimeBuckets contains upper bounds (in seconds) of response
+	// time histogram buckets, DefaultResponseTimeBuckets are used when empty
+	ResponseTimeBuckets []float64 `mapstructure:"response_time_buckets" toml:"response_time_buckets"`
+	// MethodLabel adds HTTP method label to all API metrics
+	MethodLabel bool `mapstructure:"method_label" toml:"method_label"`
+	// OrgIDLabel adds organization ID label to api_endpoints_status_codes
+	OrgIDLabel bool `mapstructure:"org_id_label" toml:"org_id_label"`
+	// MaxOrgIDs limits the number of distinct values of org_id label,
+	// requests from other organizations are counted as "other"
+	MaxOrgIDs int `mapstructure:"max_org_ids" toml:"max_org_ids"`
+}
+
+var (
+	apiMetricsConfiguration APIMetricsConfiguration
+	orgIDLabels             = &orgIDLimiter{}
+)
+
+func (configuration APIMetricsConfiguration) endpointLabels() []string {
+	if configuration.MethodLabel {
+		return []string{endpointLabel, methodLabel}
+	}
+	return []string{endpointLabel}
+}
+
+// orgIDLimiter limits cardinality of org_id label
+type orgIDLimiter struct {
+	mutex  sync.Mutex
+	seen   map[string]struct{}
+	maxIDs int
+}
+
+func (limiter *orgIDLimiter) reset(maxIDs int) {
+	limiter.mutex.Lock()
+	defer limiter.mutex.Unlock()
+
+	if maxIDs <= 0 {
+		maxIDs = DefaultMaxOrgIDs
+	}
+	limiter.seen = make(map[string]struct{})
+	limiter.maxIDs = maxIDs
+}
+
+func (limiter *orgIDLimiter) labelValue(orgID string) string {
+	if orgID == "" {
+		return UnknownOrgID
+	}
+
+	limiter.mutex.Lock()
+	defer limiter.mutex.Unlock()
+
+	if limiter.seen == nil {
+		limiter.seen = make(map[string]struct{})
+		limiter.maxIDs = DefaultMaxOrgIDs
+	}
+	if _, found := limiter.seen[orgID]; found {
+		return orgID
+	}
+	if len(limiter.seen) >= limiter.maxIDs {
+		return OtherOrgID
+	}
+	limiter.seen[orgID] = struct{}{}
+	return orgID
+}
+
+// APIRequest describes a request handled by REST API endpoint
+type APIRequest struct {
+	Endpoint string
+	Method   string
+	// OrgID is used only when org_id label is enabled
+	OrgID        string
+	StatusCode   int
+	ResponseSize int
+	Duration     time.Duration
+}
+
+func (request *APIRequest) labels() prometheus.Labels {
+	labels := prometheus.Labels{endpointLabel: request.Endpoint}
+	if apiMetricsConfiguration.MethodLabel {
+		labels[methodLabel] = request.Method
+	}
+	return labels
+}
+
+// ObserveAPIRequestStart records a request that started to be handled
+func ObserveAPIRequestStart(request *APIRequest) {
+	apiMetricsMutex.RLock()
+	defer apiMetricsMutex.RUnlock()
+
+	labels := request.labels()
+	APIRequests.With(labels).Inc()
+	APIRequestsInFlight.With(labels).Inc()
+}
+
+// ObserveAPIRequestEnd records status code, size and duration of a handled
+// request
+func ObserveAPIRequestEnd(request *APIRequest) {
+	apiMetricsMutex.RLock()
+	defer apiMetricsMutex.RUnlock()
+
+	labels := request.labels()
+	APIRequestsInFlight.With(labels).Dec()
+	APIResponsesTime.With(labels).Observe(request.Duration.Seconds())
+	APIResponseSize.With(labels).Observe(float64(request.ResponseSize))
+
+	labels[statusCodeLabel] = fmt.Sprint(request.StatusCode)
+	if apiMetricsConfiguration.OrgIDLabel {
+		labels[orgIDLabel] = orgIDLabels.labelValue(request.OrgID)
+	}
+	APIResponseStatusCodes.With(labels).Inc()
+}
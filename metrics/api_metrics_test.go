@@ -0,0 +1,152 @@
+/*
+Copyright © 2026 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics_test
+
+// Documentation in literate-programming-style is available at:
+// https://redhatinsights.github.io/insights-operator-utils/packages/metrics/api_metrics_test.html
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/RedHatInsights/insights-operator-utils/metrics"
+	"github.com/RedHatInsights/insights-operator-utils/tests/helpers"
+)
+
+// configureAPIMetrics configures the API metrics for the rest of the test
+func configureAPIMetrics(t *testing.T, configuration metrics.APIMetricsConfiguration) {
+	metrics.ConfigureAPIMetrics(configuration)
+	t.Cleanup(func() { metrics.ConfigureAPIMetrics(metrics.APIMetricsConfiguration{}) })
+}
+
+func TestObserveAPIRequest(t *testing.T) {
+	configureAPIMetrics(t, metrics.APIMetricsConfiguration{})
+
+	request := &metrics.APIRequest{Endpoint: "report", Method: "GET"}
+	metrics.ObserveAPIRequestStart(request)
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.APIRequestsInFlight.WithLabelValues("report")))
+
+	request.StatusCode = 200
+	request.ResponseSize = 1500
+	request.Duration = 30 * time.Millisecond
+	metrics.ObserveAPIRequestEnd(request)
+
+	assert.Equal(t, 0.0, testutil.ToFloat64(metrics.APIRequestsInFlight.WithLabelValues("report")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.APIRequests.WithLabelValues("report")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.APIResponseStatusCodes.With(prometheus.Labels{
+		"endpoint": "report", "status_code": "200",
+	})))
+
+	expected := `
+		# HELP api_endpoints_response_size_bytes API endpoints response size
+		# TYPE api_endpoints_response_size_bytes histogram
+		api_endpoints_response_size_bytes_bucket{endpoint="report",le="100"} 0
+		api_endpoints_response_size_bytes_bucket{endpoint="report",le="1000"} 0
+		api_endpoints_response_size_bytes_bucket{endpoint="report",le="10000"} 1
+		api_endpoints_response_size_bytes_bucket{endpoint="report",le="100000"} 1
+		api_endpoints_response_size_bytes_bucket{endpoint="report",le="1e+06"} 1
+		api_endpoints_response_size_bytes_bucket{endpoint="report",le="1e+07"} 1
+		api_endpoints_response_size_bytes_bucket{endpoint="report",le="+Inf"} 1
+		api_endpoints_response_size_bytes_sum{endpoint="report"} 1500
+		api_endpoints_response_size_bytes_count{endpoint="report"} 1
+	`
+	err := testutil.CollectAndCompare(metrics.APIResponseSize, strings.NewReader(expected))
+	helpers.FailOnError(t, err)
+}
+
+func TestConfigureAPIMetricsLabels(t *testing.T) {
+	configureAPIMetrics(t, metrics.APIMetricsConfiguration{
+		Namespace:           "service",
+		ResponseTimeBuckets: []float64{0.1, 1},
+		MethodLabel:         true,
+		OrgIDLabel:          true,
+		MaxOrgIDs:           2,
+	})
+
+	for _, orgID := range []string{"1", "2", "3", "1", ""} {
+		request := &metrics.APIRequest{Endpoint: "report", Method: "POST", OrgID: orgID, StatusCode: 201}
+		metrics.ObserveAPIRequestStart(request)
+		metrics.ObserveAPIRequestEnd(request)
+	}
+
+	expected := `
+		# HELP service_api_endpoints_status_codes API endpoints status codes
+		# TYPE service_api_endpoints_status_codes counter
+		service_api_endpoints_status_codes{endpoint="report",method="POST",org_id="1",status_code="201"} 2
+		service_api_endpoints_status_codes{endpoint="report",method="POST",org_id="2",status_code="201"} 1
+		service_api_endpoints_status_codes{endpoint="report",method="POST",org_id="other",status_code="201"} 1
+		service_api_endpoints_status_codes{endpoint="report",method="POST",org_id="unknown",status_code="201"} 1
+	`
+	err := testutil.CollectAndCompare(metrics.APIResponseStatusCodes, strings.NewReader(expected))
+	helpers.FailOnError(t, err)
+
+	expected = `
+		# HELP service_api_endpoints_response_time API endpoints response time
+		# TYPE service_api_endpoints_response_time histogram
+		service_api_endpoints_response_time_bucket{endpoint="report",method="POST",le="0.1"} 5
+		service_api_endpoints_response_time_bucket{endpoint="report",method="POST",le="1"} 5
+		service_api_endpoints_response_time_bucket{endpoint="report",method="POST",le="+Inf"} 5
+		service_api_endpoints_response_time_sum{endpoint="report",method="POST"} 0
+		service_api_endpoints_response_time_count{endpoint="report",method="POST"} 5
+	`
+	err = testutil.CollectAndCompare(metrics.APIResponsesTime, strings.NewReader(expected))
+	helpers.FailOnError(t, err)
+}
+
+func TestConfiguredAPIMetricsAreGathered(t *testing.T) {
+	configureAPIMetrics(t, metrics.APIMetricsConfiguration{MethodLabel: true})
+	metrics.APIRequests.WithLabelValues("report", "GET").Inc()
+
+	count, err := testutil.GatherAndCount(prometheus.DefaultGatherer, "api_endpoints_requests")
+	helpers.FailOnError(t, err)
+	assert.Equal(t, 1, count)
+}
+
+func TestAPIMetricsCanBeUnregistered(t *testing.T) {
+	configureAPIMetrics(t, metrics.APIMetricsConfiguration{})
+	assert.True(t, prometheus.Unregister(metrics.APIRequests))
+}
+
+func TestConfigureAPIMetricsConcurrently(t *testing.T) {
+	configureAPIMetrics(t, metrics.APIMetricsConfiguration{})
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			request := &metrics.APIRequest{Endpoint: "report", Method: "GET", StatusCode: 200}
+			metrics.ObserveAPIRequestStart(request)
+			metrics.ObserveAPIRequestEnd(request)
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 10; i++ {
+			metrics.ConfigureAPIMetrics(metrics.APIMetricsConfiguration{MethodLabel: i%2 == 0})
+			_, err := prometheus.DefaultGatherer.Gather()
+			assert.NoError(t, err)
+		}
+	}()
+	wg.Wait()
+}
@@ -19,64 +19,280 @@ limitations under the License.
 //
 // api_endpoints_requests - number of requests made for each REST API endpoint
 //
+// api_endpoints_requests_in_flight - number of requests being handled by each
+// REST API endpoint
+//
 // api_endpoints_response_time - response times for all REST API endpoints
 //
+// api_endpoints_response_size_bytes - response sizes for all REST API
+// endpoints
+//
 // api_endpoints_status_codes - number of responses for each status code
+//
+// kafka_message_decode_failures - number of Kafka messages that could not be
+// decoded, broken down by reason
+//
+// tls_certificate_expiry_timestamp_seconds - expiration time of loaded TLS
+// certificates
+//
+// http_client_requests - number of outgoing HTTP requests per target, method
+// and status code
+//
+// http_client_request_duration_seconds - duration of outgoing HTTP requests
+// per target
+//
+// http_client_retries - number of retried outgoing HTTP requests per target
+//
+// http_client_circuit_breaker_state - state of circuit breaker per target
+// (0 - closed, 1 - half-open, 2 - open)
+//
+// api_rate_limit_decisions - number of requests checked by rate limiter per
+// class of the limited key (e.g. org or ip) and result (allowed or limited)
+//
+// api_rate_limiter_errors - number of failed rate limiter checks per backend
 package metrics
 
 // Documentation in literate-programming-style is available at:
 // https://redhatinsights.github.io/insights-operator-utils/packages/metrics/metrics.html
 
 import (
+	"errors"
+	"sync"
+
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	endpointLabel   = "endpoint"
+	methodLabel     = "method"
+	statusCodeLabel = "status_code"
+	orgIDLabel      = "org_id"
+	reasonLabel     = "reason"
+	targetLabel     = "target"
+	keyClassLabel   = "key_class"
+	resultLabel     = "result"
+	backendLabel    = "backend"
 )
 
-const endpointLabel = "endpoint"
+// DefaultResponseTimeBuckets are upper bounds (in seconds) of response time
+// histogram buckets used when APIMetricsConfiguration.ResponseTimeBuckets is
+// empty, they cover latencies from 5ms to 10s
+var DefaultResponseTimeBuckets = prometheus.DefBuckets
 
 var (
 	// APIRequests is a counter vector for requests to endpoints
-	APIRequests *prometheus.CounterVec = promauto.NewCounterVec(prometheus.CounterOpts{
-		Name: "api_endpoints_requests",
-		Help: "The total number of requests per endpoint",
-	}, []string{endpointLabel})
+	APIRequests *prometheus.CounterVec
+
+	// APIRequestsInFlight contains number of requests being handled per
+	// endpoint
+	APIRequestsInFlight *prometheus.GaugeVec
 
 	// APIResponsesTime collects the information about api response time per endpoint
-	APIResponsesTime *prometheus.HistogramVec = promauto.NewHistogramVec(prometheus.HistogramOpts{
-		Name:    "api_endpoints_response_time",
-		Help:    "API endpoints response time",
-		Buckets: prometheus.LinearBuckets(0, 20, 20),
-	}, []string{endpointLabel})
+	APIResponsesTime *prometheus.HistogramVec
+
+	// APIResponseSize collects the information about size of api responses
+	// per endpoint
+	APIResponseSize *prometheus.HistogramVec
 
 	// APIResponseStatusCodes collects the information about api response status codes
-	APIResponseStatusCodes *prometheus.CounterVec = promauto.NewCounterVec(prometheus.CounterOpts{
-		Name: "api_endpoints_status_codes",
-		Help: "API endpoints status codes",
-	}, []string{"status_code", endpointLabel})
+	APIResponseStatusCodes *prometheus.CounterVec
+
+	// KafkaDecodeFailures collects the information about Kafka messages
+	// that could not be decoded or validated
+	KafkaDecodeFailures *prometheus.CounterVec = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "kafka_message_decode_failures",
+		Help: "The total number of Kafka messages that failed to be decoded per reason",
+	}, []string{reasonLabel})
+
+	// TLSCertificateExpiry contains expiration time (as Unix timestamp) of
+	// TLS certificates loaded from files
+	TLSCertificateExpiry *prometheus.GaugeVec = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "tls_certificate_expiry_timestamp_seconds",
+		Help: "Expiration time of loaded TLS certificates",
+	}, []string{"type", "path"})
+
+	// HTTPClientRequests counts outgoing HTTP requests, status code is set
+	// to "error" when no response was received
+	HTTPClientRequests *prometheus.CounterVec = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_client_requests",
+		Help: "The total number of outgoing HTTP requests per target",
+	}, []string{targetLabel, methodLabel, statusCodeLabel})
+
+	// HTTPClientRequestDuration collects durations of outgoing HTTP requests
+	HTTPClientRequestDuration *prometheus.HistogramVec = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_client_request_duration_seconds",
+		Help:    "Duration of outgoing HTTP requests per target",
+		Buckets: prometheus.DefBuckets,
+	}, []string{targetLabel, methodLabel})
+
+	// HTTPClientRetries counts retries of outgoing HTTP requests
+	HTTPClientRetries *prometheus.CounterVec = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_client_retries",
+		Help: "The total number of retried outgoing HTTP requests per target",
+	}, []string{targetLabel, methodLabel})
+
+	// HTTPClientCircuitBreakerState contains state of circuit breakers
+	// protecting outgoing HTTP requests: 0 for closed, 1 for half-open and
+	// 2 for open breaker
+	HTTPClientCircuitBreakerState *prometheus.GaugeVec = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "http_client_circuit_breaker_state",
+		Help: "State of circuit breaker per target (0 - closed, 1 - half-open, 2 - open)",
+	}, []string{targetLabel})
+
+	// RateLimitDecisions counts requests checked by rate limiter, result is
+	// either "allowed" or "limited"
+	RateLimitDecisions *prometheus.CounterVec = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "api_rate_limit_decisions",
+		Help: "The total number of requests checked by rate limiter per key class and result",
+	}, []string{keyClassLabel, resultLabel})
+
+	// RateLimiterErrors counts rate limiter checks that failed, e.g. because
+	// the backend is not reachable
+	RateLimiterErrors *prometheus.CounterVec = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "api_rate_limiter_errors",
+		Help: "The total number of failed rate limiter checks per backend",
+	}, []string{backendLabel})
 )
 
+var (
+	// apiMetricsMutex guards the API metrics and their configuration
+	apiMetricsMutex sync.RWMutex
+	// apiMetricsUnchecked is set when the API metrics are collected by
+	// apiMetricsCollector instead of being registered one by one
+	apiMetricsUnchecked bool
+)
+
+func init() {
+	createAPIMetrics()
+	registerAPIMetrics()
+	prometheus.MustRegister(apiMetricsCollector{})
+}
+
+// apiMetricsCollector collects the API metrics that can't be registered one
+// by one. It is an unchecked collector (it doesn't describe the metrics),
+// because Prometheus registry refuses to register metrics with labels that
+// differ from the labels of unregistered metrics with the same name.
+type apiMetricsCollector struct{}
+
+func (apiMetricsCollector) Describe(chan<- *prometheus.Desc) {}
+
+func (apiMetricsCollector) Collect(metrics chan<- prometheus.Metric) {
+	apiMetricsMutex.RLock()
+	defer apiMetricsMutex.RUnlock()
+
+	if !apiMetricsUnchecked {
+		return
+	}
+	for _, collector := range apiMetrics() {
+		collector.Collect(metrics)
+	}
+}
+
+func apiMetrics() []prometheus.Collector {
+	return []prometheus.Collector{
+		APIRequests, APIRequestsInFlight, APIResponsesTime, APIResponseSize, APIResponseStatusCodes,
+	}
+}
+
+// registerAPIMetrics registers the current API metrics one by one, so they
+// can be unregistered by prometheus.Unregister. When any of them is refused,
+// all of them are collected by apiMetricsCollector instead.
+func registerAPIMetrics() {
+	var registered []prometheus.Collector
+	for _, collector := range apiMetrics() {
+		err := prometheus.Register(collector)
+		var alreadyRegistered prometheus.AlreadyRegisteredError
+		if err != nil && !errors.As(err, &alreadyRegistered) {
+			log.Debug().Err(err).Msg("API metrics are collected by unchecked collector")
+			for _, collector := range registered {
+				prometheus.Unregister(collector)
+			}
+			apiMetricsUnchecked = true
+			return
+		}
+		registered = append(registered, collector)
+	}
+	apiMetricsUnchecked = false
+}
+
+func unregisterAPIMetrics() {
+	if apiMetricsUnchecked {
+		apiMetricsUnchecked = false
+		return
+	}
+	for _, collector := range apiMetrics() {
+		prometheus.Unregister(collector)
+	}
+}
+
 // AddAPIMetricsWithNamespace overwrite the defined metrics with namespaced version of them
 func AddAPIMetricsWithNamespace(namespace string) {
-	prometheus.Unregister(APIRequests)
-	prometheus.Unregister(APIResponsesTime)
-	prometheus.Unregister(APIResponseStatusCodes)
+	ConfigureAPIMetrics(APIMetricsConfiguration{Namespace: namespace})
+}
+
+// ConfigureAPIMetrics overwrite the API metrics with versions using the given
+// configuration. It is expected to be called once during service start,
+// before the exported metric variables are used by the service. The metrics
+// are registered with the default registry one by one like the original
+// ones. When the configuration changes labels of already registered
+// metrics, Prometheus registry refuses them and the metrics are collected by
+// an internal collector instead, so prometheus.Unregister has no effect on
+// them.
+func ConfigureAPIMetrics(configuration APIMetricsConfiguration) {
+	apiMetricsMutex.Lock()
+	defer apiMetricsMutex.Unlock()
 
-	APIRequests = promauto.NewCounterVec(prometheus.CounterOpts{
-		Namespace: namespace,
+	unregisterAPIMetrics()
+	apiMetricsConfiguration = configuration
+	orgIDLabels.reset(configuration.MaxOrgIDs)
+	createAPIMetrics()
+	registerAPIMetrics()
+}
+
+func createAPIMetrics() {
+	configuration := apiMetricsConfiguration
+	labels := configuration.endpointLabels()
+
+	buckets := configuration.ResponseTimeBuckets
+	if len(buckets) == 0 {
+		buckets = DefaultResponseTimeBuckets
+	}
+
+	APIRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: configuration.Namespace,
 		Name:      "api_endpoints_requests",
 		Help:      "The total number of requests per endpoint",
-	}, []string{endpointLabel})
+	}, labels)
 
-	APIResponsesTime = promauto.NewHistogramVec(prometheus.HistogramOpts{
-		Namespace: namespace,
+	APIRequestsInFlight = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: configuration.Namespace,
+		Name:      "api_endpoints_requests_in_flight",
+		Help:      "The number of requests being handled per endpoint",
+	}, labels)
+
+	APIResponsesTime = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: configuration.Namespace,
 		Name:      "api_endpoints_response_time",
 		Help:      "API endpoints response time",
-		Buckets:   prometheus.LinearBuckets(0, 20, 20),
-	}, []string{endpointLabel})
+		Buckets:   buckets,
+	}, labels)
+
+	APIResponseSize = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: configuration.Namespace,
+		Name:      "api_endpoints_response_size_bytes",
+		Help:      "API endpoints response size",
+		Buckets:   prometheus.ExponentialBuckets(100, 10, 6),
+	}, labels)
 
-	APIResponseStatusCodes = promauto.NewCounterVec(prometheus.CounterOpts{
-		Namespace: namespace,
+	statusCodeLabels := append([]string{statusCodeLabel}, labels...)
+	if configuration.OrgIDLabel {
+		statusCodeLabels = append(statusCodeLabels, orgIDLabel)
+	}
+	APIResponseStatusCodes = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: configuration.Namespace,
 		Name:      "api_endpoints_status_codes",
 		Help:      "API endpoints status codes",
-	}, []string{"status_code", endpointLabel})
+	}, statusCodeLabels)
 }
@@ -111,26 +111,17 @@ func TestAPIResponsesTimeMetric(t *testing.T) {
 	expected := `
 		# HELP api_endpoints_response_time API endpoints response time
 		# TYPE api_endpoints_response_time histogram
-		api_endpoints_response_time_bucket{endpoint="test",le="0"} 0
-		api_endpoints_response_time_bucket{endpoint="test",le="20"} 1
-		api_endpoints_response_time_bucket{endpoint="test",le="40"} 1
-		api_endpoints_response_time_bucket{endpoint="test",le="60"} 1
-		api_endpoints_response_time_bucket{endpoint="test",le="80"} 1
-		api_endpoints_response_time_bucket{endpoint="test",le="100"} 1
-		api_endpoints_response_time_bucket{endpoint="test",le="120"} 1
-		api_endpoints_response_time_bucket{endpoint="test",le="140"} 1
-		api_endpoints_response_time_bucket{endpoint="test",le="160"} 1
-		api_endpoints_response_time_bucket{endpoint="test",le="180"} 1
-		api_endpoints_response_time_bucket{endpoint="test",le="200"} 1
-		api_endpoints_response_time_bucket{endpoint="test",le="220"} 1
-		api_endpoints_response_time_bucket{endpoint="test",le="240"} 1
-		api_endpoints_response_time_bucket{endpoint="test",le="260"} 1
-		api_endpoints_response_time_bucket{endpoint="test",le="280"} 1
-		api_endpoints_response_time_bucket{endpoint="test",le="300"} 1
-		api_endpoints_response_time_bucket{endpoint="test",le="320"} 1
-		api_endpoints_response_time_bucket{endpoint="test",le="340"} 1
-		api_endpoints_response_time_bucket{endpoint="test",le="360"} 1
-		api_endpoints_response_time_bucket{endpoint="test",le="380"} 1
+		api_endpoints_response_time_bucket{endpoint="test",le="0.005"} 0
+		api_endpoints_response_time_bucket{endpoint="test",le="0.01"} 0
+		api_endpoints_response_time_bucket{endpoint="test",le="0.025"} 0
+		api_endpoints_response_time_bucket{endpoint="test",le="0.05"} 0
+		api_endpoints_response_time_bucket{endpoint="test",le="0.1"} 0
+		api_endpoints_response_time_bucket{endpoint="test",le="0.25"} 0
+		api_endpoints_response_time_bucket{endpoint="test",le="0.5"} 0
+		api_endpoints_response_time_bucket{endpoint="test",le="1"} 0
+		api_endpoints_response_time_bucket{endpoint="test",le="2.5"} 0
+		api_endpoints_response_time_bucket{endpoint="test",le="5"} 0
+		api_endpoints_response_time_bucket{endpoint="test",le="10"} 1
 		api_endpoints_response_time_bucket{endpoint="test",le="+Inf"} 1
 		api_endpoints_response_time_sum{endpoint="test"} 5.6
 		api_endpoints_response_time_count{endpoint="test"} 1
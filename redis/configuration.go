@@ -0,0 +1,43 @@
+// Copyright 2026 Red Hat, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package redis
+
+// Documentation in literate-programming-style is available at:
+// https://redhatinsights.github.io/insights-operator-utils/packages/redis/configuration.html
+
+import (
+	redisV9 "github.com/redis/go-redis/v9"
+)
+
+// Configuration represents configuration of the Redis client
+type Configuration struct {
+	Endpoint       string `mapstructure:"endpoint" toml:"endpoint"`
+	Database       int    `mapstructure:"database" toml:"database"`
+	Username       string `mapstructure:"username" toml:"username"`
+	Password       string `mapstructure:"password" toml:"password"`
+	TimeoutSeconds int    `mapstructure:"timeout_seconds" toml:"timeout_seconds"`
+}
+
+// CreateRedisClientFromConfiguration creates a Redis V9 client using the
+// parameters stored in the configuration structure
+func CreateRedisClientFromConfiguration(configuration *Configuration) (*redisV9.Client, error) {
+	return CreateRedisClient(
+		configuration.Endpoint,
+		configuration.Database,
+		configuration.Username,
+		configuration.Password,
+		configuration.TimeoutSeconds,
+	)
+}
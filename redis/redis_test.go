@@ -122,3 +122,26 @@ func TestRedisHealthCheckBadResponse(t *testing.T) {
 
 	redisExpectationsMet(t, server)
 }
+
+func TestCreateRedisClientFromConfiguration(t *testing.T) {
+	configuration := redis.Configuration{
+		Endpoint:       defaultRedisAddress,
+		Database:       defaultRedisDatabase,
+		Username:       defaultRedisUsername,
+		Password:       defaultRedisPassword,
+		TimeoutSeconds: defaultRedisTimeoutSeconds,
+	}
+
+	client, err := redis.CreateRedisClientFromConfiguration(&configuration)
+	assert.NoError(t, err)
+
+	options := client.Options()
+	assert.Equal(t, defaultRedisAddress, options.Addr)
+	assert.Equal(t, defaultRedisUsername, options.Username)
+	assert.Equal(t, time.Duration(defaultRedisTimeoutSeconds)*time.Second, options.ReadTimeout)
+
+	configuration.Endpoint = ""
+	client, err = redis.CreateRedisClientFromConfiguration(&configuration)
+	assert.Nil(t, client)
+	assert.Error(t, err)
+}
@@ -0,0 +1,63 @@
+/*
+Copyright © 2026 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package responses
+
+// Documentation in literate-programming-style is available at:
+// https://redhatinsights.github.io/insights-operator-utils/packages/responses/batch.html
+
+// Values of BatchItemResult.Status
+const (
+	BatchItemOK    = "ok"
+	BatchItemError = "error"
+)
+
+// BatchItemResult reports result of processing one item of batch request
+type BatchItemResult struct {
+	Item   string `json:"item"`
+	Status string `json:"status"`
+	// Error describes why the item was not processed
+	Error string `json:"error,omitempty"`
+}
+
+// BatchSummary contains numbers of items of batch request by result
+type BatchSummary struct {
+	Total     int `json:"total"`
+	Succeeded int `json:"succeeded"`
+	Failed    int `json:"failed"`
+}
+
+// BuildBatchResponse builds response with status "ok", per-item results
+// under dataName key and "summary" with the number of succeeded and failed
+// items. The status is "ok" even when some items failed, because the
+// request itself was processed.
+func BuildBatchResponse(dataName string, results []BatchItemResult) map[string]interface{} {
+	summary := BatchSummary{Total: len(results)}
+	for _, result := range results {
+		if result.Status == BatchItemOK {
+			summary.Succeeded++
+		} else {
+			summary.Failed++
+		}
+	}
+
+	if results == nil {
+		results = []BatchItemResult{}
+	}
+	response := BuildOkResponseWithData(dataName, results)
+	response["summary"] = summary
+	return response
+}
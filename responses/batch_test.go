@@ -0,0 +1,54 @@
+/*
+Copyright © 2026 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package responses_test
+
+// Documentation in literate-programming-style is available at:
+// https://redhatinsights.github.io/insights-operator-utils/packages/responses/batch_test.html
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/RedHatInsights/insights-operator-utils/responses"
+	"github.com/RedHatInsights/insights-operator-utils/tests/helpers"
+)
+
+func TestBuildBatchResponse(t *testing.T) {
+	response := responses.BuildBatchResponse("clusters", []responses.BatchItemResult{
+		{Item: "c1", Status: responses.BatchItemOK},
+		{Item: "c2", Status: responses.BatchItemError, Error: "invalid UUID length: 2"},
+	})
+
+	encoded, err := json.Marshal(response)
+	helpers.FailOnError(t, err)
+	assert.JSONEq(t, `{
+		"status": "ok",
+		"clusters": [
+			{"item": "c1", "status": "ok"},
+			{"item": "c2", "status": "error", "error": "invalid UUID length: 2"}
+		],
+		"summary": {"total": 2, "succeeded": 1, "failed": 1}
+	}`, string(encoded))
+}
+
+func TestBuildBatchResponseEmpty(t *testing.T) {
+	encoded, err := json.Marshal(responses.BuildBatchResponse("clusters", nil))
+	helpers.FailOnError(t, err)
+	assert.JSONEq(t, `{"status":"ok","clusters":[],"summary":{"total":0,"succeeded":0,"failed":0}}`, string(encoded))
+}
@@ -0,0 +1,158 @@
+/*
+Copyright © 2026 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package responses
+
+// Documentation in literate-programming-style is available at:
+// https://redhatinsights.github.io/insights-operator-utils/packages/responses/conditional.html
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/RedHatInsights/insights-operator-utils/formatters"
+)
+
+// Names of headers used by conditional requests
+const (
+	ETagHeader            = "ETag"
+	LastModifiedHeader    = "Last-Modified"
+	IfNoneMatchHeader     = "If-None-Match"
+	IfModifiedSinceHeader = "If-Modified-Since"
+)
+
+// Validators identify a version of the response, they are sent in ETag and
+// Last-Modified headers and compared with the conditional headers of the
+// request. Zero values are not used.
+type Validators struct {
+	ETag         string
+	LastModified time.Time
+}
+
+// StrongETag computes strong entity tag from the response payload
+func StrongETag(payload []byte) string {
+	checksum := sha256.Sum256(payload)
+	return `"` + hex.EncodeToString(checksum[:16]) + `"`
+}
+
+// VersionValidators returns validators for content identified by the version
+// string, e.g. a revision number or a timestamp of the last change
+func VersionValidators(version string) Validators {
+	return Validators{ETag: StrongETag([]byte(version))}
+}
+
+// NullTimeValidators returns validators for content last changed at the
+// given time, e.g. a report with its LastCheckedAt. The ETag is derived from
+// the time formatted by formatters.FormatNullTime and the time is also used
+// as Last-Modified. No validators are returned for invalid time.
+func NullTimeValidators(lastChanged sql.NullTime) Validators {
+	if !lastChanged.Valid {
+		return Validators{}
+	}
+	validators := VersionValidators(formatters.FormatNullTime(lastChanged))
+	validators.LastModified = lastChanged.Time
+	return validators
+}
+
+// SetHeaders sets ETag and Last-Modified headers of the response
+func (validators Validators) SetHeaders(w http.ResponseWriter) {
+	if validators.ETag != "" {
+		w.Header().Set(ETagHeader, validators.ETag)
+	}
+	if !validators.LastModified.IsZero() {
+		w.Header().Set(LastModifiedHeader, validators.LastModified.UTC().Format(http.TimeFormat))
+	}
+}
+
+// NotModified evaluates If-None-Match and If-Modified-Since headers of GET
+// and HEAD requests as defined by RFC 9110. If-Modified-Since is ignored when
+// If-None-Match is present.
+func (validators Validators) NotModified(r *http.Request) bool {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return false
+	}
+
+	if ifNoneMatch := r.Header.Get(IfNoneMatchHeader); ifNoneMatch != "" {
+		return validators.ETag != "" && ETagMatches(ifNoneMatch, validators.ETag)
+	}
+
+	ifModifiedSince := r.Header.Get(IfModifiedSinceHeader)
+	if ifModifiedSince == "" || validators.LastModified.IsZero() {
+		return false
+	}
+	since, err := http.ParseTime(ifModifiedSince)
+	if err != nil {
+		return false
+	}
+	// HTTP dates have one second resolution
+	return !validators.LastModified.Truncate(time.Second).After(since)
+}
+
+// ETagMatches checks If-None-Match header value against the ETag, weak
+// comparison is used as defined by RFC 9110
+func ETagMatches(ifNoneMatch, etag string) bool {
+	if ifNoneMatch == "" {
+		return false
+	}
+	if strings.TrimSpace(ifNoneMatch) == "*" {
+		return true
+	}
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
+
+// SendNotModified returns response with status Not Modified 304 and without
+// body
+func SendNotModified(w http.ResponseWriter, validators Validators) {
+	validators.SetHeaders(w)
+	w.WriteHeader(http.StatusNotModified)
+}
+
+// SendConditional sends the data like Send, but Not Modified 304 without
+// body is sent instead when the conditional headers of the request match the
+// validators. The validators are set in the response in both cases.
+func SendConditional(
+	statusCode int, w http.ResponseWriter, r *http.Request, validators Validators, data interface{},
+) error {
+	if validators.NotModified(r) {
+		SendNotModified(w, validators)
+		return nil
+	}
+	validators.SetHeaders(w)
+	return Send(statusCode, w, data)
+}
+
+// SendConditionalOK returns JSON response with status OK 200 and strong ETag
+// computed from the encoded data, or Not Modified 304 when the client
+// already has the same data
+func SendConditionalOK(w http.ResponseWriter, r *http.Request, data interface{}) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	payload = append(payload, '\n')
+	return SendConditional(http.StatusOK, w, r, Validators{ETag: StrongETag(payload)}, payload)
+}
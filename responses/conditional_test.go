@@ -0,0 +1,136 @@
+/*
+Copyright © 2026 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package responses_test
+
+// Documentation in literate-programming-style is available at:
+// https://redhatinsights.github.io/insights-operator-utils/packages/responses/conditional_test.html
+
+import (
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/RedHatInsights/insights-operator-utils/responses"
+	"github.com/RedHatInsights/insights-operator-utils/tests/helpers"
+)
+
+var lastCheckedAt = sql.NullTime{Time: time.Date(2026, 3, 1, 12, 30, 15, 500, time.UTC), Valid: true}
+
+func conditionalRequest(method, header, value string) *http.Request {
+	request := httptest.NewRequest(method, "/report", http.NoBody)
+	if header != "" {
+		request.Header.Set(header, value)
+	}
+	return request
+}
+
+func TestStrongETag(t *testing.T) {
+	etag := responses.StrongETag([]byte(`{"status":"ok"}`))
+	assert.Len(t, etag, 34)
+	assert.Equal(t, `"`, etag[:1])
+	assert.Equal(t, etag, responses.StrongETag([]byte(`{"status":"ok"}`)))
+	assert.NotEqual(t, etag, responses.StrongETag([]byte(`{"status":"error"}`)))
+}
+
+func TestNullTimeValidators(t *testing.T) {
+	validators := responses.NullTimeValidators(lastCheckedAt)
+	assert.Equal(t, responses.VersionValidators("2026-03-01T12:30:15Z").ETag, validators.ETag)
+	assert.Equal(t, lastCheckedAt.Time, validators.LastModified)
+
+	assert.Equal(t, responses.Validators{}, responses.NullTimeValidators(sql.NullTime{}))
+}
+
+func TestValidatorsNotModified(t *testing.T) {
+	validators := responses.NullTimeValidators(lastCheckedAt)
+	lastModified := lastCheckedAt.Time.Format(http.TimeFormat)
+	earlier := lastCheckedAt.Time.Add(-time.Minute).Format(http.TimeFormat)
+
+	tests := []struct {
+		name     string
+		request  *http.Request
+		expected bool
+	}{
+		{"no conditional headers", conditionalRequest(http.MethodGet, "", ""), false},
+		{"matching ETag", conditionalRequest(http.MethodGet, responses.IfNoneMatchHeader, validators.ETag), true},
+		{"weak matching ETag", conditionalRequest(http.MethodHead, responses.IfNoneMatchHeader, `"x", W/`+validators.ETag), true},
+		{"any ETag", conditionalRequest(http.MethodGet, responses.IfNoneMatchHeader, "*"), true},
+		{"different ETag", conditionalRequest(http.MethodGet, responses.IfNoneMatchHeader, `"x"`), false},
+		{"not modified since", conditionalRequest(http.MethodGet, responses.IfModifiedSinceHeader, lastModified), true},
+		{"modified since", conditionalRequest(http.MethodGet, responses.IfModifiedSinceHeader, earlier), false},
+		{"malformed date", conditionalRequest(http.MethodGet, responses.IfModifiedSinceHeader, "yesterday"), false},
+		{"unsafe method", conditionalRequest(http.MethodPost, responses.IfNoneMatchHeader, validators.ETag), false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, validators.NotModified(test.request))
+		})
+	}
+
+	// If-Modified-Since is ignored when If-None-Match is present
+	request := conditionalRequest(http.MethodGet, responses.IfNoneMatchHeader, `"x"`)
+	request.Header.Set(responses.IfModifiedSinceHeader, lastModified)
+	assert.False(t, validators.NotModified(request))
+}
+
+func TestSendConditional(t *testing.T) {
+	validators := responses.NullTimeValidators(lastCheckedAt)
+	data := map[string]interface{}{"status": "ok"}
+
+	recorder := httptest.NewRecorder()
+	err := responses.SendConditional(http.StatusOK, recorder, conditionalRequest(http.MethodGet, "", ""), validators, data)
+	helpers.FailOnError(t, err)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, validators.ETag, recorder.Header().Get(responses.ETagHeader))
+	assert.Equal(t, "Sun, 01 Mar 2026 12:30:15 GMT", recorder.Header().Get(responses.LastModifiedHeader))
+	assert.JSONEq(t, `{"status":"ok"}`, recorder.Body.String())
+
+	recorder = httptest.NewRecorder()
+	request := conditionalRequest(http.MethodGet, responses.IfNoneMatchHeader, validators.ETag)
+	err = responses.SendConditional(http.StatusOK, recorder, request, validators, data)
+	helpers.FailOnError(t, err)
+	assert.Equal(t, http.StatusNotModified, recorder.Code)
+	assert.Equal(t, validators.ETag, recorder.Header().Get(responses.ETagHeader))
+	assert.Empty(t, recorder.Body.String())
+}
+
+func TestSendConditionalOK(t *testing.T) {
+	data := map[string]interface{}{"status": "ok", "report": []string{"rule1", "rule2"}}
+
+	recorder := httptest.NewRecorder()
+	err := responses.SendConditionalOK(recorder, conditionalRequest(http.MethodGet, "", ""), data)
+	helpers.FailOnError(t, err)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, appJSON, recorder.Header().Get(contentType))
+	assert.JSONEq(t, `{"status":"ok","report":["rule1","rule2"]}`, recorder.Body.String())
+
+	etag := recorder.Header().Get(responses.ETagHeader)
+	assert.Equal(t, responses.StrongETag(recorder.Body.Bytes()), etag)
+
+	recorder = httptest.NewRecorder()
+	err = responses.SendConditionalOK(recorder, conditionalRequest(http.MethodGet, responses.IfNoneMatchHeader, etag), data)
+	helpers.FailOnError(t, err)
+	assert.Equal(t, http.StatusNotModified, recorder.Code)
+	assert.Empty(t, recorder.Body.String())
+
+	err = responses.SendConditionalOK(httptest.NewRecorder(), conditionalRequest(http.MethodGet, "", ""), make(chan int))
+	assert.Error(t, err)
+}
@@ -0,0 +1,150 @@
+/*
+Copyright © 2026 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package responses
+
+// Documentation in literate-programming-style is available at:
+// https://redhatinsights.github.io/insights-operator-utils/packages/responses/envelope.html
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+const (
+	// DefaultDataName is the key of data in Envelope when DataName is not set
+	DefaultDataName = "data"
+
+	metaKey  = "meta"
+	linksKey = "links"
+)
+
+// Meta describes a page of list response
+type Meta struct {
+	Count  int  `json:"count"`
+	Total  *int `json:"total,omitempty"`
+	Limit  int  `json:"limit"`
+	Offset int  `json:"offset"`
+}
+
+// Links contains links to other pages of list response
+type Links struct {
+	First string `json:"first"`
+	Next  string `json:"next,omitempty"`
+	Prev  string `json:"prev,omitempty"`
+}
+
+// Envelope is a typed variant of the responses built by
+// BuildOkResponseWithData. It is serialized as
+//
+//	{"status": "ok", "<DataName>": data, "meta": {...}, "links": {...}}
+//
+// where meta and links are left out when nil, so the format stays the same
+// as with map[string]interface{} responses.
+type Envelope[T any] struct {
+	Status string
+	Data   T
+	Meta   *Meta
+	Links  *Links
+	// DataName is the key of data, DefaultDataName is used when empty
+	DataName string
+}
+
+// NewEnvelope creates envelope with status "ok" and data under the dataName
+// key
+func NewEnvelope[T any](dataName string, data T) Envelope[T] {
+	return Envelope[T]{Status: "ok", Data: data, DataName: dataName}
+}
+
+func (envelope Envelope[T]) dataName() string {
+	if envelope.DataName == "" {
+		return DefaultDataName
+	}
+	return envelope.DataName
+}
+
+// MarshalJSON serializes the envelope with data under DataName key
+func (envelope Envelope[T]) MarshalJSON() ([]byte, error) {
+	object := map[string]interface{}{
+		statusKey:           envelope.Status,
+		envelope.dataName(): envelope.Data,
+	}
+	if envelope.Meta != nil {
+		object[metaKey] = envelope.Meta
+	}
+	if envelope.Links != nil {
+		object[linksKey] = envelope.Links
+	}
+	return json.Marshal(object)
+}
+
+// UnmarshalJSON reads the envelope, data are read from DataName key, so it
+// has to be set before unmarshalling when it's not DefaultDataName
+func (envelope *Envelope[T]) UnmarshalJSON(data []byte) error {
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(data, &object); err != nil {
+		return err
+	}
+
+	if raw, found := object[statusKey]; found {
+		if err := json.Unmarshal(raw, &envelope.Status); err != nil {
+			return fmt.Errorf("%s: %w", statusKey, err)
+		}
+	}
+	if raw, found := object[envelope.dataName()]; found {
+		if err := json.Unmarshal(raw, &envelope.Data); err != nil {
+			return fmt.Errorf("%s: %w", envelope.dataName(), err)
+		}
+	}
+	if raw, found := object[metaKey]; found {
+		if err := json.Unmarshal(raw, &envelope.Meta); err != nil {
+			return fmt.Errorf("%s: %w", metaKey, err)
+		}
+	}
+	if raw, found := object[linksKey]; found {
+		if err := json.Unmarshal(raw, &envelope.Links); err != nil {
+			return fmt.Errorf("%s: %w", linksKey, err)
+		}
+	}
+	return nil
+}
+
+// JSONSchema returns JSON schema of the envelope, it is used by SchemaOf
+func (envelope Envelope[T]) JSONSchema() Schema {
+	properties := map[string]Schema{
+		statusKey:           {"type": "string"},
+		envelope.dataName(): SchemaFor[T](),
+		metaKey:             SchemaFor[Meta](),
+		linksKey:            SchemaFor[Links](),
+	}
+	return Schema{
+		"type":       "object",
+		"properties": properties,
+		"required":   []string{statusKey, envelope.dataName()},
+	}
+}
+
+// SendEnvelope sends the envelope with the provided status code
+func SendEnvelope[T any](w http.ResponseWriter, statusCode int, envelope Envelope[T]) error {
+	return Send(statusCode, w, envelope)
+}
+
+// SendOKData sends data under the dataName key with status "ok", it's a
+// typed variant of SendOK(w, BuildOkResponseWithData(dataName, data))
+func SendOKData[T any](w http.ResponseWriter, dataName string, data T) error {
+	return SendEnvelope(w, http.StatusOK, NewEnvelope(dataName, data))
+}
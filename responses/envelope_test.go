@@ -0,0 +1,175 @@
+/*
+Copyright © 2026 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package responses_test
+
+// Documentation in literate-programming-style is available at:
+// https://redhatinsights.github.io/insights-operator-utils/packages/responses/envelope_test.html
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/RedHatInsights/insights-operator-utils/responses"
+	"github.com/RedHatInsights/insights-operator-utils/tests/helpers"
+)
+
+type clusterInfo struct {
+	ID          string     `json:"cluster"`
+	DisplayName string     `json:"display_name,omitempty"`
+	LastChecked *time.Time `json:"last_checked_at"`
+	Hits        []int      `json:"hits"`
+	Meta        struct {
+		Managed bool `json:"managed"`
+	} `json:"meta"`
+}
+
+type clusterNode struct {
+	Name     string         `json:"name"`
+	Children []*clusterNode `json:"children,omitempty"`
+}
+
+func TestEnvelopeMarshalJSON(t *testing.T) {
+	total := 10
+	envelope := responses.NewEnvelope("clusters", []string{"c1", "c2"})
+	envelope.Meta = &responses.Meta{Count: 2, Total: &total, Limit: 2}
+	envelope.Links = &responses.Links{First: "/clusters?limit=2"}
+
+	encoded, err := json.Marshal(envelope)
+	helpers.FailOnError(t, err)
+	assert.JSONEq(t, `{
+		"status": "ok",
+		"clusters": ["c1", "c2"],
+		"meta": {"count": 2, "total": 10, "limit": 2, "offset": 0},
+		"links": {"first": "/clusters?limit=2"}
+	}`, string(encoded))
+
+	// the same format as the untyped response
+	encoded, err = json.Marshal(responses.Envelope[int]{Status: "ok", Data: 1})
+	helpers.FailOnError(t, err)
+	assert.JSONEq(t, `{"status": "ok", "data": 1}`, string(encoded))
+}
+
+func TestEnvelopeUnmarshalJSON(t *testing.T) {
+	envelope := responses.Envelope[[]string]{DataName: "clusters"}
+	err := json.Unmarshal([]byte(`{"status": "ok", "clusters": ["c1"], "meta": {"count": 1}}`), &envelope)
+	helpers.FailOnError(t, err)
+
+	assert.Equal(t, "ok", envelope.Status)
+	assert.Equal(t, []string{"c1"}, envelope.Data)
+	assert.Equal(t, 1, envelope.Meta.Count)
+	assert.Nil(t, envelope.Links)
+
+	err = json.Unmarshal([]byte(`{"status": "ok", "clusters": "c1"}`), &envelope)
+	assert.ErrorContains(t, err, "clusters:")
+}
+
+func TestSendOKData(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	helpers.FailOnError(t, responses.SendOKData(recorder, "cluster", clusterInfo{ID: "c1"}))
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "application/json; charset=utf-8", recorder.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{
+		"status": "ok",
+		"cluster": {"cluster": "c1", "last_checked_at": null, "hits": null, "meta": {"managed": false}}
+	}`, recorder.Body.String())
+
+	recorder = httptest.NewRecorder()
+	helpers.FailOnError(t, responses.SendEnvelope(recorder, http.StatusCreated, responses.NewEnvelope("id", 42)))
+	assert.Equal(t, http.StatusCreated, recorder.Code)
+	assert.JSONEq(t, `{"status": "ok", "id": 42}`, recorder.Body.String())
+}
+
+func TestSchemaFor(t *testing.T) {
+	assert.Equal(t, responses.Schema{
+		"type": "object",
+		"properties": map[string]responses.Schema{
+			"cluster":         {"type": "string"},
+			"display_name":    {"type": "string"},
+			"last_checked_at": {"type": "string", "format": "date-time", "nullable": true},
+			"hits":            {"type": "array", "items": responses.Schema{"type": "integer"}, "nullable": true},
+			"meta": {
+				"type":       "object",
+				"properties": map[string]responses.Schema{"managed": {"type": "boolean"}},
+				"required":   []string{"managed"},
+			},
+		},
+		"required": []string{"cluster", "last_checked_at", "hits", "meta"},
+	}, responses.SchemaFor[clusterInfo]())
+}
+
+func TestSchemaForRecursiveType(t *testing.T) {
+	schema := responses.SchemaFor[clusterNode]()
+	children := schema["properties"].(map[string]responses.Schema)["children"]
+	assert.Equal(t, responses.Schema{"type": "object", "nullable": true}, children["items"])
+}
+
+func TestSchemaOfEnvelope(t *testing.T) {
+	schema := responses.SchemaOf(responses.NewEnvelope("clusters", []string{}))
+
+	properties := schema["properties"].(map[string]responses.Schema)
+	assert.Equal(t, []string{"status", "clusters"}, schema["required"])
+	assert.Equal(t, responses.Schema{"type": "string"}, properties["status"])
+	assert.Equal(t, "array", properties["clusters"]["type"])
+	assert.Contains(t, properties, "meta")
+
+	// envelope nested in other type uses the default data name
+	nested := responses.SchemaFor[struct {
+		Response responses.Envelope[int] `json:"response"`
+	}]()
+	properties = nested["properties"].(map[string]responses.Schema)["response"]["properties"].(map[string]responses.Schema)
+	assert.Equal(t, responses.Schema{"type": "integer"}, properties["data"])
+}
+
+// describedValue is an interface that requires the schema of its values
+type describedValue interface {
+	responses.SchemaProvider
+}
+
+func TestSchemaForInterfaceSchemaProvider(t *testing.T) {
+	schema := responses.SchemaFor[struct {
+		Value describedValue `json:"value"`
+	}]()
+	assert.Equal(t, responses.Schema{}, schema["properties"].(map[string]responses.Schema)["value"])
+}
+
+func TestSchemaForStringOption(t *testing.T) {
+	schema := responses.SchemaFor[struct {
+		OrgID   int      `json:"org_id,string"`
+		Account *uint32  `json:"account,string"`
+		Managed bool     `json:"managed,string,omitempty"`
+		Score   float64  `json:"score,omitempty,string"`
+		Hits    []int    `json:"hits,string"`
+		Name    string   `json:"name,string"`
+		Tags    []string `json:"tags"`
+	}]()
+
+	properties := schema["properties"].(map[string]responses.Schema)
+	assert.Equal(t, responses.Schema{"type": "string"}, properties["org_id"])
+	assert.Equal(t, responses.Schema{"type": "string", "nullable": true}, properties["account"])
+	assert.Equal(t, responses.Schema{"type": "string"}, properties["managed"])
+	assert.Equal(t, responses.Schema{"type": "string"}, properties["score"])
+	assert.Equal(t, responses.Schema{"type": "string"}, properties["name"])
+	// option is ignored for other types
+	assert.Equal(t, "array", properties["hits"]["type"])
+	assert.Equal(t, []string{"org_id", "account", "hits", "name", "tags"}, schema["required"])
+}
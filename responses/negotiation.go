@@ -0,0 +1,487 @@
+/*
+Copyright © 2026 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package responses
+
+// Documentation in literate-programming-style is available at:
+// https://redhatinsights.github.io/insights-operator-utils/packages/responses/negotiation.html
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/andybalholm/brotli"
+)
+
+// Media types supported by SendNegotiated and StreamRows
+const (
+	MediaTypeJSON   = "application/json"
+	MediaTypeCSV    = "text/csv"
+	MediaTypeNDJSON = "application/x-ndjson"
+)
+
+// Content encodings supported for compression
+const (
+	EncodingGzip   = "gzip"
+	EncodingBrotli = "br"
+)
+
+const (
+	acceptHeader          = "Accept"
+	acceptEncodingHeader  = "Accept-Encoding"
+	contentEncodingHeader = "Content-Encoding"
+	contentLengthHeader   = "Content-Length"
+	varyHeader            = "Vary"
+
+	// compressMinSize is the minimal size of response worth compressing
+	compressMinSize = 1024
+)
+
+// Encoder writes data to the writer in one media type
+type Encoder func(w io.Writer, data interface{}) error
+
+// encoders contains encoders usable by SendNegotiated
+var encoders = map[string]Encoder{
+	MediaTypeJSON:   encodeJSON,
+	MediaTypeCSV:    encodeCSV,
+	MediaTypeNDJSON: encodeNDJSON,
+}
+
+// contentTypes contains values of Content-Type header for media types
+var contentTypes = map[string]string{
+	MediaTypeJSON:   appJSON,
+	MediaTypeCSV:    "text/csv; charset=utf-8",
+	MediaTypeNDJSON: MediaTypeNDJSON,
+}
+
+// RegisterEncoder registers encoder for the media type, so it can be offered
+// to SendNegotiated. It is expected to be called during initialization.
+func RegisterEncoder(mediaType string, encoder Encoder) {
+	encoders[mediaType] = encoder
+}
+
+// Table is tabular data, it is encoded as CSV with header row, as JSON array
+// of objects and as one JSON object per line in NDJSON
+type Table struct {
+	Header []string
+	Rows   [][]string
+}
+
+// objects converts the table rows to objects keyed by the header
+func (table Table) objects() []map[string]string {
+	objects := make([]map[string]string, 0, len(table.Rows))
+	for _, row := range table.Rows {
+		object := make(map[string]string, len(table.Header))
+		for i, name := range table.Header {
+			if i < len(row) {
+				object[name] = row[i]
+			}
+		}
+		objects = append(objects, object)
+	}
+	return objects
+}
+
+// SendNegotiated sends data in the media type selected from offered ones by
+// Accept header of the request. The first offered type is used when Accept
+// is not set, application/json is offered when none is given. Response is
+// compressed by gzip or brotli when the client accepts it and the response
+// is large enough. 406 Not Acceptable is sent when no offered type is
+// acceptable. Supported data depend on the media type:
+//
+//	application/json     - the same data as Send
+//	text/csv             - Table, [][]string or slice of structures
+//	application/x-ndjson - slice (one line per item) or any other value
+//
+// Nothing is written when data can't be encoded, the error is returned.
+func SendNegotiated(
+	w http.ResponseWriter, r *http.Request, statusCode int, data interface{}, offered ...string,
+) error {
+	if len(offered) == 0 {
+		offered = []string{MediaTypeJSON}
+	}
+
+	w.Header().Add(varyHeader, acceptHeader)
+
+	mediaType, found := NegotiateMediaType(r.Header.Get(acceptHeader), offered...)
+	if !found {
+		return Send(http.StatusNotAcceptable, w, "none of "+strings.Join(offered, ", ")+" is acceptable")
+	}
+
+	encoder, found := encoders[mediaType]
+	if !found {
+		return fmt.Errorf("no encoder registered for %s", mediaType)
+	}
+
+	var body bytes.Buffer
+	if err := encoder(&body, data); err != nil {
+		return err
+	}
+
+	w.Header().Set(contentType, contentTypeOf(mediaType))
+	if body.Len() < compressMinSize {
+		w.WriteHeader(statusCode)
+		_, err := w.Write(body.Bytes())
+		return err
+	}
+
+	writer, finish := compressResponse(w, r)
+	w.WriteHeader(statusCode)
+	if _, err := writer.Write(body.Bytes()); err != nil {
+		return err
+	}
+	return finish()
+}
+
+// NegotiateMediaType returns the offered media type preferred by the Accept
+// header value. The first offered type is returned when Accept is empty.
+func NegotiateMediaType(accept string, offered ...string) (string, bool) {
+	if len(offered) == 0 {
+		return "", false
+	}
+	if strings.TrimSpace(accept) == "" {
+		return offered[0], true
+	}
+
+	ranges := parseQualityList(accept)
+	best, bestQuality := "", 0.0
+	for _, mediaType := range offered {
+		quality := mediaTypeQuality(ranges, mediaType)
+		if quality > bestQuality {
+			best, bestQuality = mediaType, quality
+		}
+	}
+	return best, best != ""
+}
+
+// NegotiateEncoding returns the supported content encoding preferred by the
+// Accept-Encoding header value, brotli is preferred when both are accepted
+// equally. Empty string means no compression.
+func NegotiateEncoding(acceptEncoding string) string {
+	best, bestQuality := "", 0.0
+	for _, item := range parseQualityList(acceptEncoding) {
+		encoding := strings.ToLower(item.value)
+		if encoding == "*" {
+			encoding = EncodingBrotli
+		}
+		if (encoding != EncodingGzip && encoding != EncodingBrotli) || item.quality <= 0 {
+			continue
+		}
+		if item.quality > bestQuality || (item.quality == bestQuality && encoding == EncodingBrotli) {
+			best, bestQuality = encoding, item.quality
+		}
+	}
+	return best
+}
+
+// qualityValue is one item of header like Accept or Accept-Encoding
+type qualityValue struct {
+	value   string
+	quality float64
+}
+
+func parseQualityList(header string) []qualityValue {
+	var items []qualityValue
+	for _, item := range strings.Split(header, ",") {
+		value, params, _ := strings.Cut(item, ";")
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+
+		quality := 1.0
+		for _, param := range strings.Split(params, ";") {
+			name, raw, _ := strings.Cut(strings.TrimSpace(param), "=")
+			if strings.EqualFold(name, "q") {
+				if parsed, err := strconv.ParseFloat(raw, 64); err == nil {
+					quality = parsed
+				}
+			}
+		}
+		items = append(items, qualityValue{value: value, quality: quality})
+	}
+	return items
+}
+
+// mediaTypeQuality returns quality of the most specific media range
+// matching the media type
+func mediaTypeQuality(ranges []qualityValue, mediaType string) float64 {
+	typ, subtype, _ := strings.Cut(mediaType, "/")
+	quality, specificity := 0.0, -1
+	for _, mediaRange := range ranges {
+		rangeType, rangeSubtype, _ := strings.Cut(strings.ToLower(mediaRange.value), "/")
+		var matched int
+		switch {
+		case rangeType == typ && rangeSubtype == subtype:
+			matched = 2
+		case rangeType == typ && rangeSubtype == "*":
+			matched = 1
+		case rangeType == "*" && rangeSubtype == "*":
+			matched = 0
+		default:
+			continue
+		}
+		if matched > specificity {
+			quality, specificity = mediaRange.quality, matched
+		}
+	}
+	return quality
+}
+
+func contentTypeOf(mediaType string) string {
+	if value, found := contentTypes[mediaType]; found {
+		return value
+	}
+	return mime.FormatMediaType(mediaType, nil)
+}
+
+// flushWriter is implemented by gzip and brotli writers
+type flushWriter interface {
+	io.WriteCloser
+	Flush() error
+}
+
+// compressResponse returns writer compressing the response by encoding
+// accepted by the client and function finishing the compression. The
+// original writer is returned when the client accepts no supported
+// encoding.
+func compressResponse(w http.ResponseWriter, r *http.Request) (io.Writer, func() error) {
+	w.Header().Add(varyHeader, acceptEncodingHeader)
+
+	var compressor flushWriter
+	switch NegotiateEncoding(r.Header.Get(acceptEncodingHeader)) {
+	case EncodingGzip:
+		compressor = gzip.NewWriter(w)
+		w.Header().Set(contentEncodingHeader, EncodingGzip)
+	case EncodingBrotli:
+		compressor = brotli.NewWriter(w)
+		w.Header().Set(contentEncodingHeader, EncodingBrotli)
+	default:
+		return w, func() error { return nil }
+	}
+
+	w.Header().Del(contentLengthHeader)
+	return compressor, compressor.Close
+}
+
+func encodeJSON(w io.Writer, data interface{}) error {
+	switch data := data.(type) {
+	case nil:
+		return nil
+	case string:
+		return json.NewEncoder(w).Encode(BuildResponse(data))
+	case []byte:
+		_, err := w.Write(data)
+		return err
+	case Table:
+		return json.NewEncoder(w).Encode(data.objects())
+	default:
+		return json.NewEncoder(w).Encode(data)
+	}
+}
+
+func encodeNDJSON(w io.Writer, data interface{}) error {
+	if table, ok := data.(Table); ok {
+		data = table.objects()
+	}
+
+	encoder := json.NewEncoder(w)
+	value := reflect.ValueOf(data)
+	if value.Kind() != reflect.Slice || value.Type().Elem().Kind() == reflect.Uint8 {
+		return encoder.Encode(data)
+	}
+
+	for i := 0; i < value.Len(); i++ {
+		if err := encoder.Encode(value.Index(i).Interface()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func encodeCSV(w io.Writer, data interface{}) error {
+	writer := csv.NewWriter(w)
+
+	var records [][]string
+	switch data := data.(type) {
+	case Table:
+		records = append([][]string{data.Header}, data.Rows...)
+	case [][]string:
+		records = data
+	default:
+		value := reflect.ValueOf(data)
+		if value.Kind() != reflect.Slice {
+			return fmt.Errorf("unable to encode %T as CSV", data)
+		}
+		columns, err := csvColumns(value.Type().Elem())
+		if err != nil {
+			return err
+		}
+		records = append(records, csvHeader(columns))
+		for i := 0; i < value.Len(); i++ {
+			records = append(records, csvRecord(columns, value.Index(i)))
+		}
+	}
+
+	for _, record := range records {
+		if err := writeCSV(writer, record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// csvColumn is an exported field of structure encoded as CSV column
+type csvColumn struct {
+	name  string
+	index []int
+}
+
+// csvColumns returns columns of the structure type. The column name is
+// taken from csv tag, json tag or field name. Fields tagged by "-" and
+// fields promoted from unexported embedded structures, which can't be read
+// by reflection, are skipped.
+func csvColumns(structType reflect.Type) ([]csvColumn, error) {
+	for structType.Kind() == reflect.Pointer {
+		structType = structType.Elem()
+	}
+	if structType.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("unable to encode items of type %s as CSV", structType)
+	}
+
+	var columns []csvColumn
+	for _, field := range reflect.VisibleFields(structType) {
+		if !field.IsExported() || field.Anonymous || !isAccessible(structType, field.Index) {
+			continue
+		}
+
+		name := field.Name
+		if tag, found := field.Tag.Lookup("csv"); found {
+			name = tag
+		} else if tag, found := field.Tag.Lookup("json"); found {
+			if tagName, _, _ := strings.Cut(tag, ","); tagName != "" {
+				name = tagName
+			}
+		}
+		if name == "-" {
+			continue
+		}
+		columns = append(columns, csvColumn{name: name, index: field.Index})
+	}
+	return columns, nil
+}
+
+// isAccessible checks that the field with the index isn't promoted through
+// an unexported embedded field
+func isAccessible(structType reflect.Type, index []int) bool {
+	for i := 1; i < len(index); i++ {
+		if !structType.FieldByIndex(index[:i]).IsExported() {
+			return false
+		}
+	}
+	return true
+}
+
+// csvFormulaPrefixes start cells interpreted as formulas by spreadsheets
+const csvFormulaPrefixes = "=+-@\t\r"
+
+// csvEscape prevents formula injection by prefixing cells that would be
+// interpreted as formulas by a single quote, numbers are kept as they are
+func csvEscape(cell string) string {
+	if cell == "" || !strings.ContainsRune(csvFormulaPrefixes, rune(cell[0])) {
+		return cell
+	}
+	if _, err := strconv.ParseFloat(cell, 64); err == nil {
+		return cell
+	}
+	return "'" + cell
+}
+
+// writeCSV writes the record with escaped cells
+func writeCSV(writer *csv.Writer, record []string) error {
+	escaped := make([]string, len(record))
+	for i, cell := range record {
+		escaped[i] = csvEscape(cell)
+	}
+	return writer.Write(escaped)
+}
+
+func csvHeader(columns []csvColumn) []string {
+	header := make([]string, 0, len(columns))
+	for _, column := range columns {
+		header = append(header, column.name)
+	}
+	return header
+}
+
+func csvRecord(columns []csvColumn, value reflect.Value) []string {
+	record := make([]string, len(columns))
+	for value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			return record
+		}
+		value = value.Elem()
+	}
+
+	for i, column := range columns {
+		field, err := value.FieldByIndexErr(column.index)
+		if err != nil {
+			continue
+		}
+		record[i] = csvValue(field)
+	}
+	return record
+}
+
+// csvValue formats one value, nil pointers are empty and times are in RFC
+// 3339 format
+func csvValue(value reflect.Value) string {
+	for value.Kind() == reflect.Pointer || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return ""
+		}
+		value = value.Elem()
+	}
+
+	switch data := value.Interface().(type) {
+	case time.Time:
+		return data.Format(time.RFC3339)
+	case fmt.Stringer:
+		return data.String()
+	}
+
+	switch value.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map, reflect.Struct:
+		encoded, err := json.Marshal(value.Interface())
+		if err != nil {
+			return fmt.Sprint(value.Interface())
+		}
+		return string(encoded)
+	default:
+		return fmt.Sprint(value.Interface())
+	}
+}
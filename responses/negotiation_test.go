@@ -0,0 +1,238 @@
+/*
+Copyright © 2026 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package responses_test
+
+// Documentation in literate-programming-style is available at:
+// https://redhatinsights.github.io/insights-operator-utils/packages/responses/negotiation_test.html
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andybalholm/brotli"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/RedHatInsights/insights-operator-utils/responses"
+	"github.com/RedHatInsights/insights-operator-utils/tests/helpers"
+)
+
+type ruleHit struct {
+	ClusterID   string     `json:"cluster"`
+	RuleID      string     `csv:"rule_id"`
+	TotalRisk   int        `json:"total_risk"`
+	Impacted    *time.Time `json:"impacted"`
+	Tags        []string   `json:"tags"`
+	Description string     `json:"-"`
+}
+
+var (
+	impacted = time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	ruleHits = []ruleHit{
+		{ClusterID: "c1", RuleID: "rule.a|KEY", TotalRisk: 2, Impacted: &impacted, Tags: []string{"security"}},
+		{ClusterID: "c2", RuleID: "rule.b|KEY", TotalRisk: 1, Description: "skipped"},
+	}
+)
+
+func sendNegotiated(
+	t *testing.T, accept, acceptEncoding string, data interface{}, offered ...string,
+) *httptest.ResponseRecorder {
+	request := httptest.NewRequest(http.MethodGet, "/report", http.NoBody)
+	if accept != "" {
+		request.Header.Set("Accept", accept)
+	}
+	if acceptEncoding != "" {
+		request.Header.Set("Accept-Encoding", acceptEncoding)
+	}
+	recorder := httptest.NewRecorder()
+	helpers.FailOnError(t, responses.SendNegotiated(recorder, request, http.StatusOK, data, offered...))
+	return recorder
+}
+
+func TestNegotiateMediaType(t *testing.T) {
+	offered := []string{responses.MediaTypeJSON, responses.MediaTypeCSV}
+
+	testCases := []struct {
+		accept   string
+		expected string
+		found    bool
+	}{
+		{"", responses.MediaTypeJSON, true},
+		{"*/*", responses.MediaTypeJSON, true},
+		{"text/csv", responses.MediaTypeCSV, true},
+		{"text/*", responses.MediaTypeCSV, true},
+		{"application/json;q=0.5, text/csv", responses.MediaTypeCSV, true},
+		{"text/csv;q=0.1, */*;q=0.5", responses.MediaTypeJSON, true},
+		{"*/*, text/csv;q=0", responses.MediaTypeJSON, true},
+		{"application/xml", "", false},
+		{"text/csv;q=0", "", false},
+	}
+
+	for _, testCase := range testCases {
+		mediaType, found := responses.NegotiateMediaType(testCase.accept, offered...)
+		assert.Equal(t, testCase.expected, mediaType, testCase.accept)
+		assert.Equal(t, testCase.found, found, testCase.accept)
+	}
+}
+
+func TestNegotiateEncoding(t *testing.T) {
+	testCases := map[string]string{
+		"":                    "",
+		"identity":            "",
+		"gzip":                responses.EncodingGzip,
+		"gzip, deflate, br":   responses.EncodingBrotli,
+		"br;q=0.5, gzip":      responses.EncodingGzip,
+		"*":                   responses.EncodingBrotli,
+		"gzip;q=0, br;q=0":    "",
+		"deflate, GZIP;q=0.8": responses.EncodingGzip,
+	}
+
+	for acceptEncoding, expected := range testCases {
+		assert.Equal(t, expected, responses.NegotiateEncoding(acceptEncoding), acceptEncoding)
+	}
+}
+
+func TestSendNegotiatedJSON(t *testing.T) {
+	recorder := sendNegotiated(t, "", "", responses.BuildOkResponseWithData("report", ruleHits))
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "application/json; charset=utf-8", recorder.Header().Get("Content-Type"))
+	assert.Equal(t, "Accept", recorder.Header().Get("Vary"))
+	assert.Contains(t, recorder.Body.String(), `"cluster":"c1"`)
+}
+
+func TestSendNegotiatedCSV(t *testing.T) {
+	recorder := sendNegotiated(t, "text/csv", "", ruleHits, responses.MediaTypeJSON, responses.MediaTypeCSV)
+
+	assert.Equal(t, "text/csv; charset=utf-8", recorder.Header().Get("Content-Type"))
+	assert.Equal(t, "cluster,rule_id,total_risk,impacted,tags\n"+
+		"c1,rule.a|KEY,2,2026-10-01T12:00:00Z,\"[\"\"security\"\"]\"\n"+
+		"c2,rule.b|KEY,1,,null\n", recorder.Body.String())
+
+	table := responses.Table{Header: []string{"cluster", "hits"}, Rows: [][]string{{"c1", "2"}, {"c2", "0"}}}
+	recorder = sendNegotiated(t, "text/csv", "", table, responses.MediaTypeJSON, responses.MediaTypeCSV)
+	assert.Equal(t, "cluster,hits\nc1,2\nc2,0\n", recorder.Body.String())
+
+	// the same table as JSON
+	recorder = sendNegotiated(t, "application/json", "", table, responses.MediaTypeJSON, responses.MediaTypeCSV)
+	assert.JSONEq(t, `[{"cluster": "c1", "hits": "2"}, {"cluster": "c2", "hits": "0"}]`, recorder.Body.String())
+}
+
+type ruleMetadata struct {
+	Severity string `json:"severity"`
+}
+
+type PublicMetadata struct {
+	Category string `json:"category"`
+}
+
+type embeddedRuleHit struct {
+	ruleMetadata
+	PublicMetadata
+	RuleID string `json:"rule_id"`
+}
+
+func TestSendNegotiatedCSVEmbeddedStructures(t *testing.T) {
+	hits := []embeddedRuleHit{{ruleMetadata{"high"}, PublicMetadata{"security"}, "rule.a|KEY"}}
+	recorder := sendNegotiated(t, "text/csv", "", hits, responses.MediaTypeCSV)
+
+	// fields of unexported embedded structure can't be read
+	assert.Equal(t, "category,rule_id\nsecurity,rule.a|KEY\n", recorder.Body.String())
+}
+
+func TestSendNegotiatedCSVFormulaInjection(t *testing.T) {
+	table := responses.Table{
+		Header: []string{"name", "value"},
+		Rows:   [][]string{{"=HYPERLINK(\"http://evil\")", "-1.5"}, {"@SUM(A1)", "+1"}, {"-cmd", "a=b"}},
+	}
+	recorder := sendNegotiated(t, "text/csv", "", table, responses.MediaTypeCSV)
+	assert.Equal(t, "name,value\n"+
+		"\"'=HYPERLINK(\"\"http://evil\"\")\",-1.5\n"+
+		"'@SUM(A1),+1\n"+
+		"'-cmd,a=b\n", recorder.Body.String())
+}
+
+func TestSendNegotiatedCSVUnsupportedData(t *testing.T) {
+	request := httptest.NewRequest(http.MethodGet, "/report", http.NoBody)
+	request.Header.Set("Accept", "text/csv")
+	recorder := httptest.NewRecorder()
+
+	err := responses.SendNegotiated(recorder, request, http.StatusOK, map[string]int{"a": 1}, responses.MediaTypeCSV)
+	assert.EqualError(t, err, "unable to encode map[string]int as CSV")
+	assert.Empty(t, recorder.Body.String())
+
+	err = responses.SendNegotiated(recorder, request, http.StatusOK, []int{1}, responses.MediaTypeCSV)
+	assert.EqualError(t, err, "unable to encode items of type int as CSV")
+}
+
+func TestSendNegotiatedNDJSON(t *testing.T) {
+	recorder := sendNegotiated(t, responses.MediaTypeNDJSON, "", ruleHits, responses.MediaTypeJSON, responses.MediaTypeNDJSON)
+
+	assert.Equal(t, responses.MediaTypeNDJSON, recorder.Header().Get("Content-Type"))
+	lines := strings.Split(strings.TrimSpace(recorder.Body.String()), "\n")
+	assert.Len(t, lines, 2)
+	assert.True(t, strings.HasPrefix(lines[1], `{"cluster":"c2"`))
+}
+
+func TestSendNegotiatedNotAcceptable(t *testing.T) {
+	recorder := sendNegotiated(t, "application/xml", "", ruleHits, responses.MediaTypeJSON, responses.MediaTypeCSV)
+
+	assert.Equal(t, http.StatusNotAcceptable, recorder.Code)
+	assert.JSONEq(t, `{"status": "none of application/json, text/csv is acceptable"}`, recorder.Body.String())
+}
+
+func TestSendNegotiatedCompression(t *testing.T) {
+	largeReport := make([]ruleHit, 100)
+	for i := range largeReport {
+		largeReport[i] = ruleHits[0]
+	}
+	expected, err := json.Marshal(largeReport)
+	helpers.FailOnError(t, err)
+
+	recorder := sendNegotiated(t, "", "gzip", largeReport)
+	assert.Equal(t, "gzip", recorder.Header().Get("Content-Encoding"))
+	assert.Equal(t, []string{"Accept", "Accept-Encoding"}, recorder.Header().Values("Vary"))
+	reader, err := gzip.NewReader(recorder.Body)
+	helpers.FailOnError(t, err)
+	body, err := io.ReadAll(reader)
+	helpers.FailOnError(t, err)
+	assert.JSONEq(t, string(expected), string(body))
+
+	recorder = sendNegotiated(t, "", "gzip, br", largeReport)
+	assert.Equal(t, "br", recorder.Header().Get("Content-Encoding"))
+	body, err = io.ReadAll(brotli.NewReader(recorder.Body))
+	helpers.FailOnError(t, err)
+	assert.JSONEq(t, string(expected), string(body))
+
+	// small responses are not compressed
+	recorder = sendNegotiated(t, "", "gzip", ruleHits)
+	assert.Empty(t, recorder.Header().Get("Content-Encoding"))
+}
+
+func TestSendHelpersUnchanged(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	helpers.FailOnError(t, responses.SendOK(recorder, responses.BuildOkResponse()))
+
+	assert.Equal(t, "application/json; charset=utf-8", recorder.Header().Get("Content-Type"))
+	assert.Empty(t, recorder.Header().Get("Vary"))
+	assert.JSONEq(t, `{"status": "ok"}`, recorder.Body.String())
+}
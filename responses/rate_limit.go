@@ -0,0 +1,77 @@
+/*
+Copyright © 2026 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package responses
+
+// Documentation in literate-programming-style is available at:
+// https://redhatinsights.github.io/insights-operator-utils/packages/responses/rate_limit.html
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Names of headers describing the rate limit of the client
+const (
+	RateLimitLimitHeader     = "RateLimit-Limit"
+	RateLimitRemainingHeader = "RateLimit-Remaining"
+	RateLimitResetHeader     = "RateLimit-Reset"
+	RetryAfterHeader         = "Retry-After"
+)
+
+// RateLimit describes the state of rate limit of the client
+type RateLimit struct {
+	// Limit is the number of requests allowed in the period
+	Limit int
+	// Remaining is the number of requests the client can still send
+	Remaining int
+	// Reset is the time remaining until the quota is fully restored
+	Reset time.Duration
+}
+
+// SetHeaders sets RateLimit-Limit, RateLimit-Remaining and RateLimit-Reset
+// headers of the response, times are sent in whole seconds
+func (rateLimit RateLimit) SetHeaders(w http.ResponseWriter) {
+	w.Header().Set(RateLimitLimitHeader, strconv.Itoa(rateLimit.Limit))
+	w.Header().Set(RateLimitRemainingHeader, strconv.Itoa(max(rateLimit.Remaining, 0)))
+	w.Header().Set(RateLimitResetHeader, strconv.Itoa(seconds(rateLimit.Reset)))
+}
+
+// SendRateLimited returns response with status Too Many Requests 429 and
+// Retry-After header
+func SendRateLimited(w http.ResponseWriter, retryAfter time.Duration, errorMessage string) error {
+	w.Header().Set(RetryAfterHeader, strconv.Itoa(seconds(retryAfter)))
+	return SendTooManyRequests(w, errorMessage)
+}
+
+// SetRetryAfter sets Retry-After header of the response in whole seconds,
+// the header is not set when the time is not known (zero)
+func SetRetryAfter(w http.ResponseWriter, retryAfter time.Duration) {
+	if retryAfter > 0 {
+		w.Header().Set(RetryAfterHeader, strconv.Itoa(seconds(retryAfter)))
+	}
+}
+
+// seconds rounds the duration up to whole seconds, negative durations are
+// reported as zero
+func seconds(duration time.Duration) int {
+	if duration <= 0 {
+		return 0
+	}
+	return int(math.Ceil(duration.Seconds()))
+}
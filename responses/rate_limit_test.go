@@ -0,0 +1,72 @@
+/*
+Copyright © 2026 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package responses_test
+
+// Documentation in literate-programming-style is available at:
+// https://redhatinsights.github.io/insights-operator-utils/packages/responses/rate_limit_test.html
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/RedHatInsights/insights-operator-utils/responses"
+	"github.com/RedHatInsights/insights-operator-utils/tests/helpers"
+)
+
+func TestRateLimitSetHeaders(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	responses.RateLimit{Limit: 100, Remaining: -1, Reset: 1500 * time.Millisecond}.SetHeaders(recorder)
+
+	assert.Equal(t, "100", recorder.Header().Get(responses.RateLimitLimitHeader))
+	assert.Equal(t, "0", recorder.Header().Get(responses.RateLimitRemainingHeader))
+	assert.Equal(t, "2", recorder.Header().Get(responses.RateLimitResetHeader))
+}
+
+func TestSendRateLimited(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	err := responses.SendRateLimited(recorder, 30*time.Second, "rate limit exceeded")
+	helpers.FailOnError(t, err)
+
+	assert.Equal(t, http.StatusTooManyRequests, recorder.Code)
+	assert.Equal(t, "30", recorder.Header().Get(responses.RetryAfterHeader))
+	assert.JSONEq(t, `{"status":"rate limit exceeded"}`, recorder.Body.String())
+
+	recorder = httptest.NewRecorder()
+	err = responses.SendRateLimited(recorder, -time.Second, "rate limit exceeded")
+	helpers.FailOnError(t, err)
+	assert.Equal(t, "0", recorder.Header().Get(responses.RetryAfterHeader))
+}
+
+func TestSendServiceUnavailableRetryAfter(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	err := responses.SendServiceUnavailableRetryAfter(recorder, 1500*time.Millisecond, "circuit breaker is open")
+	helpers.FailOnError(t, err)
+
+	assert.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+	assert.Equal(t, "2", recorder.Header().Get(responses.RetryAfterHeader))
+	assert.JSONEq(t, `{"status":"circuit breaker is open"}`, recorder.Body.String())
+
+	// unknown time is not sent
+	recorder = httptest.NewRecorder()
+	err = responses.SendServiceUnavailableRetryAfter(recorder, 0, "circuit breaker is open")
+	helpers.FailOnError(t, err)
+	assert.Empty(t, recorder.Header().Values(responses.RetryAfterHeader))
+}
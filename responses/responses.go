@@ -23,11 +23,14 @@ package responses
 import (
 	"encoding/json"
 	"net/http"
+	"time"
 )
 
 const (
 	contentType = "Content-Type"
 	appJSON     = "application/json; charset=utf-8"
+	// ProblemJSON is the media type of RFC 7807 problem details
+	ProblemJSON = "application/problem+json"
 	statusKey   = "status"
 )
 
@@ -116,6 +119,21 @@ func SendNotFound(w http.ResponseWriter, errorMessage string) error {
 	return Send(http.StatusNotFound, w, errorMessage)
 }
 
+// SendPayloadTooLarge returns response with status Request Entity Too Large 413
+func SendPayloadTooLarge(w http.ResponseWriter, errorMessage string) error {
+	return Send(http.StatusRequestEntityTooLarge, w, errorMessage)
+}
+
+// SendUnsupportedMediaType returns response with status Unsupported Media Type 415
+func SendUnsupportedMediaType(w http.ResponseWriter, errorMessage string) error {
+	return Send(http.StatusUnsupportedMediaType, w, errorMessage)
+}
+
+// SendTooManyRequests returns response with status Too Many Requests 429
+func SendTooManyRequests(w http.ResponseWriter, errorMessage string) error {
+	return Send(http.StatusTooManyRequests, w, errorMessage)
+}
+
 // SendInternalServerError returns response with status Internal Server Error 500
 func SendInternalServerError(w http.ResponseWriter, errorMessage string) error {
 	return Send(http.StatusInternalServerError, w, errorMessage)
@@ -125,3 +143,18 @@ func SendInternalServerError(w http.ResponseWriter, errorMessage string) error {
 func SendServiceUnavailable(w http.ResponseWriter, errorMessage string) error {
 	return Send(http.StatusServiceUnavailable, w, errorMessage)
 }
+
+// SendServiceUnavailableRetryAfter returns response with status Service
+// Unavailable 503 and Retry-After header, see SetRetryAfter
+func SendServiceUnavailableRetryAfter(w http.ResponseWriter, retryAfter time.Duration, errorMessage string) error {
+	SetRetryAfter(w, retryAfter)
+	return SendServiceUnavailable(w, errorMessage)
+}
+
+// SendProblem returns RFC 7807 problem details with the provided statusCode
+// and Content-Type set to application/problem+json
+func SendProblem(w http.ResponseWriter, statusCode int, problem interface{}) error {
+	w.Header().Set(contentType, ProblemJSON)
+	w.WriteHeader(statusCode)
+	return json.NewEncoder(w).Encode(problem)
+}
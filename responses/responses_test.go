@@ -76,6 +76,9 @@ var headerTestsWithoutData = []struct {
 	{"responses.SendForbidden", responses.SendForbidden, http.StatusForbidden},
 	{"responses.SendForbidden", responses.SendForbidden, http.StatusForbidden},
 	{"responses.SendNotFound", responses.SendNotFound, http.StatusNotFound},
+	{"responses.SendPayloadTooLarge", responses.SendPayloadTooLarge, http.StatusRequestEntityTooLarge},
+	{"responses.SendUnsupportedMediaType", responses.SendUnsupportedMediaType, http.StatusUnsupportedMediaType},
+	{"responses.SendTooManyRequests", responses.SendTooManyRequests, http.StatusTooManyRequests},
 	{"responses.SendInternalServerError", responses.SendInternalServerError, http.StatusInternalServerError},
 	{"responses.SendServiceUnavailable", responses.SendServiceUnavailable, http.StatusServiceUnavailable},
 }
@@ -289,3 +292,23 @@ func TestHeaders(t *testing.T) {
 		})
 	}
 }
+
+// TestSendProblem checks the status code, content type and body of problem
+// details response
+func TestSendProblem(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	err := responses.SendProblem(recorder, http.StatusBadRequest, map[string]interface{}{"title": "Bad Request"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if recorder.Code != http.StatusBadRequest {
+		t.Errorf("Expected status code %d, got %d", http.StatusBadRequest, recorder.Code)
+	}
+	if ct := recorder.Header().Get(contentType); ct != responses.ProblemJSON {
+		t.Errorf("Expected content type %s, got %s", responses.ProblemJSON, ct)
+	}
+	if body := strings.TrimSpace(recorder.Body.String()); body != `{"title":"Bad Request"}` {
+		t.Errorf("Unexpected body %s", body)
+	}
+}
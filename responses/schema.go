@@ -0,0 +1,198 @@
+/*
+Copyright © 2026 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package responses
+
+// Documentation in literate-programming-style is available at:
+// https://redhatinsights.github.io/insights-operator-utils/packages/responses/schema.html
+
+import (
+	"encoding"
+	"encoding/json"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Schema is JSON schema in the form used by OpenAPI 3.0 (nullable instead
+// of type lists)
+type Schema map[string]interface{}
+
+// SchemaProvider is implemented by types providing their own JSON schema,
+// e.g. types with custom JSON serialization
+type SchemaProvider interface {
+	JSONSchema() Schema
+}
+
+var (
+	timeType           = reflect.TypeFor[time.Time]()
+	schemaProviderType = reflect.TypeFor[SchemaProvider]()
+	jsonMarshalerType  = reflect.TypeFor[json.Marshaler]()
+	textMarshalerType  = reflect.TypeFor[encoding.TextMarshaler]()
+	rawJSONMessageType = reflect.TypeFor[json.RawMessage]()
+)
+
+// SchemaFor returns JSON schema of values of type T as they are serialized
+// by encoding/json
+func SchemaFor[T any]() Schema {
+	var value T
+	return SchemaOf(value)
+}
+
+// SchemaOf returns JSON schema of the value as it is serialized by
+// encoding/json. Only the type of the value matters, except for values
+// implementing SchemaProvider (e.g. Envelope with its DataName).
+//
+// Fields are named by json tags, fields without omitempty are required and
+// pointers are nullable. Types with custom serialization are described as
+// any value, unless they implement SchemaProvider or encoding.TextMarshaler.
+func SchemaOf(value interface{}) Schema {
+	if provider, ok := value.(SchemaProvider); ok {
+		return provider.JSONSchema()
+	}
+	if value == nil {
+		return Schema{}
+	}
+	return typeSchema(reflect.TypeOf(value), map[reflect.Type]bool{})
+}
+
+func typeSchema(typ reflect.Type, visiting map[reflect.Type]bool) Schema {
+	if typ.Kind() == reflect.Pointer {
+		schema := typeSchema(typ.Elem(), visiting)
+		schema["nullable"] = true
+		return schema
+	}
+
+	switch {
+	case typ == timeType:
+		return Schema{"type": "string", "format": "date-time"}
+	case typ == rawJSONMessageType:
+		return Schema{}
+	case typ.Kind() != reflect.Interface && typ.Implements(schemaProviderType):
+		// zero value of interface is nil, so it can't provide the schema
+		return reflect.Zero(typ).Interface().(SchemaProvider).JSONSchema()
+	case typ.Implements(jsonMarshalerType) || reflect.PointerTo(typ).Implements(jsonMarshalerType):
+		return Schema{}
+	case typ.Implements(textMarshalerType) || reflect.PointerTo(typ).Implements(textMarshalerType):
+		return Schema{"type": "string"}
+	}
+
+	switch typ.Kind() {
+	case reflect.Bool:
+		return Schema{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return Schema{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return Schema{"type": "number"}
+	case reflect.String:
+		return Schema{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		if typ.Elem().Kind() == reflect.Uint8 {
+			return Schema{"type": "string", "format": "byte"}
+		}
+		schema := Schema{"type": "array", "items": typeSchema(typ.Elem(), visiting)}
+		if typ.Kind() == reflect.Slice {
+			// nil slices are serialized as null
+			schema["nullable"] = true
+		}
+		return schema
+	case reflect.Map:
+		return Schema{"type": "object", "additionalProperties": typeSchema(typ.Elem(), visiting)}
+	case reflect.Struct:
+		return structSchema(typ, visiting)
+	default:
+		return Schema{}
+	}
+}
+
+func structSchema(typ reflect.Type, visiting map[reflect.Type]bool) Schema {
+	// recursive types are not expanded again
+	if visiting[typ] {
+		return Schema{"type": "object"}
+	}
+	visiting[typ] = true
+	defer delete(visiting, typ)
+
+	properties := map[string]Schema{}
+	required := []string{}
+	for _, field := range reflect.VisibleFields(typ) {
+		name, options, _ := strings.Cut(field.Tag.Get("json"), ",")
+
+		// fields of embedded structures are promoted, so they are visited
+		// on their own
+		if field.Anonymous && name == "" && indirectType(field.Type).Kind() == reflect.Struct {
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+
+		if name == "-" && options == "" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		fieldSchema := typeSchema(field.Type, visiting)
+		if isQuoted(field.Type, options) && fieldSchema["type"] != nil {
+			// values are encoded in JSON strings
+			fieldSchema = Schema{"type": "string"}
+			if field.Type.Kind() == reflect.Pointer {
+				fieldSchema["nullable"] = true
+			}
+		}
+		properties[name] = fieldSchema
+		if !strings.Contains(options, "omitempty") && !strings.Contains(options, "omitzero") {
+			required = append(required, name)
+		}
+	}
+
+	schema := Schema{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+// isQuoted checks whether the field is encoded as string because of the
+// ",string" option, it applies to basic types and unnamed pointers to them
+// like in encoding/json
+func isQuoted(typ reflect.Type, options string) bool {
+	if !slices.Contains(strings.Split(options, ","), "string") {
+		return false
+	}
+	if typ.Name() == "" && typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	switch typ.Kind() {
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.String:
+		return true
+	default:
+		return false
+	}
+}
+
+func indirectType(typ reflect.Type) reflect.Type {
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	return typ
+}
@@ -0,0 +1,197 @@
+/*
+Copyright © 2026 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package responses
+
+// Documentation in literate-programming-style is available at:
+// https://redhatinsights.github.io/insights-operator-utils/packages/responses/streaming.html
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"iter"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+)
+
+// streamFlushRows is the number of rows after which the streamed response
+// is flushed to the client
+const streamFlushRows = 100
+
+// StreamRows writes rows from the iterator as they are produced, so large
+// results don't have to be kept in memory. The format is selected by Accept
+// header from NDJSON (default), CSV and JSON array. CSV is offered only for
+// rows that are structures, 406 Not Acceptable is sent when none of the
+// formats is acceptable. Response is compressed when the client accepts it.
+//
+// The status code is sent before the first row, so an error returned by
+// the iterator or by writing can't change it. The response is aborted by
+// panicking with http.ErrAbortHandler instead, so the client sees a broken
+// transfer rather than truncated data that looks complete. The error is
+// logged before that.
+func StreamRows[T any](w http.ResponseWriter, r *http.Request, statusCode int, rows iter.Seq2[T, error]) error {
+	w.Header().Add(varyHeader, acceptHeader)
+
+	offered := []string{MediaTypeNDJSON, MediaTypeJSON}
+	columns, csvErr := csvColumns(reflect.TypeFor[T]())
+	if csvErr == nil {
+		offered = []string{MediaTypeNDJSON, MediaTypeCSV, MediaTypeJSON}
+	}
+	mediaType, found := NegotiateMediaType(r.Header.Get(acceptHeader), offered...)
+	if !found {
+		return Send(http.StatusNotAcceptable, w, "none of "+strings.Join(offered, ", ")+" is acceptable")
+	}
+
+	rowWriter := newRowWriter(mediaType, columns)
+
+	w.Header().Set(contentType, contentTypeOf(mediaType))
+	writer, finish := compressResponse(w, r)
+	w.WriteHeader(statusCode)
+
+	controller := http.NewResponseController(w)
+	flush := func() error {
+		if compressor, ok := writer.(flushWriter); ok {
+			if err := compressor.Flush(); err != nil {
+				return err
+			}
+		}
+		if err := controller.Flush(); err != nil && !errors.Is(err, http.ErrNotSupported) {
+			return err
+		}
+		return nil
+	}
+
+	err := rowWriter.begin(writer)
+	count := 0
+	for row, rowErr := range rows {
+		if err != nil {
+			break
+		}
+		if rowErr != nil {
+			err = rowErr
+			break
+		}
+		if err = rowWriter.write(writer, row); err != nil {
+			break
+		}
+		count++
+		if count%streamFlushRows == 0 {
+			err = flush()
+		}
+	}
+	if err == nil {
+		err = rowWriter.end(writer)
+	}
+	if err != nil {
+		// the output is not finished, so the client doesn't get data that
+		// look complete
+		log.Error().Err(err).Int("rows", count).Msg("Unable to stream rows, aborting response")
+		panic(http.ErrAbortHandler)
+	}
+	return finish()
+}
+
+// rowWriter writes rows in one format
+type rowWriter interface {
+	begin(w io.Writer) error
+	write(w io.Writer, row interface{}) error
+	end(w io.Writer) error
+}
+
+// newRowWriter returns writer of rows in the media type, columns are used
+// by CSV writer
+func newRowWriter(mediaType string, columns []csvColumn) rowWriter {
+	switch mediaType {
+	case MediaTypeCSV:
+		return &csvRowWriter{columns: columns}
+	case MediaTypeJSON:
+		return &jsonArrayRowWriter{}
+	default:
+		return ndjsonRowWriter{}
+	}
+}
+
+type ndjsonRowWriter struct{}
+
+func (ndjsonRowWriter) begin(io.Writer) error {
+	return nil
+}
+
+func (ndjsonRowWriter) write(w io.Writer, row interface{}) error {
+	return json.NewEncoder(w).Encode(row)
+}
+
+func (ndjsonRowWriter) end(io.Writer) error {
+	return nil
+}
+
+type csvRowWriter struct {
+	columns []csvColumn
+	writer  *csv.Writer
+}
+
+func (rowWriter *csvRowWriter) begin(w io.Writer) error {
+	rowWriter.writer = csv.NewWriter(w)
+	return writeCSV(rowWriter.writer, csvHeader(rowWriter.columns))
+}
+
+func (rowWriter *csvRowWriter) write(_ io.Writer, row interface{}) error {
+	if err := writeCSV(rowWriter.writer, csvRecord(rowWriter.columns, reflect.ValueOf(row))); err != nil {
+		return err
+	}
+	// csv.Writer is buffered, rows are passed on so they can be flushed
+	rowWriter.writer.Flush()
+	return rowWriter.writer.Error()
+}
+
+func (rowWriter *csvRowWriter) end(io.Writer) error {
+	rowWriter.writer.Flush()
+	return rowWriter.writer.Error()
+}
+
+type jsonArrayRowWriter struct {
+	rows int
+}
+
+func (rowWriter *jsonArrayRowWriter) begin(w io.Writer) error {
+	_, err := io.WriteString(w, "[")
+	return err
+}
+
+func (rowWriter *jsonArrayRowWriter) write(w io.Writer, row interface{}) error {
+	encoded, err := json.Marshal(row)
+	if err != nil {
+		return err
+	}
+	if rowWriter.rows > 0 {
+		if _, err := io.WriteString(w, ","); err != nil {
+			return err
+		}
+	}
+	rowWriter.rows++
+	_, err = w.Write(encoded)
+	return err
+}
+
+func (rowWriter *jsonArrayRowWriter) end(w io.Writer) error {
+	_, err := io.WriteString(w, "]\n")
+	return err
+}
@@ -0,0 +1,195 @@
+/*
+Copyright © 2026 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package responses_test
+
+// Documentation in literate-programming-style is available at:
+// https://redhatinsights.github.io/insights-operator-utils/packages/responses/streaming_test.html
+
+import (
+	"compress/gzip"
+	"errors"
+	"io"
+	"iter"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/RedHatInsights/insights-operator-utils/responses"
+	"github.com/RedHatInsights/insights-operator-utils/tests/helpers"
+)
+
+type clusterRow struct {
+	Cluster string `json:"cluster"`
+	Hits    int    `json:"hits"`
+}
+
+// clusterRows produces count rows and then the error, if any
+func clusterRows(count int, err error) iter.Seq2[clusterRow, error] {
+	return func(yield func(clusterRow, error) bool) {
+		for i := 0; i < count; i++ {
+			if !yield(clusterRow{Cluster: "c" + strconv.Itoa(i%10), Hits: i}, nil) {
+				return
+			}
+		}
+		if err != nil {
+			yield(clusterRow{}, err)
+		}
+	}
+}
+
+func streamRows(accept, acceptEncoding string, rows iter.Seq2[clusterRow, error]) (*httptest.ResponseRecorder, error) {
+	request := httptest.NewRequest(http.MethodGet, "/clusters", http.NoBody)
+	if accept != "" {
+		request.Header.Set("Accept", accept)
+	}
+	if acceptEncoding != "" {
+		request.Header.Set("Accept-Encoding", acceptEncoding)
+	}
+	recorder := httptest.NewRecorder()
+	err := responses.StreamRows(recorder, request, http.StatusOK, rows)
+	return recorder, err
+}
+
+func TestStreamRowsNDJSON(t *testing.T) {
+	recorder, err := streamRows("", "", clusterRows(3, nil))
+	helpers.FailOnError(t, err)
+
+	assert.Equal(t, responses.MediaTypeNDJSON, recorder.Header().Get("Content-Type"))
+	assert.Equal(t, `{"cluster":"c0","hits":0}`+"\n"+
+		`{"cluster":"c1","hits":1}`+"\n"+
+		`{"cluster":"c2","hits":2}`+"\n", recorder.Body.String())
+}
+
+func TestStreamRowsCSV(t *testing.T) {
+	recorder, err := streamRows("text/csv", "", clusterRows(2, nil))
+	helpers.FailOnError(t, err)
+
+	assert.Equal(t, "text/csv; charset=utf-8", recorder.Header().Get("Content-Type"))
+	assert.Equal(t, "cluster,hits\nc0,0\nc1,1\n", recorder.Body.String())
+}
+
+func TestStreamRowsJSONArray(t *testing.T) {
+	recorder, err := streamRows("application/json", "", clusterRows(2, nil))
+	helpers.FailOnError(t, err)
+	assert.JSONEq(t, `[{"cluster": "c0", "hits": 0}, {"cluster": "c1", "hits": 1}]`, recorder.Body.String())
+
+	recorder, err = streamRows("application/json", "", clusterRows(0, nil))
+	helpers.FailOnError(t, err)
+	assert.JSONEq(t, `[]`, recorder.Body.String())
+}
+
+func TestStreamRowsIteratorError(t *testing.T) {
+	iteratorErr := errors.New("database connection lost")
+
+	request := httptest.NewRequest(http.MethodGet, "/clusters", http.NoBody)
+	request.Header.Set("Accept", "application/json")
+	recorder := httptest.NewRecorder()
+	assert.PanicsWithValue(t, http.ErrAbortHandler, func() {
+		_ = responses.StreamRows(recorder, request, http.StatusOK, clusterRows(1, iteratorErr))
+	})
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	// the array is not closed, so the client doesn't get data that look
+	// complete
+	assert.Equal(t, `[{"cluster":"c0","hits":0}`, recorder.Body.String())
+}
+
+// failingWriter fails when more than limit bytes are written
+type failingWriter struct {
+	*httptest.ResponseRecorder
+	limit int
+}
+
+func (writer *failingWriter) Write(data []byte) (int, error) {
+	if writer.Body.Len()+len(data) > writer.limit {
+		return 0, errors.New("connection reset by peer")
+	}
+	return writer.ResponseRecorder.Write(data)
+}
+
+func TestStreamRowsWriteError(t *testing.T) {
+	request := httptest.NewRequest(http.MethodGet, "/clusters", http.NoBody)
+	request.Header.Set("Accept", "text/csv")
+	writer := &failingWriter{ResponseRecorder: httptest.NewRecorder(), limit: 20}
+
+	assert.PanicsWithValue(t, http.ErrAbortHandler, func() {
+		_ = responses.StreamRows(writer, request, http.StatusOK, clusterRows(10, nil))
+	})
+	assert.Equal(t, "cluster,hits\nc0,0\n", writer.Body.String())
+}
+
+// TestStreamRowsAbortedConnection checks that the client of real server
+// sees broken transfer when streaming fails
+func TestStreamRowsAbortedConnection(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		// rows are flushed, so the headers are sent before the failure
+		_ = responses.StreamRows(writer, request, http.StatusOK, clusterRows(150, errors.New("query failed")))
+	}))
+	defer server.Close()
+
+	response, err := http.Get(server.URL)
+	helpers.FailOnError(t, err)
+	defer response.Body.Close()
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+
+	_, err = io.ReadAll(response.Body)
+	assert.Error(t, err)
+}
+
+func TestStreamRowsCompressed(t *testing.T) {
+	recorder, err := streamRows("", "gzip", clusterRows(250, nil))
+	helpers.FailOnError(t, err)
+	assert.Equal(t, "gzip", recorder.Header().Get("Content-Encoding"))
+	// rows are flushed to the client while streaming
+	assert.True(t, recorder.Flushed)
+
+	reader, err := gzip.NewReader(recorder.Body)
+	helpers.FailOnError(t, err)
+	body, err := io.ReadAll(reader)
+	helpers.FailOnError(t, err)
+	assert.Len(t, strings.Split(strings.TrimSpace(string(body)), "\n"), 250)
+}
+
+func TestStreamRowsNotAcceptable(t *testing.T) {
+	recorder, err := streamRows("application/xml", "", clusterRows(1, nil))
+	helpers.FailOnError(t, err)
+	assert.Equal(t, http.StatusNotAcceptable, recorder.Code)
+}
+
+func TestStreamRowsCSVUnsupportedRows(t *testing.T) {
+	rows := func(yield func(string, error) bool) {
+		yield("c1", nil)
+	}
+	stream := func(accept string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(http.MethodGet, "/clusters", http.NoBody)
+		request.Header.Set("Accept", accept)
+		recorder := httptest.NewRecorder()
+		helpers.FailOnError(t, responses.StreamRows[string](recorder, request, http.StatusOK, rows))
+		return recorder
+	}
+
+	// rows that can't be sent as CSV are not offered in that format
+	recorder := stream("text/csv")
+	assert.Equal(t, http.StatusNotAcceptable, recorder.Code)
+
+	recorder = stream("text/csv, application/json;q=0.5")
+	assert.Equal(t, "application/json; charset=utf-8", recorder.Header().Get("Content-Type"))
+	assert.JSONEq(t, `["c1"]`, recorder.Body.String())
+}
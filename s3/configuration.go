@@ -0,0 +1,56 @@
+// Copyright 2026 Red Hat, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package s3util
+
+// Documentation in literate-programming-style is available at:
+// https://redhatinsights.github.io/insights-operator-utils/packages/s3/configuration.html
+
+import (
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// Configuration represents configuration of the access to S3 bucket
+type Configuration struct {
+	Endpoint  string `mapstructure:"endpoint" toml:"endpoint"`
+	Region    string `mapstructure:"region" toml:"region"`
+	Bucket    string `mapstructure:"bucket" toml:"bucket"`
+	AccessKey string `mapstructure:"access_key" toml:"access_key"`
+	SecretKey string `mapstructure:"secret_key" toml:"secret_key"`
+	UseSSL    bool   `mapstructure:"use_ssl" toml:"use_ssl"`
+}
+
+// EndpointURL returns URL of the S3 endpoint with the scheme based on UseSSL
+func (configuration *Configuration) EndpointURL() string {
+	if configuration.UseSSL {
+		return "https://" + configuration.Endpoint
+	}
+	return "http://" + configuration.Endpoint
+}
+
+// NewClient creates a S3 client that can be used with all helper functions
+// from this package. Path-style addressing is used, so the client works with
+// S3-compatible storages like MinIO too.
+func NewClient(configuration *Configuration) *s3.Client {
+	return s3.New(s3.Options{
+		BaseEndpoint: aws.String(configuration.EndpointURL()),
+		Region:       configuration.Region,
+		Credentials: credentials.NewStaticCredentialsProvider(
+			configuration.AccessKey, configuration.SecretKey, "",
+		),
+		UsePathStyle: true,
+	})
+}
@@ -0,0 +1,56 @@
+// Copyright 2026 Red Hat, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package s3util_test
+
+// Documentation in literate-programming-style is available at:
+// https://redhatinsights.github.io/insights-operator-utils/packages/s3/configuration_test.html
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	s3util "github.com/RedHatInsights/insights-operator-utils/s3"
+)
+
+func TestEndpointURL(t *testing.T) {
+	configuration := s3util.Configuration{Endpoint: "minio:9000"}
+	assert.Equal(t, "http://minio:9000", configuration.EndpointURL())
+
+	configuration.UseSSL = true
+	assert.Equal(t, "https://minio:9000", configuration.EndpointURL())
+}
+
+func TestNewClient(t *testing.T) {
+	configuration := s3util.Configuration{
+		Endpoint:  "minio:9000",
+		Region:    "us-east-1",
+		AccessKey: "access",
+		SecretKey: "secret",
+	}
+
+	client := s3util.NewClient(&configuration)
+	options := client.Options()
+
+	assert.Equal(t, "http://minio:9000", *options.BaseEndpoint)
+	assert.Equal(t, "us-east-1", options.Region)
+	assert.True(t, options.UsePathStyle)
+
+	credentials, err := options.Credentials.Retrieve(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "access", credentials.AccessKeyID)
+	assert.Equal(t, "secret", credentials.SecretAccessKey)
+}
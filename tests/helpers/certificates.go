@@ -0,0 +1,123 @@
+// Copyright 2026 Red Hat, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helpers
+
+// Documentation in literate-programming-style is available at:
+// https://redhatinsights.github.io/insights-operator-utils/packages/tests/helpers/certificates.html
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestCertificateAuthority is a certificate authority that can issue
+// certificates for unit tests
+type TestCertificateAuthority struct {
+	Certificate    *x509.Certificate
+	CertificatePEM []byte
+	key            *ecdsa.PrivateKey
+}
+
+// TestCertificate is a certificate issued by TestCertificateAuthority together
+// with its private key
+type TestCertificate struct {
+	Certificate    *x509.Certificate
+	CertificatePEM []byte
+	KeyPEM         []byte
+}
+
+// NewTestCertificateAuthority generates a self-signed CA certificate valid for
+// the given duration
+func NewTestCertificateAuthority(t testing.TB, commonName string, validFor time.Duration) *TestCertificateAuthority {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	FailOnError(t, err)
+
+	template := certificateTemplate(t, commonName, validFor)
+	template.IsCA = true
+	template.BasicConstraintsValid = true
+	template.KeyUsage = x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	FailOnError(t, err)
+
+	certificate, err := x509.ParseCertificate(der)
+	FailOnError(t, err)
+
+	return &TestCertificateAuthority{
+		Certificate:    certificate,
+		CertificatePEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		key:            key,
+	}
+}
+
+// Issue generates a certificate signed by the authority. The certificate is
+// valid for "localhost" and 127.0.0.1, and can be used by both servers and
+// clients.
+func (ca *TestCertificateAuthority) Issue(t testing.TB, commonName string, validFor time.Duration) *TestCertificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	FailOnError(t, err)
+
+	template := certificateTemplate(t, commonName, validFor)
+	template.KeyUsage = x509.KeyUsageDigitalSignature
+	template.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth}
+	template.DNSNames = []string{"localhost"}
+	template.IPAddresses = []net.IP{net.IPv4(127, 0, 0, 1), net.IPv6loopback}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.Certificate, &key.PublicKey, ca.key)
+	FailOnError(t, err)
+
+	certificate, err := x509.ParseCertificate(der)
+	FailOnError(t, err)
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	FailOnError(t, err)
+
+	return &TestCertificate{
+		Certificate:    certificate,
+		CertificatePEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		KeyPEM:         pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+	}
+}
+
+// WriteTestFile writes the content into a file in the given directory and
+// returns path to the file
+func WriteTestFile(t testing.TB, dir, name string, content []byte) string {
+	path := filepath.Join(dir, name)
+	FailOnError(t, os.WriteFile(path, content, 0o600))
+	return path
+}
+
+func certificateTemplate(t testing.TB, commonName string, validFor time.Duration) *x509.Certificate {
+	serialNumber, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	FailOnError(t, err)
+
+	now := time.Now()
+	return &x509.Certificate{
+		SerialNumber: serialNumber,
+		Subject:      pkix.Name{CommonName: commonName, Organization: []string{"Red Hat"}},
+		NotBefore:    now.Add(-time.Minute),
+		NotAfter:     now.Add(validFor),
+	}
+}
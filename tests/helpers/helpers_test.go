@@ -23,6 +23,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
 	"time"
@@ -93,6 +94,23 @@ func TestNewMicroHTTPServer(t *testing.T) {
 	server.AddEndpoint("/", func(http.ResponseWriter, *http.Request) {})
 }
 
+func TestNewMicroHTTPServerWithMiddlewares(t *testing.T) {
+	handler := func(http.ResponseWriter, *http.Request) {}
+
+	// standard middlewares are opt-in
+	server := helpers.NewMicroHTTPServer(":"+fmt.Sprint(port), "/api/")
+	server.AddEndpoint("test", handler)
+	recorder := httptest.NewRecorder()
+	server.Initialize().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api/test", http.NoBody))
+	assert.Empty(t, recorder.Header().Get(httputils.RequestIDHeader))
+
+	server = helpers.NewMicroHTTPServerWithMiddlewares(":"+fmt.Sprint(port), "/api/", httputils.StandardMiddlewares()...)
+	server.AddEndpoint("test", handler)
+	recorder = httptest.NewRecorder()
+	server.Initialize().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api/test", http.NoBody))
+	assert.NotEmpty(t, recorder.Header().Get(httputils.RequestIDHeader))
+}
+
 func TestMustGobSerialize(t *testing.T) {
 	objectToSerialize := 1
 	bytesResult := helpers.MustGobSerialize(t, objectToSerialize)
@@ -213,6 +231,18 @@ func TestAssertAPIRequest(t *testing.T) {
 		},
 	})
 
+	t.Run("TypedBody", func(t *testing.T) {
+		helpers.AssertAPIRequest(t, testServer, apiPrefix, &helpers.APIRequest{
+			Method:       http.MethodGet,
+			Endpoint:     endpoint,
+			EndpointArgs: []interface{}{expectedURLParam},
+			Body:         map[string]string{"test": "json"},
+		}, &helpers.APIResponse{
+			StatusCode: http.StatusOK,
+			Body:       map[string]uint64{"param": expectedURLParam},
+		})
+	})
+
 	t.Run("NoBodyChecker", func(t *testing.T) {
 		helpers.AssertAPIRequest(t, testServer, apiPrefix, &helpers.APIRequest{
 			Method:       http.MethodGet,
@@ -226,6 +256,53 @@ func TestAssertAPIRequest(t *testing.T) {
 	})
 }
 
+func TestAssertAPIRequestMatchesSpec(t *testing.T) {
+	const (
+		apiPrefix = "/api/v1/"
+		endpoint  = "clusters/{cluster}"
+		spec      = `{
+			"openapi": "3.0.0",
+			"info": {"title": "title", "version": "1.0.0"},
+			"paths": {
+				"/clusters/{cluster}": {
+					"get": {
+						"parameters": [{"name": "cluster", "in": "path", "required": true, "schema": {"type": "string"}}],
+						"responses": {
+							"200": {"description": "cluster", "content": {"application/json": {"schema": {
+								"type": "object",
+								"required": ["status", "cluster"],
+								"properties": {"status": {"type": "string"}, "cluster": {"type": "string"}}
+							}}}},
+							"400": {"description": "invalid cluster"}
+						}
+					}
+				}
+			}
+		}`
+	)
+
+	validator, err := httputils.NewOpenAPIValidator(spec, httputils.OpenAPIValidationConfiguration{APIPrefix: apiPrefix})
+	helpers.FailOnError(t, err)
+
+	testServer := helpers.NewMicroHTTPServer(":8080", apiPrefix)
+	testServer.AddEndpoint(endpoint, func(writer http.ResponseWriter, request *http.Request) {
+		cluster, err := httputils.GetRouterParam(request, "cluster")
+		helpers.FailOnError(t, err)
+
+		err = responses.SendOK(writer, responses.BuildOkResponseWithData("cluster", cluster))
+		helpers.FailOnError(t, err)
+	})
+
+	helpers.AssertAPIRequestMatchesSpec(t, validator, testServer, apiPrefix, &helpers.APIRequest{
+		Method:       http.MethodGet,
+		Endpoint:     endpoint,
+		EndpointArgs: []interface{}{"c1"},
+	}, &helpers.APIResponse{
+		StatusCode: http.StatusOK,
+		Body:       `{"status": "ok", "cluster": "c1"}`,
+	})
+}
+
 func TestAssertReportResponsesEqual(t *testing.T) {
 	testAssertResponsesEqual(
 		t,
@@ -364,6 +441,9 @@ func TestToBytes(t *testing.T) {
 		assert.Equal(t, []byte(testStr), helpers.ToBytes(t, []byte(testStr)))
 		assert.Equal(t, []byte(testStr), helpers.ToBytes(t, testStr))
 		assert.Equal(t, []byte(testStr), helpers.ToBytes(t, strings.NewReader(testStr)))
+		assert.Equal(t, []byte(`["1"]`), helpers.ToBytes(t, []string{testStr}))
+		assert.JSONEq(t, `{"status": "ok", "report": ["1"]}`,
+			string(helpers.ToBytes(t, responses.NewEnvelope("report", []string{testStr}))))
 	})
 
 	t.Run("Error", func(t *testing.T) {
@@ -372,7 +452,7 @@ func TestToBytes(t *testing.T) {
 
 		mockT.Expects.EXPECT().Fatalf(gomock.Any(), gomock.Any())
 
-		_ = helpers.ToBytes(mockT, []string{})
+		_ = helpers.ToBytes(mockT, make(chan int))
 	})
 }
 
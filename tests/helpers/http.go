@@ -50,7 +50,8 @@ type BodyChecker func(t testing.TB, expected, got []byte)
 // (required) Method is an http method
 // (required) Endpoint is an endpoint without api prefix
 // EndpointArgs are the arguments to pass to endpoint template (leave empty if endpoint is not a template)
-// Body is a request body which can be a string or []byte (leave empty to not send)
+// Body is a request body which can be a string, []byte, io.Reader or any value serialized to JSON,
+// e.g. responses.Envelope (leave empty to not send)
 // UserID is a user id for methods requiring user id (leave empty to not use it)
 // OrgID is an org id for methods requiring it to be in token (leave empty to not use it)
 // XRHIdentity is an authentication token (leave empty to not use it)
@@ -70,7 +71,8 @@ type APIRequest struct {
 // APIResponse is an expected api response to use in AssertAPIRequest
 //
 // StatusCode is an expected http status code (leave empty to not check for status code)
-// Body is an expected body which can be a string or []byte(leave empty to not check for body)
+// Body is an expected body which can be a string, []byte or any value serialized to JSON,
+// e.g. responses.Envelope (leave empty to not check for body)
 // BodyChecker is a custom body checker function (leave empty to use default one - CheckResponseBodyJSON)
 type APIResponse struct {
 	StatusCode  int
@@ -101,6 +103,44 @@ func AssertAPIRequest(
 
 	response := ExecuteRequest(testServer, req).Result()
 
+	assertAPIResponse(t, response, expectedResponse)
+}
+
+// AssertAPIRequestMatchesSpec works like AssertAPIRequest, but it also
+// checks that the response matches the OpenAPI specification used by the
+// validator. The request is checked too, unless the expected status code
+// means client error, so tests of invalid requests keep working.
+func AssertAPIRequestMatchesSpec(
+	t testing.TB,
+	validator *httputils.OpenAPIValidator,
+	testServer ServerInitializer,
+	APIPrefix string,
+	request *APIRequest,
+	expectedResponse *APIResponse,
+) {
+	url := httputils.MakeURLToEndpoint(APIPrefix, request.Endpoint, request.EndpointArgs...)
+
+	req := makeRequest(t, request, url)
+
+	isClientError := expectedResponse.StatusCode >= http.StatusBadRequest &&
+		expectedResponse.StatusCode < http.StatusInternalServerError
+	if !isClientError {
+		assert.NoError(t, validator.ValidateRequest(req), "Request does not match OpenAPI specification")
+	}
+
+	response := ExecuteRequest(testServer, req).Result()
+
+	body, err := io.ReadAll(response.Body)
+	FailOnError(t, err)
+	response.Body = io.NopCloser(bytes.NewReader(body))
+
+	err = validator.ValidateResponse(req, response.StatusCode, response.Header, body)
+	assert.NoError(t, err, "Response does not match OpenAPI specification")
+
+	assertAPIResponse(t, response, expectedResponse)
+}
+
+func assertAPIResponse(t testing.TB, response *http.Response, expectedResponse *APIResponse) {
 	if len(expectedResponse.Headers) != 0 {
 		checkResponseHeaders(t, expectedResponse.Headers, response.Header)
 	}
@@ -128,13 +168,16 @@ func toBytes(t testing.TB, obj interface{}) []byte {
 		FailOnError(t, err)
 		return res
 	default:
-		t.Fatalf(
-			`type "%T" of API(Request|Response).Body is not supported, please see the documentation. Value is "%+v"`,
-			obj, obj,
-		)
+		// typed bodies are serialized the same way as by responses.Send
+		res, err := json.Marshal(v)
+		if err != nil {
+			t.Fatalf(
+				`API(Request|Response).Body of type "%T" can't be serialized to JSON: %v. Value is "%+v"`,
+				obj, err, obj,
+			)
+		}
+		return res
 	}
-
-	return nil
 }
 
 func assertBody(t testing.TB, expectedBody, body interface{}, bodyChecker BodyChecker) {
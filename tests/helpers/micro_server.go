@@ -19,15 +19,24 @@ package helpers
 
 import (
 	"net/http"
-	"time"
 
 	"github.com/gorilla/mux"
+
+	httputils "github.com/RedHatInsights/insights-operator-utils/http"
 )
 
 // MicroHTTPServer in an implementation of ServerInitializer interface
 // This small implementation could help implementing tests without using
-// a real HTTP server implementation
+// a real HTTP server implementation. It wraps httputils.Server, so the
+// standard routes are available. The standard middlewares are not used
+// unless requested by NewMicroHTTPServerWithMiddlewares.
+//
+// Paths {prefix}metrics and {prefix}health (httputils.MetricsEndpoint and
+// httputils.HealthEndpoint) are reserved for the standard routes. They are
+// registered first, so endpoints added with the same names are never
+// reached.
 type MicroHTTPServer struct {
+	Server    *httputils.Server
 	Serv      *http.Server
 	Router    *mux.Router
 	APIPrefix string
@@ -35,16 +44,22 @@ type MicroHTTPServer struct {
 
 // NewMicroHTTPServer creates a MicroHTTPServer for the given address and prefix
 func NewMicroHTTPServer(address, apiPrefix string) *MicroHTTPServer {
-	router := mux.NewRouter().StrictSlash(true)
-	server := &http.Server{
-		Addr:              address,
-		Handler:           router,
-		ReadHeaderTimeout: 3 * time.Second,
-	}
+	return NewMicroHTTPServerWithMiddlewares(address, apiPrefix)
+}
+
+// NewMicroHTTPServerWithMiddlewares creates a MicroHTTPServer using the given
+// middlewares, httputils.StandardMiddlewares() can be passed to test the
+// server as it's used by the services
+func NewMicroHTTPServerWithMiddlewares(address, apiPrefix string, middlewares ...mux.MiddlewareFunc) *MicroHTTPServer {
+	server := httputils.NewServerWithMiddlewares(httputils.ServerConfiguration{
+		Address:   address,
+		APIPrefix: apiPrefix,
+	}, middlewares...)
 	return &MicroHTTPServer{
+		Server:    server,
 		APIPrefix: apiPrefix,
-		Router:    router,
-		Serv:      server,
+		Router:    server.Router,
+		Serv:      server.HTTPServer(),
 	}
 }
 
@@ -52,13 +67,12 @@ func NewMicroHTTPServer(address, apiPrefix string) *MicroHTTPServer {
 
 // Initialize returns the Handler instance in order to be modified
 func (server *MicroHTTPServer) Initialize() http.Handler {
-	return server.Router
+	return server.Server.Handler()
 }
 
 // TODO: make it more flexible, at least an array of methods should be passed through arguments
 
 // AddEndpoint adds a handler function to the router in order to response to the given endpoint
 func (server *MicroHTTPServer) AddEndpoint(endpoint string, f func(http.ResponseWriter, *http.Request)) {
-	realEndpoint := server.APIPrefix + endpoint
-	server.Router.HandleFunc(realEndpoint, f).Methods(http.MethodGet)
+	server.Server.AddEndpoint(endpoint, f)
 }
@@ -0,0 +1,284 @@
+// Copyright 2026 Red Hat, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tlsutil
+
+// Documentation in literate-programming-style is available at:
+// https://redhatinsights.github.io/insights-operator-utils/packages/tls/certificate_source.html
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rs/zerolog/log"
+
+	"github.com/RedHatInsights/insights-operator-utils/metrics"
+)
+
+// Values of "type" label of the tls_certificate_expiry_timestamp_seconds metric
+const (
+	caCertificateType     = "ca"
+	clientCertificateType = "client"
+)
+
+// CertificateSource provides CA certificates and optionally a certificate with
+// private key loaded from files. The files are reloaded when their content
+// changes, so certificates rotated by cert-manager are used without
+// restarting the service.
+type CertificateSource struct {
+	caPath   string
+	certPath string
+	keyPath  string
+	current  atomic.Pointer[certificateBundle]
+}
+
+// certificateBundle contains all certificates loaded at once, so they are
+// always replaced together
+type certificateBundle struct {
+	roots       *x509.CertPool
+	certificate *tls.Certificate
+	checksum    [sha256.Size]byte
+}
+
+// NewCertificateSource creates a certificate source and loads the certificates.
+// The certPath and keyPath parameters are optional, but they have to be
+// provided together.
+func NewCertificateSource(caPath, certPath, keyPath string) (*CertificateSource, error) {
+	if caPath == "" {
+		return nil, fmt.Errorf("no CA path provided")
+	}
+	if (certPath == "") != (keyPath == "") {
+		return nil, fmt.Errorf("both certificate and key paths need to be provided")
+	}
+
+	source := &CertificateSource{
+		caPath:   caPath,
+		certPath: certPath,
+		keyPath:  keyPath,
+	}
+
+	if _, err := source.Reload(); err != nil {
+		return nil, err
+	}
+	return source, nil
+}
+
+// Reload reads the files again and replaces the certificates if the content
+// of any file has changed. It returns true if the certificates were replaced.
+// The previous certificates are kept when the new ones can't be loaded.
+func (source *CertificateSource) Reload() (bool, error) {
+	contents, checksum, err := source.readFiles()
+	if err != nil {
+		return false, err
+	}
+
+	previous := source.current.Load()
+	if previous != nil && previous.checksum == checksum {
+		return false, nil
+	}
+
+	bundle, err := source.parse(contents)
+	if err != nil {
+		return false, err
+	}
+	bundle.checksum = checksum
+	source.current.Store(bundle)
+
+	if previous != nil {
+		log.Info().
+			Str("ca", source.caPath).
+			Str("certificate", source.certPath).
+			Msg("TLS certificates have been rotated")
+	}
+	return true, nil
+}
+
+// Watch checks the files periodically and reloads them when they change. It
+// blocks until the context is canceled.
+func (source *CertificateSource) Watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := source.Reload(); err != nil {
+				log.Error().Err(err).Msg("unable to reload TLS certificates, using the previous ones")
+			}
+		}
+	}
+}
+
+// RootCAs returns the currently loaded CA certificates
+func (source *CertificateSource) RootCAs() *x509.CertPool {
+	return source.current.Load().roots
+}
+
+// Certificate returns the currently loaded certificate or nil if no
+// certificate is configured
+func (source *CertificateSource) Certificate() *tls.Certificate {
+	return source.current.Load().certificate
+}
+
+// ClientTLSConfig returns TLS configuration for clients that always uses the
+// currently loaded certificates. The standard verification is replaced by
+// VerifyConnection callback, because RootCAs can't be changed in
+// configuration already used by a client. Unlike VerifyPeerCertificate, the
+// callback has access to the server name, so host names are still verified.
+// The server name is taken from SNI, which can't contain IP address, so
+// servers have to be addressed by host name (or ServerName has to be set).
+func (source *CertificateSource) ClientTLSConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		// verification is done in VerifyConnection callback
+		InsecureSkipVerify: true, // #nosec G402
+		VerifyConnection:   source.verifyServer,
+		GetClientCertificate: func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			certificate := source.Certificate()
+			if certificate == nil {
+				// no certificate is sent to the server
+				return &tls.Certificate{}, nil
+			}
+			return certificate, nil
+		},
+	}
+}
+
+func (source *CertificateSource) verifyServer(state tls.ConnectionState) error {
+	if len(state.PeerCertificates) == 0 {
+		return errors.New("server didn't provide any certificate")
+	}
+	if state.ServerName == "" {
+		// empty name would disable verification of the host name
+		return errors.New("server name is not known, unable to verify server certificate")
+	}
+
+	options := x509.VerifyOptions{
+		Roots:         source.RootCAs(),
+		DNSName:       state.ServerName,
+		Intermediates: x509.NewCertPool(),
+	}
+	for _, intermediate := range state.PeerCertificates[1:] {
+		options.Intermediates.AddCert(intermediate)
+	}
+
+	_, err := state.PeerCertificates[0].Verify(options)
+	return err
+}
+
+func (source *CertificateSource) readFiles() ([][]byte, [sha256.Size]byte, error) {
+	paths := []string{source.caPath}
+	if source.certPath != "" {
+		paths = append(paths, source.certPath, source.keyPath)
+	}
+
+	hash := sha256.New()
+	contents := make([][]byte, 0, len(paths))
+	for _, path := range paths {
+		content, err := os.ReadFile(filepath.Clean(path))
+		if err != nil {
+			return nil, [sha256.Size]byte{}, err
+		}
+		hash.Write(content)
+		contents = append(contents, content)
+	}
+
+	var checksum [sha256.Size]byte
+	copy(checksum[:], hash.Sum(nil))
+	return contents, checksum, nil
+}
+
+func (source *CertificateSource) parse(contents [][]byte) (*certificateBundle, error) {
+	caCertificates, err := parseCertificates(contents[0])
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse CA certificates from %s: %w", source.caPath, err)
+	}
+
+	bundle := &certificateBundle{roots: x509.NewCertPool()}
+	for _, certificate := range caCertificates {
+		bundle.roots.AddCert(certificate)
+	}
+	observeExpiry(caCertificateType, source.caPath, caCertificates)
+
+	if source.certPath != "" {
+		certificate, err := tls.X509KeyPair(contents[1], contents[2])
+		if err != nil {
+			return nil, fmt.Errorf("unable to load key pair from %s: %w", source.certPath, err)
+		}
+		// leaf is not parsed when x509keypairleaf=0 is set in GODEBUG
+		if certificate.Leaf == nil {
+			certificate.Leaf, err = x509.ParseCertificate(certificate.Certificate[0])
+			if err != nil {
+				return nil, fmt.Errorf("unable to parse certificate from %s: %w", source.certPath, err)
+			}
+		}
+		bundle.certificate = &certificate
+		observeExpiry(clientCertificateType, source.certPath, []*x509.Certificate{certificate.Leaf})
+	}
+
+	return bundle, nil
+}
+
+// parseCertificates parses all certificates from PEM encoded content
+func parseCertificates(content []byte) ([]*x509.Certificate, error) {
+	var certificates []*x509.Certificate
+	for {
+		var block *pem.Block
+		block, content = pem.Decode(content)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		certificate, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		certificates = append(certificates, certificate)
+	}
+
+	if len(certificates) == 0 {
+		return nil, errors.New("no certificate found")
+	}
+	return certificates, nil
+}
+
+// observeExpiry sets the metric to the earliest expiration time of the
+// given certificates
+func observeExpiry(certificateType, path string, certificates []*x509.Certificate) {
+	expiry := certificates[0].NotAfter
+	for _, certificate := range certificates[1:] {
+		if certificate.NotAfter.Before(expiry) {
+			expiry = certificate.NotAfter
+		}
+	}
+
+	metrics.TLSCertificateExpiry.With(prometheus.Labels{
+		"type": certificateType,
+		"path": path,
+	}).Set(float64(expiry.Unix()))
+}
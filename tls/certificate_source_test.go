@@ -0,0 +1,231 @@
+// Copyright 2026 Red Hat, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tlsutil_test
+
+// Documentation in literate-programming-style is available at:
+// https://redhatinsights.github.io/insights-operator-utils/packages/tls/certificate_source_test.html
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/RedHatInsights/insights-operator-utils/metrics"
+	"github.com/RedHatInsights/insights-operator-utils/tests/helpers"
+	tlsutil "github.com/RedHatInsights/insights-operator-utils/tls"
+)
+
+const validity = 24 * time.Hour
+
+// startTLSServer starts HTTPS server with certificate issued by the given CA.
+// When clientCA is not nil, client certificates are required.
+func startTLSServer(
+	t *testing.T, ca *helpers.TestCertificateAuthority, clientCA *x509.CertPool,
+) *httptest.Server {
+	issued := ca.Issue(t, "server", validity)
+	certificate, err := tls.X509KeyPair(issued.CertificatePEM, issued.KeyPEM)
+	helpers.FailOnError(t, err)
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if len(request.TLS.PeerCertificates) > 0 {
+			_, _ = writer.Write([]byte(request.TLS.PeerCertificates[0].Subject.CommonName))
+		}
+	}))
+	server.TLS = &tls.Config{
+		Certificates: []tls.Certificate{certificate},
+		MinVersion:   tls.VersionTLS12,
+	}
+	if clientCA != nil {
+		server.TLS.ClientCAs = clientCA
+		server.TLS.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	server.StartTLS()
+	t.Cleanup(server.Close)
+	// server name is verified, so it can't be an IP address
+	server.URL = strings.Replace(server.URL, "127.0.0.1", "localhost", 1)
+	return server
+}
+
+func get(source *tlsutil.CertificateSource, url string) (*http.Response, error) {
+	client := http.Client{
+		Transport: &http.Transport{TLSClientConfig: source.ClientTLSConfig()},
+		Timeout:   5 * time.Second,
+	}
+	return client.Get(url)
+}
+
+func TestNewCertificateSourceErrors(t *testing.T) {
+	dir := t.TempDir()
+	ca := helpers.NewTestCertificateAuthority(t, "ca", validity)
+	caPath := helpers.WriteTestFile(t, dir, "ca.pem", ca.CertificatePEM)
+	notPEMPath := helpers.WriteTestFile(t, dir, "invalid.pem", []byte("not a certificate"))
+
+	testCases := []struct {
+		name, caPath, certPath, keyPath string
+	}{
+		{"no CA path", "", "", ""},
+		{"missing CA file", "missing.pem", "", ""},
+		{"invalid CA file", notPEMPath, "", ""},
+		{"missing key path", caPath, caPath, ""},
+		{"invalid key pair", caPath, caPath, notPEMPath},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			source, err := tlsutil.NewCertificateSource(tc.caPath, tc.certPath, tc.keyPath)
+			assert.Error(t, err)
+			assert.Nil(t, source)
+		})
+	}
+}
+
+func TestCertificateSourceExpiryMetric(t *testing.T) {
+	dir := t.TempDir()
+	ca := helpers.NewTestCertificateAuthority(t, "ca", validity)
+	client := ca.Issue(t, "client", time.Hour)
+	caPath := helpers.WriteTestFile(t, dir, "ca.pem", ca.CertificatePEM)
+	certPath := helpers.WriteTestFile(t, dir, "tls.crt", client.CertificatePEM)
+	keyPath := helpers.WriteTestFile(t, dir, "tls.key", client.KeyPEM)
+
+	source, err := tlsutil.NewCertificateSource(caPath, certPath, keyPath)
+	helpers.FailOnError(t, err)
+	assert.NotNil(t, source.Certificate())
+
+	assert.Equal(t,
+		float64(ca.Certificate.NotAfter.Unix()),
+		testutil.ToFloat64(metrics.TLSCertificateExpiry.WithLabelValues("ca", caPath)))
+	assert.Equal(t,
+		float64(client.Certificate.NotAfter.Unix()),
+		testutil.ToFloat64(metrics.TLSCertificateExpiry.WithLabelValues("client", certPath)))
+}
+
+func TestCertificateSourceRequiresServerName(t *testing.T) {
+	dir := t.TempDir()
+	ca := helpers.NewTestCertificateAuthority(t, "ca", validity)
+	caPath := helpers.WriteTestFile(t, dir, "ca.pem", ca.CertificatePEM)
+
+	source, err := tlsutil.NewCertificateSource(caPath, "", "")
+	helpers.FailOnError(t, err)
+
+	server := startTLSServer(t, ca, nil)
+
+	// IP addresses are not sent in SNI, so the server name is not known
+	_, err = get(source, strings.Replace(server.URL, "localhost", "127.0.0.1", 1))
+	assert.ErrorContains(t, err, "server name is not known")
+
+	response, err := get(source, server.URL)
+	helpers.FailOnError(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	helpers.FailOnError(t, response.Body.Close())
+}
+
+func TestCertificateSourceRotation(t *testing.T) {
+	dir := t.TempDir()
+	oldCA := helpers.NewTestCertificateAuthority(t, "old-ca", validity)
+	newCA := helpers.NewTestCertificateAuthority(t, "new-ca", validity)
+	caPath := helpers.WriteTestFile(t, dir, "ca.pem", oldCA.CertificatePEM)
+
+	source, err := tlsutil.NewCertificateSource(caPath, "", "")
+	helpers.FailOnError(t, err)
+	assert.Nil(t, source.Certificate())
+
+	server := startTLSServer(t, newCA, nil)
+
+	// server certificate is not trusted yet
+	_, err = get(source, server.URL)
+	assert.Error(t, err)
+
+	reloaded, err := source.Reload()
+	helpers.FailOnError(t, err)
+	assert.False(t, reloaded, "nothing has changed")
+
+	helpers.WriteTestFile(t, dir, "ca.pem", newCA.CertificatePEM)
+	reloaded, err = source.Reload()
+	helpers.FailOnError(t, err)
+	assert.True(t, reloaded)
+
+	response, err := get(source, server.URL)
+	helpers.FailOnError(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	helpers.FailOnError(t, response.Body.Close())
+}
+
+func TestCertificateSourceKeepsCertificatesOnError(t *testing.T) {
+	dir := t.TempDir()
+	ca := helpers.NewTestCertificateAuthority(t, "ca", validity)
+	caPath := helpers.WriteTestFile(t, dir, "ca.pem", ca.CertificatePEM)
+
+	source, err := tlsutil.NewCertificateSource(caPath, "", "")
+	helpers.FailOnError(t, err)
+	roots := source.RootCAs()
+
+	helpers.WriteTestFile(t, dir, "ca.pem", []byte("broken"))
+	reloaded, err := source.Reload()
+	assert.Error(t, err)
+	assert.False(t, reloaded)
+	assert.Same(t, roots, source.RootCAs())
+}
+
+func TestCertificateSourceClientCertificate(t *testing.T) {
+	dir := t.TempDir()
+	ca := helpers.NewTestCertificateAuthority(t, "ca", validity)
+	clientCA := helpers.NewTestCertificateAuthority(t, "client-ca", validity)
+	oldClient := clientCA.Issue(t, "old-client", validity)
+	newClient := clientCA.Issue(t, "new-client", validity)
+
+	caPath := helpers.WriteTestFile(t, dir, "ca.pem", ca.CertificatePEM)
+	certPath := helpers.WriteTestFile(t, dir, "tls.crt", oldClient.CertificatePEM)
+	keyPath := helpers.WriteTestFile(t, dir, "tls.key", oldClient.KeyPEM)
+
+	source, err := tlsutil.NewCertificateSource(caPath, certPath, keyPath)
+	helpers.FailOnError(t, err)
+
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(clientCA.Certificate)
+	server := startTLSServer(t, ca, clientCAs)
+
+	assertClientName := func(expected string) {
+		response, err := get(source, server.URL)
+		helpers.FailOnError(t, err)
+		defer func() { helpers.FailOnError(t, response.Body.Close()) }()
+		body, err := io.ReadAll(response.Body)
+		helpers.FailOnError(t, err)
+		assert.Equal(t, expected, string(body))
+	}
+
+	assertClientName("old-client")
+
+	helpers.WriteTestFile(t, dir, "tls.crt", newClient.CertificatePEM)
+	helpers.WriteTestFile(t, dir, "tls.key", newClient.KeyPEM)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go source.Watch(ctx, 10*time.Millisecond)
+
+	assert.Eventually(t, func() bool {
+		return source.Certificate().Leaf.Subject.CommonName == "new-client"
+	}, 5*time.Second, 10*time.Millisecond)
+
+	assertClientName("new-client")
+}
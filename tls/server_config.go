@@ -0,0 +1,158 @@
+// Copyright 2026 Red Hat, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tlsutil
+
+// Documentation in literate-programming-style is available at:
+// https://redhatinsights.github.io/insights-operator-utils/packages/tls/server_config.html
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Supported values of ServerConfiguration.ClientAuth
+const (
+	ClientAuthNone             = "none"
+	ClientAuthVerifyIfGiven    = "verify_if_given"
+	ClientAuthRequireAndVerify = "require_and_verify"
+)
+
+// Supported values of ServerConfiguration.MinVersion
+const (
+	TLSVersion12 = "1.2"
+	TLSVersion13 = "1.3"
+)
+
+// ServerConfiguration represents TLS configuration of HTTP servers
+type ServerConfiguration struct {
+	CertPath string `mapstructure:"cert_path" toml:"cert_path"`
+	KeyPath  string `mapstructure:"key_path" toml:"key_path"`
+	// ClientCAPath points to CA certificates used to verify client
+	// certificates, it is required when client certificates are verified
+	ClientCAPath string `mapstructure:"client_ca_path" toml:"client_ca_path"`
+	// ClientAuth is one of "none" (default), "verify_if_given" and
+	// "require_and_verify"
+	ClientAuth string `mapstructure:"client_auth" toml:"client_auth"`
+	// MinVersion is either "1.2" (default) or "1.3"
+	MinVersion string `mapstructure:"min_version" toml:"min_version"`
+	// CipherSuites contains names of allowed cipher suites, as defined by
+	// IANA (e.g. "TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256"). Cipher suites
+	// considered insecure are refused. The setting is ignored by TLS 1.3.
+	CipherSuites []string `mapstructure:"cipher_suites" toml:"cipher_suites"`
+}
+
+// NewServerTLSConfig creates TLS configuration for HTTP servers from the given
+// configuration
+func NewServerTLSConfig(configuration *ServerConfiguration) (*tls.Config, error) {
+	if configuration.CertPath == "" || configuration.KeyPath == "" {
+		return nil, fmt.Errorf("both certificate and key paths need to be provided")
+	}
+
+	certificate, err := tls.LoadX509KeyPair(configuration.CertPath, configuration.KeyPath)
+	if err != nil {
+		return nil, err
+	}
+
+	minVersion, err := parseTLSVersion(configuration.MinVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	cipherSuites, err := parseCipherSuites(configuration.CipherSuites)
+	if err != nil {
+		return nil, err
+	}
+
+	clientAuth, err := parseClientAuth(configuration.ClientAuth)
+	if err != nil {
+		return nil, err
+	}
+
+	tlsConfig := &tls.Config{
+		Certificates: []tls.Certificate{certificate},
+		MinVersion:   minVersion,
+		CipherSuites: cipherSuites,
+		ClientAuth:   clientAuth,
+	}
+
+	if clientAuth == tls.NoClientCert {
+		return tlsConfig, nil
+	}
+
+	if configuration.ClientCAPath == "" {
+		return nil, fmt.Errorf("client CA path needs to be provided to verify client certificates")
+	}
+	caCerts, err := os.ReadFile(filepath.Clean(configuration.ClientCAPath))
+	if err != nil {
+		return nil, err
+	}
+	tlsConfig.ClientCAs = x509.NewCertPool()
+	if !tlsConfig.ClientCAs.AppendCertsFromPEM(caCerts) {
+		return nil, fmt.Errorf("error appending the client CA certificates")
+	}
+
+	return tlsConfig, nil
+}
+
+func parseTLSVersion(version string) (uint16, error) {
+	switch version {
+	case "", TLSVersion12:
+		return tls.VersionTLS12, nil
+	case TLSVersion13:
+		return tls.VersionTLS13, nil
+	default:
+		return 0, fmt.Errorf("unsupported minimal TLS version '%s'", version)
+	}
+}
+
+func parseClientAuth(clientAuth string) (tls.ClientAuthType, error) {
+	switch strings.ToLower(clientAuth) {
+	case "", ClientAuthNone:
+		return tls.NoClientCert, nil
+	case ClientAuthVerifyIfGiven:
+		return tls.VerifyClientCertIfGiven, nil
+	case ClientAuthRequireAndVerify:
+		return tls.RequireAndVerifyClientCert, nil
+	default:
+		return tls.NoClientCert, fmt.Errorf("unsupported client authentication '%s'", clientAuth)
+	}
+}
+
+// parseCipherSuites converts names of cipher suites to their IDs. Nil is
+// returned for an empty list, so the Go defaults are used.
+func parseCipherSuites(names []string) ([]uint16, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	secure := make(map[string]uint16)
+	for _, suite := range tls.CipherSuites() {
+		secure[suite.Name] = suite.ID
+	}
+
+	ids := make([]uint16, 0, len(names))
+	for _, name := range names {
+		id, found := secure[name]
+		if !found {
+			return nil, fmt.Errorf("unknown or insecure cipher suite '%s'", name)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
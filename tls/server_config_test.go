@@ -0,0 +1,178 @@
+// Copyright 2026 Red Hat, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tlsutil_test
+
+// Documentation in literate-programming-style is available at:
+// https://redhatinsights.github.io/insights-operator-utils/packages/tls/server_config_test.html
+
+import (
+	"crypto/tls"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/RedHatInsights/insights-operator-utils/tests/helpers"
+	tlsutil "github.com/RedHatInsights/insights-operator-utils/tls"
+)
+
+type serverFiles struct {
+	caPath   string
+	certPath string
+	keyPath  string
+	ca       *helpers.TestCertificateAuthority
+}
+
+func writeServerFiles(t *testing.T) serverFiles {
+	dir := t.TempDir()
+	ca := helpers.NewTestCertificateAuthority(t, "ca", validity)
+	issued := ca.Issue(t, "server", validity)
+	return serverFiles{
+		caPath:   helpers.WriteTestFile(t, dir, "ca.pem", ca.CertificatePEM),
+		certPath: helpers.WriteTestFile(t, dir, "server.pem", issued.CertificatePEM),
+		keyPath:  helpers.WriteTestFile(t, dir, "server-key.pem", issued.KeyPEM),
+		ca:       ca,
+	}
+}
+
+func TestNewServerTLSConfigDefaults(t *testing.T) {
+	files := writeServerFiles(t)
+
+	tlsConfig, err := tlsutil.NewServerTLSConfig(&tlsutil.ServerConfiguration{
+		CertPath: files.certPath,
+		KeyPath:  files.keyPath,
+	})
+	helpers.FailOnError(t, err)
+
+	assert.Len(t, tlsConfig.Certificates, 1)
+	assert.Equal(t, uint16(tls.VersionTLS12), tlsConfig.MinVersion)
+	assert.Equal(t, tls.NoClientCert, tlsConfig.ClientAuth)
+	assert.Nil(t, tlsConfig.CipherSuites)
+	assert.Nil(t, tlsConfig.ClientCAs)
+}
+
+func TestNewServerTLSConfigPolicy(t *testing.T) {
+	files := writeServerFiles(t)
+
+	tlsConfig, err := tlsutil.NewServerTLSConfig(&tlsutil.ServerConfiguration{
+		CertPath:     files.certPath,
+		KeyPath:      files.keyPath,
+		ClientCAPath: files.caPath,
+		ClientAuth:   tlsutil.ClientAuthVerifyIfGiven,
+		MinVersion:   tlsutil.TLSVersion13,
+		CipherSuites: []string{"TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256"},
+	})
+	helpers.FailOnError(t, err)
+
+	assert.Equal(t, uint16(tls.VersionTLS13), tlsConfig.MinVersion)
+	assert.Equal(t, tls.VerifyClientCertIfGiven, tlsConfig.ClientAuth)
+	assert.Equal(t, []uint16{tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256}, tlsConfig.CipherSuites)
+	assert.NotNil(t, tlsConfig.ClientCAs)
+}
+
+func TestNewServerTLSConfigErrors(t *testing.T) {
+	files := writeServerFiles(t)
+	notPEMPath := helpers.WriteTestFile(t, t.TempDir(), "invalid.pem", []byte("not a certificate"))
+
+	testCases := []struct {
+		name          string
+		configuration tlsutil.ServerConfiguration
+	}{
+		{"missing key", tlsutil.ServerConfiguration{CertPath: files.certPath}},
+		{"invalid key pair", tlsutil.ServerConfiguration{CertPath: files.certPath, KeyPath: notPEMPath}},
+		{
+			"unsupported TLS version",
+			tlsutil.ServerConfiguration{CertPath: files.certPath, KeyPath: files.keyPath, MinVersion: "1.1"},
+		},
+		{
+			"insecure cipher suite",
+			tlsutil.ServerConfiguration{
+				CertPath: files.certPath, KeyPath: files.keyPath,
+				CipherSuites: []string{"TLS_RSA_WITH_RC4_128_SHA"},
+			},
+		},
+		{
+			"unsupported client authentication",
+			tlsutil.ServerConfiguration{CertPath: files.certPath, KeyPath: files.keyPath, ClientAuth: "request"},
+		},
+		{
+			"missing client CA",
+			tlsutil.ServerConfiguration{
+				CertPath: files.certPath, KeyPath: files.keyPath,
+				ClientAuth: tlsutil.ClientAuthRequireAndVerify,
+			},
+		},
+		{
+			"invalid client CA",
+			tlsutil.ServerConfiguration{
+				CertPath: files.certPath, KeyPath: files.keyPath,
+				ClientAuth: tlsutil.ClientAuthRequireAndVerify, ClientCAPath: notPEMPath,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := tlsutil.NewServerTLSConfig(&tc.configuration)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestNewServerTLSConfigRequiresClientCertificate(t *testing.T) {
+	files := writeServerFiles(t)
+
+	tlsConfig, err := tlsutil.NewServerTLSConfig(&tlsutil.ServerConfiguration{
+		CertPath:     files.certPath,
+		KeyPath:      files.keyPath,
+		ClientCAPath: files.caPath,
+		ClientAuth:   tlsutil.ClientAuthRequireAndVerify,
+	})
+	helpers.FailOnError(t, err)
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		_, _ = writer.Write([]byte(request.TLS.VerifiedChains[0][0].Subject.CommonName))
+	}))
+	server.TLS = tlsConfig
+	server.StartTLS()
+	defer server.Close()
+	// server name is verified, so it can't be an IP address
+	server.URL = strings.Replace(server.URL, "127.0.0.1", "localhost", 1)
+
+	source, err := tlsutil.NewCertificateSource(files.caPath, "", "")
+	helpers.FailOnError(t, err)
+	_, err = get(source, server.URL)
+	assert.Error(t, err, "connection without client certificate should be refused")
+
+	client := files.ca.Issue(t, "client", validity)
+	dir := t.TempDir()
+	source, err = tlsutil.NewCertificateSource(
+		files.caPath,
+		helpers.WriteTestFile(t, dir, "client.pem", client.CertificatePEM),
+		helpers.WriteTestFile(t, dir, "client-key.pem", client.KeyPEM),
+	)
+	helpers.FailOnError(t, err)
+
+	response, err := get(source, server.URL)
+	helpers.FailOnError(t, err)
+	defer response.Body.Close()
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	body, err := io.ReadAll(response.Body)
+	helpers.FailOnError(t, err)
+	assert.Equal(t, "client", string(body))
+}
@@ -22,6 +22,8 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/rs/zerolog/log"
 
@@ -92,6 +94,49 @@ func (*NoBodyError) Error() string {
 	return "client didn't provide request body"
 }
 
+// InvalidJSONError means that request body is not valid JSON or it does not
+// match the expected structure. Offset is position in the body where the
+// problem was found and Field is the name of the problematic field, both are
+// optional.
+type InvalidJSONError struct {
+	Offset    int64
+	Field     string
+	ErrString string
+}
+
+func (e *InvalidJSONError) Error() string {
+	switch {
+	case e.Field != "":
+		return fmt.Sprintf("invalid JSON in request body, field '%s': %s", e.Field, e.ErrString)
+	case e.Offset > 0:
+		return fmt.Sprintf("invalid JSON in request body at offset %d: %s", e.Offset, e.ErrString)
+	default:
+		return fmt.Sprintf("invalid JSON in request body: %s", e.ErrString)
+	}
+}
+
+// PayloadTooLargeError means that request body is larger than Limit bytes
+type PayloadTooLargeError struct {
+	Limit int64
+}
+
+func (e *PayloadTooLargeError) Error() string {
+	return fmt.Sprintf("request body is larger than %d bytes", e.Limit)
+}
+
+// UnsupportedMediaTypeError means that Content-Type of request body is not
+// supported by the endpoint
+type UnsupportedMediaTypeError struct {
+	ContentType string
+	Supported   []string
+}
+
+func (e *UnsupportedMediaTypeError) Error() string {
+	return fmt.Sprintf(
+		"unsupported Content-Type '%s', expected %s", e.ContentType, strings.Join(e.Supported, " or "),
+	)
+}
+
 // ValidationError validation error, for example when string is longer then expected
 type ValidationError struct {
 	ParamName  string
@@ -106,6 +151,47 @@ func (e *ValidationError) Error() string {
 	)
 }
 
+// ValidationErrors aggregates multiple validation errors, for example all
+// invalid parameters of a request
+type ValidationErrors struct {
+	Errors []*ValidationError
+}
+
+func (e *ValidationErrors) Error() string {
+	messages := make([]string, 0, len(e.Errors))
+	for _, err := range e.Errors {
+		messages = append(messages, err.Error())
+	}
+	return strings.Join(messages, "; ")
+}
+
+// CircuitOpenError means a dependency of the service is considered
+// unavailable, because the circuit breaker protecting calls to it is open
+type CircuitOpenError struct {
+	Target string
+	// RetryAfter is the time remaining until the breaker lets requests
+	// through again
+	RetryAfter time.Duration
+}
+
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("circuit breaker for %s is open", e.Target)
+}
+
+// TooManyRequestsError means the client exceeded its rate limit
+type TooManyRequestsError struct {
+	// Key identifies the limited client, e.g. organization ID or IP
+	// address
+	Key string
+	// RetryAfter is the time remaining until the client can send requests
+	// again
+	RetryAfter time.Duration
+}
+
+func (e *TooManyRequestsError) Error() string {
+	return fmt.Sprintf("rate limit exceeded for %s", e.Key)
+}
+
 // OutOfRangeError indicates that a value is outside the expected range.
 type OutOfRangeError struct {
 	Value uint64
@@ -117,26 +203,34 @@ func (e *OutOfRangeError) Error() string {
 	return fmt.Sprintf("value %d out of range for %s", e.Value, e.Type)
 }
 
-// HandleServerError handles separate server errors and sends appropriate responses
+// HandleServerError handles separate server errors and sends appropriate
+// responses. Errors registered by RegisterErrorMapping are handled first.
+// The status code is the one reported by NewProblemDetails, so both kinds
+// of responses stay consistent.
 func HandleServerError(writer http.ResponseWriter, err error) {
-	var level = log.Warn() // set the default log level for most HTTP responses
+	problem := NewProblemDetails(err)
 
-	var respErr error
+	var level = log.Warn() // set the default log level for most HTTP responses
 
-	switch err := err.(type) {
-	case *RouterMissingParamError, *RouterParsingError, *json.SyntaxError, *NoBodyError, *ValidationError, *OutOfRangeError:
-		respErr = responses.SendBadRequest(writer, err.Error())
-	case *json.UnmarshalTypeError:
-		respErr = responses.SendBadRequest(writer, "bad type in json data")
-	case *ItemNotFoundError:
-		respErr = responses.SendNotFound(writer, err.Error())
-	case *UnauthorizedError:
-		respErr = responses.SendUnauthorized(writer, err.Error())
-	case *ForbiddenError:
-		respErr = responses.SendForbidden(writer, err.Error())
-	default:
+	var message string
+	switch {
+	case problem.Status >= http.StatusInternalServerError && problem.Detail == "":
+		// details of server errors are only logged
 		level = log.Error()
-		respErr = responses.SendInternalServerError(writer, "Internal Server Error")
+		message = http.StatusText(problem.Status)
+	case isUnmarshalTypeError(err):
+		message = problem.Detail
+	default:
+		message = err.Error()
+	}
+
+	setRetryAfter(writer, err)
+
+	var respErr error
+	if problem.Status == http.StatusNoContent {
+		respErr = responses.SendNoContent(writer)
+	} else {
+		respErr = responses.Send(problem.Status, writer, message)
 	}
 
 	level.Err(err).Msg(handleServerErrorStr)
@@ -146,6 +240,22 @@ func HandleServerError(writer http.ResponseWriter, err error) {
 	}
 }
 
+func isUnmarshalTypeError(err error) bool {
+	_, ok := err.(*json.UnmarshalTypeError)
+	return ok
+}
+
+// setRetryAfter tells the client when to retry requests rejected by rate
+// limiter or circuit breaker
+func setRetryAfter(writer http.ResponseWriter, err error) {
+	switch err := err.(type) {
+	case *TooManyRequestsError:
+		responses.SetRetryAfter(writer, err.RetryAfter)
+	case *CircuitOpenError:
+		responses.SetRetryAfter(writer, err.RetryAfter)
+	}
+}
+
 // ErrOldReport is an error returned if a more recent already
 // exists on the storage while attempting to write a report for a cluster.
 var ErrOldReport = errors.New("More recent report already exists in storage")
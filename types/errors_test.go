@@ -135,6 +135,45 @@ func TestValidationError(t *testing.T) {
 	assert.Equal(t, err.Error(), expected)
 }
 
+// TestInvalidJSONError checks the method Error() for data structure
+// InvalidJSONError.
+func TestInvalidJSONError(t *testing.T) {
+	assert.Equal(t, "invalid JSON in request body: body is truncated",
+		(&types.InvalidJSONError{ErrString: "body is truncated"}).Error())
+	assert.Equal(t, "invalid JSON in request body at offset 5: unexpected '}'",
+		(&types.InvalidJSONError{Offset: 5, ErrString: "unexpected '}'"}).Error())
+	assert.Equal(t, "invalid JSON in request body, field 'count': wrong type",
+		(&types.InvalidJSONError{Offset: 5, Field: "count", ErrString: "wrong type"}).Error())
+}
+
+// TestPayloadTooLargeError checks the method Error() for data structure
+// PayloadTooLargeError.
+func TestPayloadTooLargeError(t *testing.T) {
+	assert.Equal(t, "request body is larger than 1024 bytes", (&types.PayloadTooLargeError{Limit: 1024}).Error())
+}
+
+// TestUnsupportedMediaTypeError checks the method Error() for data
+// structure UnsupportedMediaTypeError.
+func TestUnsupportedMediaTypeError(t *testing.T) {
+	err := types.UnsupportedMediaTypeError{
+		ContentType: "text/plain",
+		Supported:   []string{"application/json", "application/xml"},
+	}
+	assert.Equal(t, "unsupported Content-Type 'text/plain', expected application/json or application/xml", err.Error())
+}
+
+// TestValidationErrors checks the method Error() for data structure
+// ValidationErrors.
+func TestValidationErrors(t *testing.T) {
+	err := types.ValidationErrors{Errors: []*types.ValidationError{
+		{ParamName: "limit", ParamValue: "0", ErrString: "too small"},
+		{ParamName: "sort", ParamValue: "up", ErrString: "unknown order"},
+	}}
+
+	assert.Equal(t, "Error during validating param 'limit' with value '0'. Error: 'too small'; "+
+		"Error during validating param 'sort' with value 'up'. Error: 'unknown order'", err.Error())
+}
+
 // TestItemNotFoundError checks the method Error() for data structure
 // ItemNotFoundError.
 func TestItemNotFoundError(t *testing.T) {
@@ -149,15 +188,49 @@ func TestItemNotFoundError(t *testing.T) {
 	assert.Equal(t, err.Error(), expected)
 }
 
+// TestCircuitOpenError checks the method Error() for data structure
+// CircuitOpenError
+func TestCircuitOpenError(t *testing.T) {
+	// expected error value
+	const expected = "circuit breaker for aggregator:8080 is open"
+
+	// construct an instance of error interface
+	err := types.CircuitOpenError{
+		Target: "aggregator:8080"}
+
+	// check if error value is correct
+	assert.Equal(t, err.Error(), expected)
+}
+
+// TestTooManyRequestsError checks the method Error() for data structure
+// TooManyRequestsError
+func TestTooManyRequestsError(t *testing.T) {
+	// expected error value
+	const expected = "rate limit exceeded for org:42"
+
+	// construct an instance of error interface
+	err := types.TooManyRequestsError{
+		Key: "org:42"}
+
+	// check if error value is correct
+	assert.Equal(t, err.Error(), expected)
+}
+
 // TestHandleServer error check the function HandleServerError defined in errors.go
 func TestHandleServerError(t *testing.T) {
 	// check the behaviour with all error types defined in this package
 	testResponse(t, &types.RouterMissingParamError{}, http.StatusBadRequest)
 	testResponse(t, &types.RouterParsingError{}, http.StatusBadRequest)
+	testResponse(t, &types.NoContentError{}, http.StatusNoContent)
 	testResponse(t, &types.ItemNotFoundError{}, http.StatusNotFound)
 	testResponse(t, &types.UnauthorizedError{}, http.StatusUnauthorized)
 	testResponse(t, &types.ForbiddenError{}, http.StatusForbidden)
 	testResponse(t, &types.ForbiddenError{}, http.StatusForbidden)
+	testResponse(t, &types.CircuitOpenError{}, http.StatusServiceUnavailable)
+	testResponse(t, &types.TooManyRequestsError{}, http.StatusTooManyRequests)
+	testResponse(t, &types.InvalidJSONError{}, http.StatusBadRequest)
+	testResponse(t, &types.PayloadTooLargeError{}, http.StatusRequestEntityTooLarge)
+	testResponse(t, &types.UnsupportedMediaTypeError{}, http.StatusUnsupportedMediaType)
 
 	// we need to retriev json.UnmarshalTypeError
 	// so let's try to unmarshal "foo" string into an integer
@@ -1,10 +1,10 @@
-// Copyright 2024 Red Hat, Inc
+// Copyright 2026 Red Hat, Inc
 //
 // Licensed under the Apache License, Version 2.0 (the "License");
 // you may not use this file except in compliance with the License.
 // You may obtain a copy of the License at
 //
-//     http://www.apache.org/licenses/LICENSE-2.0
+//      http://www.apache.org/licenses/LICENSE-2.0
 //
 // Unless required by applicable law or agreed to in writing, software
 // distributed under the License is distributed on an "AS IS" BASIS,
@@ -12,9 +12,13 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-package clowder
+package types
 
-var (
-	NoBrokerCfg = noBrokerConfig
-	NoSaslCfg   = noSaslConfig
-)
+// Documentation in literate-programming-style is available at:
+// https://redhatinsights.github.io/insights-operator-utils/packages/types/feature_flags.html
+
+// FeatureFlagsConfiguration holds configuration of the feature flags service
+type FeatureFlagsConfiguration struct {
+	URL   string `mapstructure:"url" toml:"url"`
+	Token string `mapstructure:"token" toml:"token"`
+}
@@ -0,0 +1,249 @@
+// Copyright 2026 Red Hat, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+// Documentation in literate-programming-style is available at:
+// https://redhatinsights.github.io/insights-operator-utils/packages/types/problem.html
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/RedHatInsights/insights-operator-utils/responses"
+)
+
+// RequestIDHeader is the header carrying the ID used to correlate responses
+// with logs
+const RequestIDHeader = "X-Request-Id"
+
+// Machine-readable types of problems reported for the errors defined in this
+// package
+const (
+	ProblemTypeMissingParam  = "missing-parameter"
+	ProblemTypeInvalidParam  = "invalid-parameter"
+	ProblemTypeValidation    = "validation-error"
+	ProblemTypeInvalidJSON   = "invalid-json"
+	ProblemTypeMissingBody   = "missing-body"
+	ProblemTypeBodyTooLarge  = "payload-too-large"
+	ProblemTypeMediaType     = "unsupported-media-type"
+	ProblemTypeOutOfRange    = "out-of-range"
+	ProblemTypeNotFound      = "not-found"
+	ProblemTypeUnauthorized  = "unauthorized"
+	ProblemTypeForbidden     = "forbidden"
+	ProblemTypeRateLimited   = "rate-limited"
+	ProblemTypeUnavailable   = "service-unavailable"
+	ProblemTypeInternalError = "internal-error"
+)
+
+// ProblemDetails represents error response as defined by RFC 7807
+type ProblemDetails struct {
+	Type          string      `json:"type"`
+	Title         string      `json:"title"`
+	Status        int         `json:"status"`
+	Detail        string      `json:"detail,omitempty"`
+	Instance      string      `json:"instance,omitempty"`
+	ParamName     string      `json:"param_name,omitempty"`
+	ParamValue    interface{} `json:"param_value,omitempty"`
+	CorrelationID string      `json:"correlation_id,omitempty"`
+	// InvalidParams lists all invalid parameters when more of them are
+	// reported at once
+	InvalidParams []InvalidParam `json:"invalid_params,omitempty"`
+}
+
+// InvalidParam describes one invalid parameter in ProblemDetails
+type InvalidParam struct {
+	Name   string      `json:"name"`
+	Value  interface{} `json:"value,omitempty"`
+	Reason string      `json:"reason"`
+}
+
+// ErrorMapping describes how an error is reported to the client
+type ErrorMapping struct {
+	StatusCode int
+	// Type identifies the problem, "about:blank" is used when empty
+	Type string
+	// Title is a short summary of the problem, the status text is used
+	// when empty
+	Title string
+}
+
+type registeredError struct {
+	matches func(err error) bool
+	mapping ErrorMapping
+}
+
+var (
+	errorRegistry      []registeredError
+	errorRegistryMutex sync.RWMutex
+)
+
+// RegisterErrorMapping registers mapping of errors of type T to responses
+// sent by HandleServerError and HandleServerProblem. Wrapped errors are
+// matched too. Mappings registered later take precedence and they also
+// override handling of the errors defined in this package. Messages of
+// errors mapped to 5xx status codes are not disclosed to the client.
+func RegisterErrorMapping[T error](mapping ErrorMapping) {
+	errorRegistryMutex.Lock()
+	defer errorRegistryMutex.Unlock()
+
+	errorRegistry = append(errorRegistry, registeredError{
+		matches: func(err error) bool {
+			var target T
+			return errors.As(err, &target)
+		},
+		mapping: mapping,
+	})
+}
+
+func lookupErrorMapping(err error) (ErrorMapping, bool) {
+	errorRegistryMutex.RLock()
+	defer errorRegistryMutex.RUnlock()
+
+	for i := len(errorRegistry) - 1; i >= 0; i-- {
+		if errorRegistry[i].matches(err) {
+			return errorRegistry[i].mapping, true
+		}
+	}
+	return ErrorMapping{}, false
+}
+
+// NewProblemDetails describes the error as RFC 7807 problem details. Details
+// of internal server errors are not disclosed.
+func NewProblemDetails(err error) *ProblemDetails {
+	if mapping, found := lookupErrorMapping(err); found {
+		detail := err.Error()
+		if mapping.StatusCode >= http.StatusInternalServerError {
+			detail = ""
+		}
+		problem := newProblem(mapping.StatusCode, mapping.Type, detail)
+		if mapping.Title != "" {
+			problem.Title = mapping.Title
+		}
+		return problem
+	}
+
+	switch err := err.(type) {
+	case *RouterMissingParamError:
+		problem := newProblem(http.StatusBadRequest, ProblemTypeMissingParam, err.Error())
+		problem.ParamName = err.ParamName
+		return problem
+	case *RouterParsingError:
+		problem := newProblem(http.StatusBadRequest, ProblemTypeInvalidParam, err.ErrString)
+		problem.ParamName = err.ParamName
+		problem.ParamValue = err.ParamValue
+		return problem
+	case *ValidationError:
+		problem := newProblem(http.StatusBadRequest, ProblemTypeValidation, err.ErrString)
+		problem.ParamName = err.ParamName
+		problem.ParamValue = err.ParamValue
+		return problem
+	case *ValidationErrors:
+		problem := newProblem(http.StatusBadRequest, ProblemTypeValidation, "request parameters are invalid")
+		for _, paramErr := range err.Errors {
+			problem.InvalidParams = append(problem.InvalidParams, InvalidParam{
+				Name:   paramErr.ParamName,
+				Value:  paramErr.ParamValue,
+				Reason: paramErr.ErrString,
+			})
+		}
+		return problem
+	case *json.SyntaxError:
+		return newProblem(http.StatusBadRequest, ProblemTypeInvalidJSON, err.Error())
+	case *json.UnmarshalTypeError:
+		problem := newProblem(http.StatusBadRequest, ProblemTypeInvalidJSON, "bad type in json data")
+		problem.ParamName = err.Field
+		return problem
+	case *NoBodyError:
+		return newProblem(http.StatusBadRequest, ProblemTypeMissingBody, err.Error())
+	case *InvalidJSONError:
+		problem := newProblem(http.StatusBadRequest, ProblemTypeInvalidJSON, err.ErrString)
+		problem.ParamName = err.Field
+		return problem
+	case *PayloadTooLargeError:
+		return newProblem(http.StatusRequestEntityTooLarge, ProblemTypeBodyTooLarge, err.Error())
+	case *UnsupportedMediaTypeError:
+		return newProblem(http.StatusUnsupportedMediaType, ProblemTypeMediaType, err.Error())
+	case *OutOfRangeError:
+		problem := newProblem(http.StatusBadRequest, ProblemTypeOutOfRange, err.Error())
+		problem.ParamValue = err.Value
+		return problem
+	case *NoContentError:
+		return newProblem(http.StatusNoContent, "", err.Error())
+	case *ItemNotFoundError:
+		return newProblem(http.StatusNotFound, ProblemTypeNotFound, err.Error())
+	case *UnauthorizedError:
+		return newProblem(http.StatusUnauthorized, ProblemTypeUnauthorized, err.Error())
+	case *ForbiddenError:
+		return newProblem(http.StatusForbidden, ProblemTypeForbidden, err.Error())
+	case *TooManyRequestsError:
+		return newProblem(http.StatusTooManyRequests, ProblemTypeRateLimited, err.Error())
+	case *CircuitOpenError:
+		return newProblem(http.StatusServiceUnavailable, ProblemTypeUnavailable, err.Error())
+	default:
+		return newProblem(http.StatusInternalServerError, ProblemTypeInternalError, "")
+	}
+}
+
+func newProblem(statusCode int, problemType, detail string) *ProblemDetails {
+	if problemType == "" {
+		problemType = "about:blank"
+	}
+	return &ProblemDetails{
+		Type:   problemType,
+		Title:  http.StatusText(statusCode),
+		Status: statusCode,
+		Detail: detail,
+	}
+}
+
+// HandleServerProblem handles server errors like HandleServerError, but the
+// response is sent as RFC 7807 problem details (application/problem+json).
+// The instance is set to the request path and the correlation ID is taken
+// from the X-Request-Id header of the response or, if not set, of the
+// request.
+func HandleServerProblem(writer http.ResponseWriter, request *http.Request, err error) {
+	problem := NewProblemDetails(err)
+
+	problem.CorrelationID = writer.Header().Get(RequestIDHeader)
+	if request != nil {
+		problem.Instance = request.URL.Path
+		if problem.CorrelationID == "" {
+			problem.CorrelationID = request.Header.Get(RequestIDHeader)
+		}
+	}
+
+	setRetryAfter(writer, err)
+
+	level := log.Warn()
+	if problem.Status >= http.StatusInternalServerError {
+		level = log.Error()
+	}
+	level.Err(err).Str("correlation_id", problem.CorrelationID).Msg(handleServerErrorStr)
+
+	// response without any content can't carry the problem details
+	var respErr error
+	if problem.Status == http.StatusNoContent {
+		respErr = responses.SendNoContent(writer)
+	} else {
+		respErr = responses.SendProblem(writer, problem.Status, problem)
+	}
+	if respErr != nil {
+		log.Error().Err(respErr).Msg(responseDataError)
+	}
+}
@@ -0,0 +1,219 @@
+// Copyright 2026 Red Hat, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types_test
+
+// Documentation in literate-programming-style is available at:
+// https://redhatinsights.github.io/insights-operator-utils/packages/types/problem_test.html
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/RedHatInsights/insights-operator-utils/responses"
+	"github.com/RedHatInsights/insights-operator-utils/tests/helpers"
+	"github.com/RedHatInsights/insights-operator-utils/types"
+)
+
+// quotaExceededError is a service specific error registered in the registry
+type quotaExceededError struct{}
+
+func (*quotaExceededError) Error() string {
+	return "quota exceeded"
+}
+
+func init() {
+	types.RegisterErrorMapping[*quotaExceededError](types.ErrorMapping{
+		StatusCode: http.StatusTooManyRequests,
+		Type:       "quota-exceeded",
+	})
+}
+
+func handleProblem(request *http.Request, err error) (*httptest.ResponseRecorder, types.ProblemDetails) {
+	recorder := httptest.NewRecorder()
+	types.HandleServerProblem(recorder, request, err)
+
+	var problem types.ProblemDetails
+	_ = json.Unmarshal(recorder.Body.Bytes(), &problem)
+	return recorder, problem
+}
+
+func TestHandleServerProblemNoContent(t *testing.T) {
+	recorder, _ := handleProblem(nil, &types.NoContentError{ErrString: "feature is disabled"})
+	assert.Equal(t, http.StatusNoContent, recorder.Code)
+	assert.Empty(t, recorder.Body.String())
+}
+
+func TestHandleServerProblemValidationError(t *testing.T) {
+	request := httptest.NewRequest(http.MethodGet, "/api/v1/clusters/foo", http.NoBody)
+	request.Header.Set(types.RequestIDHeader, "request-id")
+
+	recorder, problem := handleProblem(request, &types.ValidationError{
+		ParamName:  "cluster",
+		ParamValue: "foo",
+		ErrString:  "invalid UUID",
+	})
+
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	assert.Equal(t, responses.ProblemJSON, recorder.Header().Get("Content-Type"))
+	assert.Equal(t, types.ProblemDetails{
+		Type:          types.ProblemTypeValidation,
+		Title:         "Bad Request",
+		Status:        http.StatusBadRequest,
+		Detail:        "invalid UUID",
+		Instance:      "/api/v1/clusters/foo",
+		ParamName:     "cluster",
+		ParamValue:    "foo",
+		CorrelationID: "request-id",
+	}, problem)
+}
+
+func TestHandleServerProblemValidationErrors(t *testing.T) {
+	request := httptest.NewRequest(http.MethodGet, "/api/v1/report?limit=0", http.NoBody)
+
+	recorder, problem := handleProblem(request, &types.ValidationErrors{Errors: []*types.ValidationError{
+		{ParamName: "limit", ParamValue: "0", ErrString: "value must be at least 1"},
+		{ParamName: "cluster", ErrString: "value is required"},
+	}})
+
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	assert.Equal(t, types.ProblemTypeValidation, problem.Type)
+	assert.Equal(t, "request parameters are invalid", problem.Detail)
+	assert.Equal(t, []types.InvalidParam{
+		{Name: "limit", Value: "0", Reason: "value must be at least 1"},
+		{Name: "cluster", Reason: "value is required"},
+	}, problem.InvalidParams)
+}
+
+func TestHandleServerProblemCorrelationIDFromResponse(t *testing.T) {
+	request := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+	request.Header.Set(types.RequestIDHeader, "from-request")
+
+	recorder := httptest.NewRecorder()
+	recorder.Header().Set(types.RequestIDHeader, "from-response")
+	types.HandleServerProblem(recorder, request, &types.NoBodyError{})
+
+	var problem types.ProblemDetails
+	helpers.FailOnError(t, json.Unmarshal(recorder.Body.Bytes(), &problem))
+	assert.Equal(t, "from-response", problem.CorrelationID)
+}
+
+func TestHandleServerProblemStatusCodes(t *testing.T) {
+	testCases := []struct {
+		err          error
+		expectedCode int
+		expectedType string
+	}{
+		{&types.RouterMissingParamError{ParamName: "org_id"}, http.StatusBadRequest, types.ProblemTypeMissingParam},
+		{&types.RouterParsingError{ParamName: "org_id"}, http.StatusBadRequest, types.ProblemTypeInvalidParam},
+		{&json.UnmarshalTypeError{Field: "name", Type: reflect.TypeOf("")}, http.StatusBadRequest, types.ProblemTypeInvalidJSON},
+		{&types.OutOfRangeError{Value: 1, Type: "int8"}, http.StatusBadRequest, types.ProblemTypeOutOfRange},
+		{&types.InvalidJSONError{Field: "count"}, http.StatusBadRequest, types.ProblemTypeInvalidJSON},
+		{&types.PayloadTooLargeError{Limit: 10}, http.StatusRequestEntityTooLarge, types.ProblemTypeBodyTooLarge},
+		{&types.UnsupportedMediaTypeError{}, http.StatusUnsupportedMediaType, types.ProblemTypeMediaType},
+		{&types.ItemNotFoundError{ItemID: 1}, http.StatusNotFound, types.ProblemTypeNotFound},
+		{&types.UnauthorizedError{}, http.StatusUnauthorized, types.ProblemTypeUnauthorized},
+		{&types.ForbiddenError{}, http.StatusForbidden, types.ProblemTypeForbidden},
+		{&types.TooManyRequestsError{Key: "org:1"}, http.StatusTooManyRequests, types.ProblemTypeRateLimited},
+		{errors.New("database is down"), http.StatusInternalServerError, types.ProblemTypeInternalError},
+		{fmt.Errorf("wrapped: %w", &quotaExceededError{}), http.StatusTooManyRequests, "quota-exceeded"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.expectedType, func(t *testing.T) {
+			recorder, problem := handleProblem(nil, tc.err)
+			assert.Equal(t, tc.expectedCode, recorder.Code)
+			assert.Equal(t, tc.expectedCode, problem.Status)
+			assert.Equal(t, tc.expectedType, problem.Type)
+			assert.Equal(t, http.StatusText(tc.expectedCode), problem.Title)
+
+			// plain error responses use the same status codes
+			plainRecorder := httptest.NewRecorder()
+			types.HandleServerError(plainRecorder, tc.err)
+			assert.Equal(t, tc.expectedCode, plainRecorder.Code)
+		})
+	}
+}
+
+func TestHandleServerProblemHidesInternalErrors(t *testing.T) {
+	_, problem := handleProblem(nil, errors.New("password=secret"))
+	assert.Empty(t, problem.Detail)
+}
+
+func TestCircuitOpenErrorRetryAfter(t *testing.T) {
+	err := &types.CircuitOpenError{Target: "content-service", RetryAfter: 10 * time.Second}
+
+	recorder, problem := handleProblem(nil, err)
+	assert.Equal(t, http.StatusServiceUnavailable, problem.Status)
+	assert.Equal(t, "10", recorder.Header().Get(responses.RetryAfterHeader))
+
+	recorder = httptest.NewRecorder()
+	types.HandleServerError(recorder, err)
+	assert.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+	assert.Equal(t, "10", recorder.Header().Get(responses.RetryAfterHeader))
+}
+
+func TestTooManyRequestsErrorRetryAfter(t *testing.T) {
+	recorder, problem := handleProblem(nil, &types.TooManyRequestsError{Key: "org:1", RetryAfter: 1500 * time.Millisecond})
+	assert.Equal(t, http.StatusTooManyRequests, problem.Status)
+	assert.Equal(t, "2", recorder.Header().Get(responses.RetryAfterHeader))
+}
+
+func TestHandleServerErrorRegisteredMapping(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	types.HandleServerError(recorder, &quotaExceededError{})
+
+	assert.Equal(t, http.StatusTooManyRequests, recorder.Code)
+	assert.JSONEq(t, `{"status": "quota exceeded"}`, recorder.Body.String())
+}
+
+func TestRegisterErrorMappingServerError(t *testing.T) {
+	type upstreamError struct{ *types.ItemNotFoundError }
+	types.RegisterErrorMapping[upstreamError](types.ErrorMapping{
+		StatusCode: http.StatusBadGateway,
+		Type:       "upstream-error",
+	})
+	err := upstreamError{&types.ItemNotFoundError{ItemID: "postgres://user:secret@db"}}
+
+	_, problem := handleProblem(nil, err)
+	assert.Equal(t, http.StatusBadGateway, problem.Status)
+	assert.Equal(t, "upstream-error", problem.Type)
+	assert.Empty(t, problem.Detail)
+
+	recorder := httptest.NewRecorder()
+	types.HandleServerError(recorder, err)
+	assert.Equal(t, http.StatusBadGateway, recorder.Code)
+	assert.JSONEq(t, `{"status": "Bad Gateway"}`, recorder.Body.String())
+}
+
+func TestRegisterErrorMappingTitle(t *testing.T) {
+	type conflictError struct{ *types.ItemNotFoundError }
+	types.RegisterErrorMapping[conflictError](types.ErrorMapping{
+		StatusCode: http.StatusConflict,
+		Title:      "Already exists",
+	})
+
+	_, problem := handleProblem(nil, conflictError{&types.ItemNotFoundError{ItemID: 1}})
+	assert.Equal(t, http.StatusConflict, problem.Status)
+	assert.Equal(t, "Already exists", problem.Title)
+	assert.Equal(t, "about:blank", problem.Type)
+}
@@ -0,0 +1,44 @@
+// Copyright 2026 Red Hat, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+// Documentation in literate-programming-style is available at:
+// https://redhatinsights.github.io/insights-operator-utils/packages/types/server.html
+
+import "time"
+
+// ServerConfiguration represents configuration of HTTP server. The metrics
+// can be served on a separate address; empty MetricsAddress means that the
+// metrics are served on the public one.
+type ServerConfiguration struct {
+	Address        string `mapstructure:"address" toml:"address"`
+	PrivateAddress string `mapstructure:"private_address" toml:"private_address"`
+	MetricsAddress string `mapstructure:"metrics_address" toml:"metrics_address"`
+	MetricsPath    string `mapstructure:"metrics_path" toml:"metrics_path"`
+	// APIPrefix is prepended to all endpoints, e.g. "/api/v1/"
+	APIPrefix string `mapstructure:"api_prefix" toml:"api_prefix"`
+	// APISpecFile is the path to OpenAPI specification served by the
+	// server, the specification is not served when empty
+	APISpecFile string `mapstructure:"api_spec_file" toml:"api_spec_file"`
+	// Debug keeps debug endpoints in the served OpenAPI specification
+	Debug bool `mapstructure:"debug" toml:"debug"`
+	// DrainTimeout is the time requests in flight have to finish when the
+	// server is shutting down
+	DrainTimeout time.Duration `mapstructure:"drain_timeout" toml:"drain_timeout"`
+	// ReadinessDelay is the time the server keeps accepting requests after
+	// the health endpoint starts to report the shutdown, so load balancers
+	// can stop routing requests to the instance before it's closed
+	ReadinessDelay time.Duration `mapstructure:"readiness_delay" toml:"readiness_delay"`
+}